@@ -6,6 +6,7 @@ import (
 	"net/http"
 
 	"github.com/juice-shop/multi-juicer/balancer/pkg/bundle"
+	"github.com/juice-shop/multi-juicer/balancer/pkg/ctfd"
 	"github.com/juice-shop/multi-juicer/balancer/pkg/scoring"
 	"github.com/juice-shop/multi-juicer/balancer/routes"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
@@ -13,13 +14,31 @@ import (
 
 func main() {
 	bundle := bundle.New()
-	scoringService := scoring.NewScoringService(bundle)
 
 	ctx := context.Background()
 
+	var scoringService *scoring.ScoringService
+	if bundle.Config.ScoreSnapshot.Enabled {
+		snapshotStore := scoring.NewConfigMapSnapshotStore(bundle.ClientSet, bundle.RuntimeEnvironment.Namespace, bundle.Config.ScoreSnapshot.ConfigMapName)
+		scoringService = scoring.NewScoringServiceFromSnapshot(ctx, bundle, snapshotStore)
+	} else {
+		scoringService = scoring.NewScoringService(bundle)
+	}
+
 	go StartMetricsServer()
-	scoringService.CalculateAndCacheScoreBoard(ctx)
-	go scoringService.StartingScoringWorker(ctx)
+	if scoringService.RestoredFromSnapshot() {
+		// serves the restored scores right away, the recalculation replaces them once it's done. The watcher only starts afterwards, so that its updates don't get overwritten by the recalculation
+		go func() {
+			scoringService.CalculateAndCacheScoreBoard(ctx)
+			scoringService.StartingScoringWorker(ctx)
+		}()
+	} else {
+		scoringService.CalculateAndCacheScoreBoard(ctx)
+		go scoringService.StartingScoringWorker(ctx)
+	}
+	if bundle.Config.Ctfd.Enabled {
+		go ctfd.NewScorePusher(bundle).Run(ctx, scoringService)
+	}
 	StartBalancerServer(bundle, scoringService)
 }
 
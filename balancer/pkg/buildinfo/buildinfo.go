@@ -0,0 +1,14 @@
+// Package buildinfo holds the version information of the balancer build.
+// The values are injected at build time, e.g. via:
+//
+//	go build -ldflags "-X github.com/juice-shop/multi-juicer/balancer/pkg/buildinfo.Version=v8.0.0"
+package buildinfo
+
+var (
+	// Version is the released version of the balancer, "dev" for local builds
+	Version = "dev"
+	// Commit is the git commit the balancer was built from
+	Commit = "unknown"
+	// BuildDate is the time the balancer was built at, in RFC3339 format
+	BuildDate = "unknown"
+)
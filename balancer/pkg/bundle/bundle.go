@@ -1,14 +1,24 @@
 package bundle
 
 import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"log"
 	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/juice-shop/multi-juicer/balancer/pkg/passcode"
 	"golang.org/x/crypto/bcrypt"
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
@@ -23,12 +33,141 @@ type Bundle struct {
 	GeneratePasscode func() string
 	// returns the (cluster internal) url for a team used by the balancer to proxy the request to. On the bundle to allow the tests to proxy requests to a local testing server
 	GetJuiceShopUrlForTeam func(team string, bundle *Bundle) string
-	BcryptRounds           int
-	StaticAssetsDirectory  string `json:"staticAssetsDirectory"`
-	Config                 *Config
-	Log                    *log.Logger
+	// returns the current resource usage of all JuiceShop instances, keyed by team. On the bundle as the fake clientset has no support for the metrics api
+	GetJuiceShopResourceUsage func(ctx context.Context, bundle *Bundle) (map[string]JuiceShopResourceUsage, error)
+	BcryptRounds              int
+	StaticAssetsDirectory     string `json:"staticAssetsDirectory"`
+	Config                    *Config
+	Log                       *log.Logger
 
 	JuiceShopChallenges []JuiceShopChallenge
+	// ChallengesHash is the sha256 hash of the loaded challenges.json, allows operators to check which challenge set a balancer runs with
+	ChallengesHash string
+
+	// readOnly blocks all mutating routes and freezes the scoreboard. Can be toggled at runtime by admins, so it's kept outside of the config. Only ever local to this replica
+	readOnly atomic.Bool
+
+	// scoringRules replaces the Config for score calculations once admins changed the scoring options at runtime, see UpdateScoringRules
+	scoringRules      atomic.Pointer[Config]
+	scoringRulesMutex sync.Mutex
+}
+
+// IsReadOnly reports whether the balancer is currently in read-only mode
+func (b *Bundle) IsReadOnly() bool {
+	return b.readOnly.Load()
+}
+
+// SetReadOnly enables or disables the read-only mode
+func (b *Bundle) SetReadOnly(readOnly bool) {
+	b.readOnly.Store(readOnly)
+}
+
+// DefaultAdminTeamName is the team name used to log in as admin if no other name is configured
+const DefaultAdminTeamName = "admin"
+
+// AdminTeamName returns the configured team name used to log in as admin
+func (b *Bundle) AdminTeamName() string {
+	if b.Config.AdminConfig == nil || b.Config.AdminConfig.TeamName == "" {
+		return DefaultAdminTeamName
+	}
+	return b.Config.AdminConfig.TeamName
+}
+
+// IsAdminTeam checks if the team is the admin. Use this for all admin checks instead of comparing team names directly
+func (b *Bundle) IsAdminTeam(team string) bool {
+	return team == b.AdminTeamName()
+}
+
+// PublicTeamName returns the name of the team shown on the public scoreboard. If the scoreboard is anonymized this is an alias derived from the team name and the cookie signing key, so it stays the same for the whole event
+func (b *Bundle) PublicTeamName(team string) string {
+	if !b.Config.AnonymizePublicScoreboard {
+		return team
+	}
+	mac := hmac.New(sha256.New, []byte(b.Config.CookieConfig.SigningKey))
+	mac.Write([]byte(team))
+	return "team-" + hex.EncodeToString(mac.Sum(nil))[:8]
+}
+
+// DefaultSolveTimePrecision is used to break ties between teams if no other precision is configured
+const DefaultSolveTimePrecision = time.Second
+
+// SolveTimePrecision returns the configured precision of solve times used to break ties between teams
+func (b *Bundle) SolveTimePrecision() time.Duration {
+	if b.Config.SolveTimePrecision == "" {
+		return DefaultSolveTimePrecision
+	}
+	precision, err := time.ParseDuration(b.Config.SolveTimePrecision)
+	if err != nil || precision < 0 {
+		return DefaultSolveTimePrecision
+	}
+	return precision
+}
+
+// NewInstanceGracePeriodDuration returns the parsed NewInstanceGracePeriod, 0 if it isn't set
+func (c *Config) NewInstanceGracePeriodDuration() time.Duration {
+	gracePeriod, err := time.ParseDuration(c.NewInstanceGracePeriod)
+	if err != nil || gracePeriod < 0 {
+		return 0
+	}
+	return gracePeriod
+}
+
+// WatchRestartMaxBackoffDuration returns the parsed WatchRestartMaxBackoff, one minute if it isn't set
+func (c *Config) WatchRestartMaxBackoffDuration() time.Duration {
+	maxBackoff, err := time.ParseDuration(c.WatchRestartMaxBackoff)
+	if err != nil || maxBackoff <= 0 {
+		return time.Minute
+	}
+	return maxBackoff
+}
+
+const (
+	// JSONFieldNamingCamelCase keeps the field names of the responses as is, e.g. "solvedChallengeCount"
+	JSONFieldNamingCamelCase = "camelCase"
+	// JSONFieldNamingSnakeCase renames the fields of the responses to snake_case, e.g. "solved_challenge_count", for integrations expecting that convention
+	JSONFieldNamingSnakeCase = "snake_case"
+)
+
+const (
+	// InstanceReadinessAnyReady treats an instance as ready as soon as one of its replicas is ready
+	InstanceReadinessAnyReady = "anyReady"
+	// InstanceReadinessAllReady treats an instance as ready once all of its desired replicas are ready
+	InstanceReadinessAllReady = "allReady"
+)
+
+// IsInstanceReady checks if the JuiceShop deployment of a team counts as ready according to the configured readiness definition.
+// Use this for all readiness checks, so that the scoreboard and the admin views agree on it
+func (b *Bundle) IsInstanceReady(deployment *appsv1.Deployment) bool {
+	return b.Config.IsInstanceReady(deployment)
+}
+
+// IsInstanceReady is the config only part of Bundle.IsInstanceReady, for code which doesn't have access to the full bundle
+func (c *Config) IsInstanceReady(deployment *appsv1.Deployment) bool {
+	if deployment.Status.ReadyReplicas == 0 {
+		return false
+	}
+	if c.InstanceReadiness != InstanceReadinessAllReady {
+		return true
+	}
+	desiredReplicas := int32(1)
+	if deployment.Spec.Replicas != nil {
+		desiredReplicas = *deployment.Spec.Replicas
+	}
+	return deployment.Status.ReadyReplicas >= desiredReplicas
+}
+
+// juiceShopInstanceLabelSelector selects all JuiceShop instances managed by MultiJuicer.
+// Keep in sync with the selectors used by the progress-watchdog and the cleaner
+const juiceShopInstanceLabelSelector = "app.kubernetes.io/name=juice-shop,app.kubernetes.io/part-of=multi-juicer"
+
+// JuiceShopInstanceLabelSelector returns the label selector matching the deployments and pods of all JuiceShop instances
+func (b *Bundle) JuiceShopInstanceLabelSelector() string {
+	return juiceShopInstanceLabelSelector
+}
+
+// JuiceShopTeamLabelSelector returns the label selector matching the deployment and pods of a single team
+func (b *Bundle) JuiceShopTeamLabelSelector(team string) string {
+	return fmt.Sprintf("%s,team=%s", juiceShopInstanceLabelSelector, team)
 }
 
 type RuntimeEnvironment struct {
@@ -36,14 +175,86 @@ type RuntimeEnvironment struct {
 }
 
 type Config struct {
-	JuiceShopConfig JuiceShopConfig `json:"juiceShop"`
-	MaxInstances    int             `json:"maxInstances"`
-	CookieConfig    CookieConfig    `json:"cookie"`
-	AdminConfig     *AdminConfig
+	JuiceShopConfig       JuiceShopConfig             `json:"juiceShop"`
+	MaxInstances          int                         `json:"maxInstances"`
+	CookieConfig          CookieConfig                `json:"cookie"`
+	TeamCreationRateLimit TeamCreationRateLimitConfig `json:"teamCreationRateLimit"`
+	// ChallengePointOverrides maps challenge keys to a fixed number of points, replacing the default difficulty based points. An override of 0 makes the challenge worth nothing while still listing it as solved
+	ChallengePointOverrides map[string]int `json:"challengePointOverrides"`
+	// CategoryMultipliers scales the difficulty based points of all challenges in a category, e.g. 2.0 for double points. Categories without an entry use 1.0. Doesn't apply to challenges with a point override
+	CategoryMultipliers map[string]float64  `json:"categoryMultipliers"`
+	ScoreSnapshot       ScoreSnapshotConfig `json:"scoreSnapshot"`
+	// DifficultyPoints maps a challenge difficulty to its points, replacing the default of difficulty * 10. Usually set via the scoring config file, see ScoringConfig
+	DifficultyPoints map[int]int `json:"difficultyPoints"`
+	// CategoryCompletionBonus grants extra points to teams which solved every challenge of a category. Usually set via the scoring config file, see ScoringConfig
+	CategoryCompletionBonus map[string]int `json:"categoryCompletionBonus"`
+	// HideScoresOfNotReadyInstances shows teams without points on the public scoreboard while their instance isn't ready. Admins still see the real scores
+	HideScoresOfNotReadyInstances bool `json:"hideScoresOfNotReadyInstances"`
+	// PrivateTeamDetails only returns the solved challenges of a team to the team itself and to admins. The public scoreboard keeps showing name, score and position
+	PrivateTeamDetails bool `json:"privateTeamDetails"`
+	// AnonymizePublicScoreboard replaces the team names on the public scoreboard and activity feeds with stable aliases. Admin routes keep showing the real names
+	AnonymizePublicScoreboard bool `json:"anonymizePublicScoreboard"`
+	// JSONFieldNaming is the default naming of the fields in the team score responses, either JSONFieldNamingCamelCase (default) or JSONFieldNamingSnakeCase. Clients can override it per request, see routes.responseFieldNaming
+	JSONFieldNaming string `json:"jsonFieldNaming"`
+	// InstanceReadiness defines when a JuiceShop instance counts as ready, either InstanceReadinessAnyReady (default) or InstanceReadinessAllReady. Only makes a difference for instances with multiple replicas
+	InstanceReadiness string `json:"instanceReadiness"`
+	// FreezeScoreboardAt freezes the public scoreboard at the given time, it keeps showing the scores as of the freeze while scoring continues for the admin routes. Disabled if nil
+	FreezeScoreboardAt *time.Time `json:"freezeScoreboardAt"`
+	// SolveTimePrecision is the precision (as go duration, e.g. "1s") solve times are truncated to before breaking ties between teams with the same score, so that sub-second jitter doesn't flip their order. "0s" compares the exact times. Defaults to one second
+	SolveTimePrecision string `json:"solveTimePrecision"`
+	// NewInstanceGracePeriod (as go duration, e.g. "30s") holds teams at their last known score while their instance is younger than it and reports fewer solved challenges, as fresh instances briefly report no progress until it got restored. Disabled if empty
+	NewInstanceGracePeriod string `json:"newInstanceGracePeriod"`
+	// WatchRestartMaxBackoff (as go duration, e.g. "1m") caps the exponential backoff between restarts of a failing deployment watch. Defaults to one minute
+	WatchRestartMaxBackoff string `json:"watchRestartMaxBackoff"`
+	// MaxTeamScore caps the score a team can reach, e.g. to limit the impact of automated mass-solving. Capped teams are ordered by the time they reached the cap. Disabled if 0
+	MaxTeamScore int `json:"maxTeamScore"`
+	// EventName is the name of the event, shown on the scoreboard if ScoreboardEventMetadata is enabled
+	EventName string `json:"eventName"`
+	// ScoreboardEventMetadata includes the event name, start and number of challenges in the scoreboard responses, so that the UI can show event info while no team joined yet
+	ScoreboardEventMetadata bool `json:"scoreboardEventMetadata"`
+	// EventStart is the start of the event, solve times in the timelines are reported relative to it. Read from the MULTI_JUICER_CONFIG_EVENT_START env var, the scoring service falls back to its own start time if it isn't set
+	EventStart *time.Time `json:"-"`
+	// ReadOnly starts the balancer in read-only mode, e.g. when it gets restarted after an event got frozen. Admins can still toggle it at runtime
+	ReadOnly    bool       `json:"readOnly"`
+	Ctfd        CtfdConfig `json:"ctfd"`
+	AdminConfig *AdminConfig
+}
+
+type CtfdConfig struct {
+	// Enabled pushes the scores of all teams to CTFd whenever the public scoreboard changes. Teams are mapped to the CTFd teams / users with the same name
+	Enabled bool `json:"enabled"`
+	// BaseUrl of the CTFd instance, e.g. https://ctfd.example.com
+	BaseUrl string `json:"baseUrl"`
+	// UserMode must match the user mode of the CTFd instance, either "teams" or "users". Defaults to "teams"
+	UserMode string `json:"userMode"`
+	// ApiToken is an admin access token for the CTFd api. Read from the MULTI_JUICER_CONFIG_CTFD_API_TOKEN env var instead of the config file
+	ApiToken string `json:"-"`
+}
+
+type ScoreSnapshotConfig struct {
+	// Enabled periodically persists the scoreboard to a ConfigMap, so that a restarted balancer can start with the last known scores
+	Enabled bool `json:"enabled"`
+	// ConfigMapName is the name of the ConfigMap the snapshot is stored in
+	ConfigMapName string `json:"configMapName"`
+}
+
+type TeamCreationRateLimitConfig struct {
+	// PerClientPerMinute is the number of teams a single client (ip address) can create per minute. Per client rate limiting is disabled if it's 0
+	PerClientPerMinute float64 `json:"perClientPerMinute"`
+	// Burst is the number of teams a client can create in quick succession before the rate limit kicks in. Defaults to 1
+	Burst int `json:"burst"`
+	// MaxConcurrentCreations caps the number of teams being created at the same time across all clients. Disabled if it's 0
+	MaxConcurrentCreations int `json:"maxConcurrentCreations"`
+	// TrustForwardedFor uses the last address of the X-Forwarded-For header, the one added by the proxy in front of the balancer, as client ip. Only enable this when the balancer is running behind a single proxy / ingress setting the header
+	TrustForwardedFor bool `json:"trustForwardedFor"`
 }
 
 type AdminConfig struct {
+	// TeamName is the name used to log in as admin. Defaults to DefaultAdminTeamName if empty
+	TeamName string `json:"teamName"`
 	Password string `json:"password"`
+	// ApiToken can be passed as a bearer token to the admin routes as an alternative to the admin cookie. Bearer token auth is disabled if it's empty.
+	ApiToken string `json:"apiToken"`
 }
 
 type CookieConfig struct {
@@ -112,7 +323,7 @@ func New() *Bundle {
 		panic(err.Error())
 	}
 
-	namespace := os.Getenv("NAMESPACE")
+	namespace := strings.TrimSpace(os.Getenv("NAMESPACE"))
 	if namespace == "" {
 		panic(errors.New("environment variable 'NAMESPACE' must be set"))
 	}
@@ -127,13 +338,81 @@ func New() *Bundle {
 		panic(errors.New("environment variable 'MULTI_JUICER_CONFIG_ADMIN_PASSWORD' must be set"))
 	}
 
+	// optional, admin routes only accept bearer tokens if it is set
+	adminApiToken := os.Getenv("MULTI_JUICER_CONFIG_ADMIN_API_TOKEN")
+
+	// optional, defaults to DefaultAdminTeamName
+	adminTeamName, err := ParseAdminTeamName(os.Getenv("MULTI_JUICER_CONFIG_ADMIN_TEAM_NAME"))
+	if err != nil {
+		panic(err)
+	}
+
+	// optional, the scoring service defaults to its start time
+	eventStart, err := ParseEventStart(os.Getenv("MULTI_JUICER_CONFIG_EVENT_START"))
+	if err != nil {
+		panic(err)
+	}
+
 	config, err := readConfigFromFile("/config/config.json")
 	if err != nil {
 		panic(err)
 	}
 
+	scoringConfig, err := readScoringConfigFromFile(scoringConfigFilePath)
+	if err != nil {
+		panic(err)
+	}
+	if scoringConfig != nil {
+		scoringConfig.apply(config)
+	}
+
 	config.CookieConfig.SigningKey = cookieSigningKey
-	config.AdminConfig = &AdminConfig{Password: adminPasswordKey}
+	if config.SolveTimePrecision != "" {
+		if precision, err := time.ParseDuration(config.SolveTimePrecision); err != nil || precision < 0 {
+			panic(fmt.Errorf("config 'solveTimePrecision' must be a positive duration like '1s', got '%s'", config.SolveTimePrecision))
+		}
+	}
+	if config.NewInstanceGracePeriod != "" {
+		if gracePeriod, err := time.ParseDuration(config.NewInstanceGracePeriod); err != nil || gracePeriod < 0 {
+			panic(fmt.Errorf("config 'newInstanceGracePeriod' must be a positive duration like '30s', got '%s'", config.NewInstanceGracePeriod))
+		}
+	}
+	if config.WatchRestartMaxBackoff != "" {
+		if maxBackoff, err := time.ParseDuration(config.WatchRestartMaxBackoff); err != nil || maxBackoff <= 0 {
+			panic(fmt.Errorf("config 'watchRestartMaxBackoff' must be a positive duration like '1m', got '%s'", config.WatchRestartMaxBackoff))
+		}
+	}
+	if config.MaxTeamScore < 0 {
+		panic(fmt.Errorf("config 'maxTeamScore' must not be negative, got '%d'", config.MaxTeamScore))
+	}
+	if config.JSONFieldNaming == "" {
+		config.JSONFieldNaming = JSONFieldNamingCamelCase
+	} else if config.JSONFieldNaming != JSONFieldNamingCamelCase && config.JSONFieldNaming != JSONFieldNamingSnakeCase {
+		panic(fmt.Errorf("config 'jsonFieldNaming' must be either '%s' or '%s', got '%s'", JSONFieldNamingCamelCase, JSONFieldNamingSnakeCase, config.JSONFieldNaming))
+	}
+	if config.InstanceReadiness == "" {
+		config.InstanceReadiness = InstanceReadinessAnyReady
+	} else if config.InstanceReadiness != InstanceReadinessAnyReady && config.InstanceReadiness != InstanceReadinessAllReady {
+		panic(fmt.Errorf("config 'instanceReadiness' must be either '%s' or '%s', got '%s'", InstanceReadinessAnyReady, InstanceReadinessAllReady, config.InstanceReadiness))
+	}
+	if config.ScoreSnapshot.ConfigMapName == "" {
+		config.ScoreSnapshot.ConfigMapName = "multi-juicer-score-snapshot"
+	}
+	config.EventStart = eventStart
+	config.AdminConfig = &AdminConfig{TeamName: adminTeamName, Password: adminPasswordKey, ApiToken: adminApiToken}
+
+	if config.Ctfd.Enabled {
+		config.Ctfd.ApiToken = os.Getenv("MULTI_JUICER_CONFIG_CTFD_API_TOKEN")
+		if config.Ctfd.ApiToken == "" {
+			panic(errors.New("environment variable 'MULTI_JUICER_CONFIG_CTFD_API_TOKEN' must be set when the CTFd integration is enabled"))
+		}
+		if config.Ctfd.BaseUrl == "" {
+			panic(errors.New("config 'ctfd.baseUrl' must be set when the CTFd integration is enabled"))
+		}
+		if config.Ctfd.UserMode != "" && config.Ctfd.UserMode != "teams" && config.Ctfd.UserMode != "users" {
+			panic(fmt.Errorf("config 'ctfd.userMode' must be either 'teams' or 'users', got '%s'", config.Ctfd.UserMode))
+		}
+	}
 
 	// read /challenges.json file
 	challengesBytes, err := os.ReadFile("/challenges.json")
@@ -147,19 +426,61 @@ func New() *Bundle {
 		panic(err)
 	}
 
-	return &Bundle{
+	bundle := &Bundle{
 		ClientSet:             clientset,
 		StaticAssetsDirectory: "/public/",
 		RuntimeEnvironment: RuntimeEnvironment{
 			Namespace: namespace,
 		},
-		GeneratePasscode:       passcode.GeneratePasscode,
-		GetJuiceShopUrlForTeam: getJuiceShopUrlForTeam,
-		BcryptRounds:           bcrypt.DefaultCost,
-		Log:                    log.New(os.Stdout, "", log.LstdFlags),
-		Config:                 config,
-		JuiceShopChallenges:    challenges,
+		GeneratePasscode:          passcode.GeneratePasscode,
+		GetJuiceShopUrlForTeam:    getJuiceShopUrlForTeam,
+		GetJuiceShopResourceUsage: getJuiceShopResourceUsage,
+		BcryptRounds:              bcrypt.DefaultCost,
+		Log:                       log.New(os.Stdout, "", log.LstdFlags),
+		Config:                    config,
+		JuiceShopChallenges:       challenges,
+		ChallengesHash:            HashChallenges(challengesBytes),
 	}
+	bundle.SetReadOnly(config.ReadOnly)
+	return bundle
+}
+
+// ParseEventStart parses the MULTI_JUICER_CONFIG_EVENT_START env var as RFC3339 timestamp. Returns nil if it isn't set
+func ParseEventStart(value string) (*time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+	eventStart, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, fmt.Errorf("environment variable 'MULTI_JUICER_CONFIG_EVENT_START' must be a RFC3339 timestamp like '2024-11-01T09:00:00Z', got '%s'", value)
+	}
+	return &eventStart, nil
+}
+
+// TeamNamePattern matches valid team names. Team names are part of the names and labels of the kubernetes resources of a team
+const TeamNamePattern = "[a-z0-9]([-a-z0-9])+[a-z0-9]"
+
+var validTeamNamePattern = regexp.MustCompile("^" + TeamNamePattern + "$")
+
+// IsValidTeamName checks if the team name matches the TeamNamePattern and is at most 16 characters long
+func IsValidTeamName(team string) bool {
+	return validTeamNamePattern.MatchString(team) && len(team) <= 16
+}
+
+// ParseAdminTeamName parses the MULTI_JUICER_CONFIG_ADMIN_TEAM_NAME env var. Returns an empty string if it isn't set, so that DefaultAdminTeamName is used
+func ParseAdminTeamName(value string) (string, error) {
+	value = strings.TrimSpace(value)
+	if value != "" && !IsValidTeamName(value) {
+		return "", fmt.Errorf("environment variable 'MULTI_JUICER_CONFIG_ADMIN_TEAM_NAME' must be a valid team name of up to 16 lowercase letters, digits and dashes, got '%s'", value)
+	}
+	return value, nil
+}
+
+// HashChallenges returns the hex encoded sha256 hash of the raw challenges.json
+func HashChallenges(challengesBytes []byte) string {
+	hash := sha256.Sum256(challengesBytes)
+	return hex.EncodeToString(hash[:])
 }
 
 func readConfigFromFile(filePath string) (*Config, error) {
@@ -2,8 +2,10 @@ package bundle
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
 )
 
 func TestGetJuiceShopUrlForTeam(t *testing.T) {
@@ -15,3 +17,105 @@ func TestGetJuiceShopUrlForTeam(t *testing.T) {
 		}))
 	})
 }
+
+func TestAdminTeamName(t *testing.T) {
+	t.Run("defaults to admin", func(t *testing.T) {
+		bundle := &Bundle{Config: &Config{AdminConfig: &AdminConfig{}}}
+		assert.Equal(t, "admin", bundle.AdminTeamName())
+		assert.True(t, bundle.IsAdminTeam("admin"))
+		assert.False(t, bundle.IsAdminTeam("foobar"))
+	})
+
+	t.Run("uses the configured team name", func(t *testing.T) {
+		bundle := &Bundle{Config: &Config{AdminConfig: &AdminConfig{TeamName: "organizers"}}}
+		assert.Equal(t, "organizers", bundle.AdminTeamName())
+		assert.True(t, bundle.IsAdminTeam("organizers"))
+		assert.False(t, bundle.IsAdminTeam("admin"))
+	})
+}
+
+func TestIsInstanceReady(t *testing.T) {
+	createDeployment := func(replicas int32, readyReplicas int32) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			Spec:   appsv1.DeploymentSpec{Replicas: &replicas},
+			Status: appsv1.DeploymentStatus{ReadyReplicas: readyReplicas},
+		}
+	}
+
+	t.Run("any ready replica is enough by default", func(t *testing.T) {
+		bundle := &Bundle{Config: &Config{}}
+		assert.True(t, bundle.IsInstanceReady(createDeployment(1, 1)))
+		assert.True(t, bundle.IsInstanceReady(createDeployment(3, 1)))
+		assert.False(t, bundle.IsInstanceReady(createDeployment(1, 0)))
+	})
+
+	t.Run("requires all replicas to be ready if configured", func(t *testing.T) {
+		bundle := &Bundle{Config: &Config{InstanceReadiness: InstanceReadinessAllReady}}
+		assert.True(t, bundle.IsInstanceReady(createDeployment(1, 1)))
+		assert.True(t, bundle.IsInstanceReady(createDeployment(3, 3)))
+		assert.False(t, bundle.IsInstanceReady(createDeployment(3, 1)))
+		assert.False(t, bundle.IsInstanceReady(createDeployment(0, 0)), "parked instances are never ready")
+	})
+}
+
+func TestPublicTeamName(t *testing.T) {
+	t.Run("returns the real name if the scoreboard isn't anonymized", func(t *testing.T) {
+		bundle := &Bundle{Config: &Config{}}
+		assert.Equal(t, "foobar", bundle.PublicTeamName("foobar"))
+	})
+
+	t.Run("returns a stable alias if the scoreboard is anonymized", func(t *testing.T) {
+		bundle := &Bundle{Config: &Config{AnonymizePublicScoreboard: true, CookieConfig: CookieConfig{SigningKey: "test-signing-key"}}}
+		alias := bundle.PublicTeamName("foobar")
+		assert.Regexp(t, "^team-[0-9a-f]{8}$", alias)
+		assert.Equal(t, alias, bundle.PublicTeamName("foobar"))
+		assert.NotEqual(t, alias, bundle.PublicTeamName("barfoo"))
+
+		otherEvent := &Bundle{Config: &Config{AnonymizePublicScoreboard: true, CookieConfig: CookieConfig{SigningKey: "other-signing-key"}}}
+		assert.NotEqual(t, alias, otherEvent.PublicTeamName("foobar"), "aliases differ between events")
+	})
+}
+
+func TestSolveTimePrecision(t *testing.T) {
+	assert.Equal(t, time.Second, (&Bundle{Config: &Config{}}).SolveTimePrecision(), "Should default to one second")
+	assert.Equal(t, 100*time.Millisecond, (&Bundle{Config: &Config{SolveTimePrecision: "100ms"}}).SolveTimePrecision())
+	assert.Equal(t, time.Duration(0), (&Bundle{Config: &Config{SolveTimePrecision: "0s"}}).SolveTimePrecision())
+}
+
+func TestParseEventStart(t *testing.T) {
+	eventStart, err := ParseEventStart("")
+	assert.Nil(t, err)
+	assert.Nil(t, eventStart, "Should be unset by default")
+
+	eventStart, err = ParseEventStart("2024-11-01T09:00:00+01:00")
+	assert.Nil(t, err)
+	assert.Equal(t, time.Date(2024, 11, 1, 8, 0, 0, 0, time.UTC), eventStart.UTC())
+
+	_, err = ParseEventStart("yesterday")
+	assert.NotNil(t, err, "Should reject values which aren't RFC3339 timestamps")
+}
+
+func TestParseAdminTeamName(t *testing.T) {
+	adminTeamName, err := ParseAdminTeamName("")
+	assert.Nil(t, err)
+	assert.Equal(t, "", adminTeamName, "Should be unset by default")
+
+	adminTeamName, err = ParseAdminTeamName(" organizers ")
+	assert.Nil(t, err)
+	assert.Equal(t, "organizers", adminTeamName)
+
+	for _, invalid := range []string{"Organizers", "orga nizers", "-organizers", "a-very-long-admin-team-name"} {
+		_, err = ParseAdminTeamName(invalid)
+		assert.NotNil(t, err, "Should reject names teams couldn't join with: %s", invalid)
+	}
+}
+
+func TestWatchRestartMaxBackoffDuration(t *testing.T) {
+	assert.Equal(t, time.Minute, (&Config{}).WatchRestartMaxBackoffDuration(), "Should default to one minute")
+	assert.Equal(t, 5*time.Minute, (&Config{WatchRestartMaxBackoff: "5m"}).WatchRestartMaxBackoffDuration())
+}
+
+func TestNewInstanceGracePeriodDuration(t *testing.T) {
+	assert.Equal(t, time.Duration(0), (&Config{}).NewInstanceGracePeriodDuration(), "Should be disabled by default")
+	assert.Equal(t, 30*time.Second, (&Config{NewInstanceGracePeriod: "30s"}).NewInstanceGracePeriodDuration())
+}
@@ -0,0 +1,77 @@
+package bundle
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// JuiceShopResourceUsage is the current cpu and memory usage of a JuiceShop instance as reported by the metrics server
+type JuiceShopResourceUsage struct {
+	CPUMillicores int64
+	MemoryBytes   int64
+}
+
+// subset of the metrics.k8s.io/v1beta1 PodMetricsList, just the fields required to sum up the usage per team
+type podMetricsList struct {
+	Items []podMetrics `json:"items"`
+}
+
+type podMetrics struct {
+	Metadata struct {
+		Labels map[string]string `json:"labels"`
+	} `json:"metadata"`
+	Containers []struct {
+		Usage map[string]resource.Quantity `json:"usage"`
+	} `json:"containers"`
+}
+
+var errMetricsUnavailable = errors.New("metrics api is not available")
+
+// metricsRequestTimeout bounds how long listing the instances waits for the metrics server, the usage is left out if it doesn't respond in time
+const metricsRequestTimeout = 2 * time.Second
+
+// getJuiceShopResourceUsage fetches the resource usage of all JuiceShop instances from the metrics.k8s.io api, keyed by team.
+// Returns an error if the metrics server isn't installed in the cluster or doesn't respond within metricsRequestTimeout
+func getJuiceShopResourceUsage(ctx context.Context, bundle *Bundle) (map[string]JuiceShopResourceUsage, error) {
+	restClient := bundle.ClientSet.Discovery().RESTClient()
+	if restClient == nil {
+		return nil, errMetricsUnavailable
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, metricsRequestTimeout)
+	defer cancel()
+
+	body, err := restClient.Get().
+		AbsPath("/apis/metrics.k8s.io/v1beta1/namespaces", bundle.RuntimeEnvironment.Namespace, "pods").
+		Param("labelSelector", bundle.JuiceShopInstanceLabelSelector()).
+		DoRaw(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch pod metrics: %w", err)
+	}
+
+	var metrics podMetricsList
+	if err := json.Unmarshal(body, &metrics); err != nil {
+		return nil, fmt.Errorf("failed to decode pod metrics: %w", err)
+	}
+
+	usage := map[string]JuiceShopResourceUsage{}
+	for _, pod := range metrics.Items {
+		team := pod.Metadata.Labels["team"]
+		teamUsage := usage[team]
+		for _, container := range pod.Containers {
+			if cpu, ok := container.Usage["cpu"]; ok {
+				teamUsage.CPUMillicores += cpu.MilliValue()
+			}
+			if memory, ok := container.Usage["memory"]; ok {
+				teamUsage.MemoryBytes += memory.Value()
+			}
+		}
+		usage[team] = teamUsage
+	}
+	return usage, nil
+}
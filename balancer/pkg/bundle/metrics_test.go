@@ -0,0 +1,57 @@
+package bundle
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+func TestGetJuiceShopResourceUsage(t *testing.T) {
+	createBundle := func(t *testing.T, handler http.HandlerFunc) *Bundle {
+		server := httptest.NewServer(handler)
+		t.Cleanup(server.Close)
+		clientset, err := kubernetes.NewForConfig(&rest.Config{Host: server.URL})
+		assert.NoError(t, err)
+		return &Bundle{
+			ClientSet:          clientset,
+			RuntimeEnvironment: RuntimeEnvironment{Namespace: "test-namespace"},
+		}
+	}
+
+	t.Run("sums up the usage of all pods of a team", func(t *testing.T) {
+		bundle := createBundle(t, func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/apis/metrics.k8s.io/v1beta1/namespaces/test-namespace/pods", r.URL.Path)
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"items":[
+				{"metadata":{"labels":{"team":"foobar"}},"containers":[{"usage":{"cpu":"100m","memory":"64Mi"}}]},
+				{"metadata":{"labels":{"team":"foobar"}},"containers":[{"usage":{"cpu":"50m","memory":"64Mi"}}]}
+			]}`))
+		})
+
+		usage, err := getJuiceShopResourceUsage(context.Background(), bundle)
+
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]JuiceShopResourceUsage{
+			"foobar": {CPUMillicores: 150, MemoryBytes: 128 * 1024 * 1024},
+		}, usage)
+	})
+
+	t.Run("gives up if the metrics server doesn't respond in time", func(t *testing.T) {
+		bundle := createBundle(t, func(w http.ResponseWriter, r *http.Request) {
+			<-r.Context().Done()
+		})
+
+		start := time.Now()
+		usage, err := getJuiceShopResourceUsage(context.Background(), bundle)
+
+		assert.Error(t, err)
+		assert.Nil(t, usage)
+		assert.Less(t, time.Since(start), metricsRequestTimeout+time.Second)
+	})
+}
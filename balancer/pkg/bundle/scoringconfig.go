@@ -0,0 +1,153 @@
+package bundle
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// scoringConfigFilePath is where the optional scoring config gets mounted, next to the config.json
+const scoringConfigFilePath = "/config/scoring-config.json"
+
+// ScoringConfig consolidates all scoring options in a single optional file. Options set in it replace the same options of the config.json. Example:
+//
+//	{
+//	  "difficultyPoints": {"1": 100, "2": 250},
+//	  "challengePointOverrides": {"scoreBoardChallenge": 5},
+//	  "categoryMultipliers": {"Injection": 1.5},
+//	  "categoryCompletionBonus": {"Injection": 200},
+//	  "maxTeamScore": 5000,
+//	  "solveTimePrecision": "1s"
+//	}
+type ScoringConfig struct {
+	// DifficultyPoints maps a challenge difficulty (1-6) to its points, replacing the default of difficulty * 10. Difficulties without an entry keep the default
+	DifficultyPoints map[int]int `json:"difficultyPoints"`
+	// ChallengePointOverrides maps challenge keys to a fixed number of points, see Config.ChallengePointOverrides
+	ChallengePointOverrides map[string]int `json:"challengePointOverrides"`
+	// CategoryMultipliers scales the difficulty based points of all challenges in a category, see Config.CategoryMultipliers
+	CategoryMultipliers map[string]float64 `json:"categoryMultipliers"`
+	// CategoryCompletionBonus grants extra points to teams which solved every challenge of a category
+	CategoryCompletionBonus map[string]int `json:"categoryCompletionBonus"`
+	// MaxTeamScore caps the score a team can reach, see Config.MaxTeamScore
+	MaxTeamScore *int `json:"maxTeamScore"`
+	// SolveTimePrecision is used to break ties between teams with the same score, see Config.SolveTimePrecision
+	SolveTimePrecision string `json:"solveTimePrecision"`
+}
+
+// readScoringConfigFromFile reads the scoring config. Returns nil if the file doesn't exist, as the scoring config is optional. Unknown fields are rejected, so that typos don't go unnoticed
+func readScoringConfigFromFile(filePath string) (*ScoringConfig, error) {
+	scoringConfigBytes, err := os.ReadFile(filePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read scoring config file: %w", err)
+	}
+
+	scoringConfig, err := DecodeScoringConfig(bytes.NewReader(scoringConfigBytes))
+	if err != nil {
+		return nil, fmt.Errorf("invalid scoring config file: %w", err)
+	}
+	return scoringConfig, nil
+}
+
+// DecodeScoringConfig decodes and validates a scoring config. Unknown fields are rejected, so that typos don't go unnoticed
+func DecodeScoringConfig(reader io.Reader) (*ScoringConfig, error) {
+	var scoringConfig ScoringConfig
+	decoder := json.NewDecoder(reader)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&scoringConfig); err != nil {
+		return nil, fmt.Errorf("failed to decode scoring config: %w", err)
+	}
+	if err := scoringConfig.validate(); err != nil {
+		return nil, err
+	}
+	return &scoringConfig, nil
+}
+
+// ScoringRules returns the config scores are calculated with. That's the Config, unless admins changed the scoring options at runtime
+func (b *Bundle) ScoringRules() *Config {
+	if scoringRules := b.scoringRules.Load(); scoringRules != nil {
+		return scoringRules
+	}
+	return b.Config
+}
+
+// UpdateScoringRules applies the options set in the scoring config on top of the current scoring rules. The rules are only kept in memory of this balancer, so they are lost on restarts.
+// The solve time precision can't be changed at runtime, as it's used while sorting the scoreboard
+func (b *Bundle) UpdateScoringRules(scoringConfig *ScoringConfig) error {
+	if err := scoringConfig.validate(); err != nil {
+		return err
+	}
+	if scoringConfig.SolveTimePrecision != "" {
+		return errors.New("'solveTimePrecision' can't be changed at runtime")
+	}
+
+	b.scoringRulesMutex.Lock()
+	defer b.scoringRulesMutex.Unlock()
+	// copied, as the current rules might still be used by running score calculations
+	scoringRules := *b.ScoringRules()
+	scoringConfig.apply(&scoringRules)
+	b.scoringRules.Store(&scoringRules)
+	return nil
+}
+
+func (s *ScoringConfig) validate() error {
+	for difficulty, points := range s.DifficultyPoints {
+		if difficulty < 1 || difficulty > 6 {
+			return fmt.Errorf("'difficultyPoints' contains the unknown difficulty %d, JuiceShop difficulties range from 1 to 6", difficulty)
+		}
+		if points < 0 {
+			return fmt.Errorf("'difficultyPoints' of difficulty %d must not be negative, got %d", difficulty, points)
+		}
+	}
+	for key, points := range s.ChallengePointOverrides {
+		if points < 0 {
+			return fmt.Errorf("'challengePointOverrides' of challenge '%s' must not be negative, got %d", key, points)
+		}
+	}
+	for category, multiplier := range s.CategoryMultipliers {
+		if multiplier < 0 {
+			return fmt.Errorf("'categoryMultipliers' of category '%s' must not be negative, got %g", category, multiplier)
+		}
+	}
+	for category, bonus := range s.CategoryCompletionBonus {
+		if bonus < 0 {
+			return fmt.Errorf("'categoryCompletionBonus' of category '%s' must not be negative, got %d", category, bonus)
+		}
+	}
+	if s.MaxTeamScore != nil && *s.MaxTeamScore < 0 {
+		return fmt.Errorf("'maxTeamScore' must not be negative, got %d", *s.MaxTeamScore)
+	}
+	if s.SolveTimePrecision != "" {
+		if precision, err := time.ParseDuration(s.SolveTimePrecision); err != nil || precision < 0 {
+			return fmt.Errorf("'solveTimePrecision' must be a positive duration like '1s', got '%s'", s.SolveTimePrecision)
+		}
+	}
+	return nil
+}
+
+// apply copies all options set in the scoring config into the config
+func (s *ScoringConfig) apply(config *Config) {
+	if s.DifficultyPoints != nil {
+		config.DifficultyPoints = s.DifficultyPoints
+	}
+	if s.ChallengePointOverrides != nil {
+		config.ChallengePointOverrides = s.ChallengePointOverrides
+	}
+	if s.CategoryMultipliers != nil {
+		config.CategoryMultipliers = s.CategoryMultipliers
+	}
+	if s.CategoryCompletionBonus != nil {
+		config.CategoryCompletionBonus = s.CategoryCompletionBonus
+	}
+	if s.MaxTeamScore != nil {
+		config.MaxTeamScore = *s.MaxTeamScore
+	}
+	if s.SolveTimePrecision != "" {
+		config.SolveTimePrecision = s.SolveTimePrecision
+	}
+}
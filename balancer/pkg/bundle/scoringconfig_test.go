@@ -0,0 +1,96 @@
+package bundle
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadScoringConfigFromFile(t *testing.T) {
+	writeScoringConfig := func(t *testing.T, content string) string {
+		path := filepath.Join(t.TempDir(), "scoring-config.json")
+		assert.Nil(t, os.WriteFile(path, []byte(content), 0644))
+		return path
+	}
+
+	t.Run("is optional", func(t *testing.T) {
+		scoringConfig, err := readScoringConfigFromFile(filepath.Join(t.TempDir(), "missing.json"))
+		assert.Nil(t, err)
+		assert.Nil(t, scoringConfig)
+	})
+
+	t.Run("reads all scoring options and applies them to the config", func(t *testing.T) {
+		scoringConfig, err := readScoringConfigFromFile(writeScoringConfig(t, `{
+			"difficultyPoints": {"1": 100, "4": 500},
+			"challengePointOverrides": {"scoreBoardChallenge": 5},
+			"categoryMultipliers": {"Injection": 1.5},
+			"categoryCompletionBonus": {"Injection": 200},
+			"maxTeamScore": 5000,
+			"solveTimePrecision": "1m"
+		}`))
+		assert.Nil(t, err)
+
+		config := &Config{SolveTimePrecision: "1s", ChallengePointOverrides: map[string]int{"nullByteChallenge": 1}}
+		scoringConfig.apply(config)
+
+		assert.Equal(t, map[int]int{1: 100, 4: 500}, config.DifficultyPoints)
+		assert.Equal(t, map[string]int{"scoreBoardChallenge": 5}, config.ChallengePointOverrides)
+		assert.Equal(t, map[string]float64{"Injection": 1.5}, config.CategoryMultipliers)
+		assert.Equal(t, map[string]int{"Injection": 200}, config.CategoryCompletionBonus)
+		assert.Equal(t, 5000, config.MaxTeamScore)
+		assert.Equal(t, "1m", config.SolveTimePrecision)
+	})
+
+	t.Run("keeps the options of the config which aren't set in the scoring config", func(t *testing.T) {
+		scoringConfig, err := readScoringConfigFromFile(writeScoringConfig(t, `{"maxTeamScore": 0}`))
+		assert.Nil(t, err)
+
+		config := &Config{SolveTimePrecision: "1s", MaxTeamScore: 100, ChallengePointOverrides: map[string]int{"nullByteChallenge": 1}}
+		scoringConfig.apply(config)
+
+		assert.Equal(t, 0, config.MaxTeamScore)
+		assert.Equal(t, "1s", config.SolveTimePrecision)
+		assert.Equal(t, map[string]int{"nullByteChallenge": 1}, config.ChallengePointOverrides)
+	})
+
+	t.Run("rejects malformed scoring configs", func(t *testing.T) {
+		for _, content := range []string{
+			`{"difficultyPoints": `,
+			`{"maxTeamScroe": 100}`,
+			`{"difficultyPoints": {"7": 100}}`,
+			`{"difficultyPoints": {"1": -10}}`,
+			`{"challengePointOverrides": {"scoreBoardChallenge": -1}}`,
+			`{"categoryMultipliers": {"Injection": -2}}`,
+			`{"categoryCompletionBonus": {"Injection": -100}}`,
+			`{"maxTeamScore": -1}`,
+			`{"solveTimePrecision": "soon"}`,
+		} {
+			_, err := readScoringConfigFromFile(writeScoringConfig(t, content))
+			assert.NotNil(t, err, "Should reject %s", content)
+		}
+	})
+}
+
+func TestUpdateScoringRules(t *testing.T) {
+	t.Run("applies the options on top of the current rules without modifying the config", func(t *testing.T) {
+		bundle := &Bundle{Config: &Config{ChallengePointOverrides: map[string]int{"scoreBoardChallenge": 5}, MaxTeamScore: 100}}
+		assert.Same(t, bundle.Config, bundle.ScoringRules(), "Should use the config until the rules get updated")
+
+		assert.Nil(t, bundle.UpdateScoringRules(&ScoringConfig{CategoryMultipliers: map[string]float64{"Injection": 2}}))
+		assert.Nil(t, bundle.UpdateScoringRules(&ScoringConfig{ChallengePointOverrides: map[string]int{"nullByteChallenge": 0}}))
+
+		assert.Equal(t, map[string]int{"nullByteChallenge": 0}, bundle.ScoringRules().ChallengePointOverrides)
+		assert.Equal(t, map[string]float64{"Injection": 2}, bundle.ScoringRules().CategoryMultipliers)
+		assert.Equal(t, 100, bundle.ScoringRules().MaxTeamScore)
+		assert.Equal(t, map[string]int{"scoreBoardChallenge": 5}, bundle.Config.ChallengePointOverrides)
+	})
+
+	t.Run("rejects invalid options", func(t *testing.T) {
+		bundle := &Bundle{Config: &Config{}}
+		assert.NotNil(t, bundle.UpdateScoringRules(&ScoringConfig{ChallengePointOverrides: map[string]int{"scoreBoardChallenge": -5}}))
+		assert.NotNil(t, bundle.UpdateScoringRules(&ScoringConfig{SolveTimePrecision: "1ms"}), "The solve time precision can't be changed at runtime")
+		assert.Same(t, bundle.Config, bundle.ScoringRules())
+	})
+}
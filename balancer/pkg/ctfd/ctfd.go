@@ -0,0 +1,249 @@
+package ctfd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/juice-shop/multi-juicer/balancer/pkg/bundle"
+	"github.com/juice-shop/multi-juicer/balancer/pkg/scoring"
+)
+
+// awardName identifies the awards created by MultiJuicer. The sum of these awards is the score already pushed to CTFd
+const awardName = "MultiJuicer"
+
+// ErrAccountNotFound is returned if CTFd has no team / user with the name of the MultiJuicer team
+var ErrAccountNotFound = errors.New("no CTFd account with the name of the team found")
+
+// Client talks to the CTFd api using an admin access token
+type Client struct {
+	baseUrl    string
+	apiToken   string
+	userMode   string
+	httpClient *http.Client
+}
+
+func NewClient(config bundle.CtfdConfig) *Client {
+	userMode := config.UserMode
+	if userMode == "" {
+		userMode = "teams"
+	}
+	return &Client{
+		baseUrl:    strings.TrimSuffix(config.BaseUrl, "/"),
+		apiToken:   config.ApiToken,
+		userMode:   userMode,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+type ctfdResponse[T any] struct {
+	Success bool `json:"success"`
+	Data    T    `json:"data"`
+}
+
+type ctfdAccount struct {
+	Id   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+type ctfdAward struct {
+	Name  string `json:"name"`
+	Value int    `json:"value"`
+}
+
+// PushScore makes the MultiJuicer awards of the CTFd account with the same name as the team add up to the score
+func (c *Client) PushScore(ctx context.Context, team string, score int) error {
+	accountId, err := c.findAccountId(ctx, team)
+	if err != nil {
+		return err
+	}
+	pushedScore, err := c.getPushedScore(ctx, accountId)
+	if err != nil {
+		return err
+	}
+	if pushedScore == score {
+		return nil
+	}
+	return c.createAward(ctx, accountId, score-pushedScore)
+}
+
+func (c *Client) findAccountId(ctx context.Context, name string) (int, error) {
+	var response ctfdResponse[[]ctfdAccount]
+	query := url.Values{"field": {"name"}, "q": {name}}
+	if err := c.do(ctx, "GET", fmt.Sprintf("/api/v1/%s?%s", c.userMode, query.Encode()), nil, &response); err != nil {
+		return 0, err
+	}
+	// the name filter of CTFd matches substrings, so other accounts containing the name can be part of the response
+	for _, account := range response.Data {
+		if account.Name == name {
+			return account.Id, nil
+		}
+	}
+	return 0, ErrAccountNotFound
+}
+
+func (c *Client) getPushedScore(ctx context.Context, accountId int) (int, error) {
+	var response ctfdResponse[[]ctfdAward]
+	if err := c.do(ctx, "GET", fmt.Sprintf("/api/v1/%s/%d/awards", c.userMode, accountId), nil, &response); err != nil {
+		return 0, err
+	}
+	pushedScore := 0
+	for _, award := range response.Data {
+		if award.Name == awardName {
+			pushedScore += award.Value
+		}
+	}
+	return pushedScore, nil
+}
+
+func (c *Client) createAward(ctx context.Context, accountId int, value int) error {
+	award := map[string]any{
+		"name":        awardName,
+		"value":       value,
+		"category":    "MultiJuicer",
+		"description": "Points scored in the MultiJuicer JuiceShop",
+	}
+	if c.userMode == "users" {
+		award["user_id"] = accountId
+	} else {
+		award["team_id"] = accountId
+	}
+	return c.do(ctx, "POST", "/api/v1/awards", award, nil)
+}
+
+func (c *Client) do(ctx context.Context, method string, path string, body any, response any) error {
+	var requestBody io.Reader
+	if body != nil {
+		bodyBytes, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode CTFd request: %w", err)
+		}
+		requestBody = bytes.NewReader(bodyBytes)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseUrl+path, requestBody)
+	if err != nil {
+		return fmt.Errorf("failed to create CTFd request: %w", err)
+	}
+	req.Header.Set("Authorization", "Token "+c.apiToken)
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach CTFd: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return fmt.Errorf("unexpected response status code '%d' from CTFd for %s %s", res.StatusCode, method, path)
+	}
+	if response == nil {
+		return nil
+	}
+	if err := json.NewDecoder(res.Body).Decode(response); err != nil {
+		return fmt.Errorf("failed to decode CTFd response: %w", err)
+	}
+	return nil
+}
+
+const minRetryDelay = 5 * time.Second
+const maxRetryDelay = 5 * time.Minute
+
+// ScorePusher pushes the scores of all teams to CTFd whenever the public scoreboard changes.
+// Scores which couldn't be pushed, e.g. while CTFd is down, stay queued and are retried with an increasing delay
+type ScorePusher struct {
+	client *Client
+	bundle *bundle.Bundle
+	log    *log.Logger
+
+	// pending holds the scores still to be pushed, keyed by team. Only the latest score of a team is kept
+	pending map[string]int
+	// lastPushed avoids pushing unchanged scores again on every scoreboard update
+	lastPushed map[string]int
+}
+
+func NewScorePusher(b *bundle.Bundle) *ScorePusher {
+	return &ScorePusher{
+		client:     NewClient(b.Config.Ctfd),
+		bundle:     b,
+		log:        b.Log,
+		pending:    map[string]int{},
+		lastPushed: map[string]int{},
+	}
+}
+
+// Run pushes score changes to CTFd until the context is canceled
+func (p *ScorePusher) Run(ctx context.Context, scoringService *scoring.ScoringService) {
+	p.log.Printf("Pushing scores to CTFd at '%s'", p.client.baseUrl)
+
+	var lastSeenUpdate time.Time
+	retryDelay := minRetryDelay
+	for ctx.Err() == nil {
+		scores, lastUpdate, _ := scoringService.GetPublicTopScoresWithLastUpdate()
+		if lastUpdate.After(lastSeenUpdate) {
+			p.enqueue(scores)
+			lastSeenUpdate = lastUpdate
+		}
+
+		if p.pushPending(ctx) {
+			retryDelay = minRetryDelay
+			scoringService.WaitForPublicUpdatesNewerThan(ctx, lastSeenUpdate)
+			continue
+		}
+
+		p.log.Printf("Failed to push %d scores to CTFd, retrying in %s", len(p.pending), retryDelay)
+		select {
+		case <-time.After(retryDelay):
+		case <-ctx.Done():
+		}
+		retryDelay = min(retryDelay*2, maxRetryDelay)
+	}
+}
+
+// enqueue queues the scores which changed since they were last pushed
+func (p *ScorePusher) enqueue(scores []*scoring.TeamScore) {
+	for _, score := range scores {
+		// withheld scores would temporarily take away the points of the team in CTFd. They are pushed once the instance is ready again
+		if p.bundle.Config.HideScoresOfNotReadyInstances && !score.InstanceReadiness {
+			continue
+		}
+		if lastPushed, ok := p.lastPushed[score.Name]; ok && lastPushed == score.Score {
+			delete(p.pending, score.Name)
+			continue
+		}
+		p.pending[score.Name] = score.Score
+	}
+}
+
+// pushPending pushes all queued scores. Returns false if pushing got aborted because CTFd couldn't be reached, the remaining scores stay queued
+func (p *ScorePusher) pushPending(ctx context.Context) bool {
+	teams := make([]string, 0, len(p.pending))
+	for team := range p.pending {
+		teams = append(teams, team)
+	}
+	sort.Strings(teams)
+
+	for _, team := range teams {
+		score := p.pending[team]
+		err := p.client.PushScore(ctx, team, score)
+		if errors.Is(err, ErrAccountNotFound) {
+			// not retried until the score changes again, as the team is probably just not taking part in the CTF
+			p.log.Printf("Skipping pushing the score of team '%s' to CTFd: %s", team, err)
+		} else if err != nil {
+			p.log.Printf("Failed to push score of team '%s' to CTFd: %s", team, err)
+			return false
+		}
+		p.lastPushed[team] = score
+		delete(p.pending, team)
+	}
+	return true
+}
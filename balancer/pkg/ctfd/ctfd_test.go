@@ -0,0 +1,183 @@
+package ctfd
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/juice-shop/multi-juicer/balancer/pkg/bundle"
+	"github.com/juice-shop/multi-juicer/balancer/pkg/scoring"
+	"github.com/juice-shop/multi-juicer/balancer/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeCtfd is a minimal in memory CTFd api, supporting the endpoints used by the client in teams mode
+type fakeCtfd struct {
+	mutex  sync.Mutex
+	teams  map[string]int
+	awards map[int][]ctfdAward
+	down   bool
+}
+
+func newFakeCtfd(teams map[string]int) (*fakeCtfd, *httptest.Server) {
+	ctfd := &fakeCtfd{teams: teams, awards: map[int][]ctfdAward{}}
+	router := http.NewServeMux()
+	router.HandleFunc("GET /api/v1/teams", func(w http.ResponseWriter, r *http.Request) {
+		ctfd.mutex.Lock()
+		defer ctfd.mutex.Unlock()
+		accounts := []ctfdAccount{}
+		for name, id := range ctfd.teams {
+			accounts = append(accounts, ctfdAccount{Id: id, Name: name})
+		}
+		json.NewEncoder(w).Encode(ctfdResponse[[]ctfdAccount]{Success: true, Data: accounts})
+	})
+	router.HandleFunc("GET /api/v1/teams/{id}/awards", func(w http.ResponseWriter, r *http.Request) {
+		ctfd.mutex.Lock()
+		defer ctfd.mutex.Unlock()
+		id, _ := strconv.Atoi(r.PathValue("id"))
+		awards := append([]ctfdAward{}, ctfd.awards[id]...)
+		json.NewEncoder(w).Encode(ctfdResponse[[]ctfdAward]{Success: true, Data: awards})
+	})
+	router.HandleFunc("POST /api/v1/awards", func(w http.ResponseWriter, r *http.Request) {
+		ctfd.mutex.Lock()
+		defer ctfd.mutex.Unlock()
+		var award struct {
+			TeamId int    `json:"team_id"`
+			Name   string `json:"name"`
+			Value  int    `json:"value"`
+		}
+		json.NewDecoder(r.Body).Decode(&award)
+		ctfd.awards[award.TeamId] = append(ctfd.awards[award.TeamId], ctfdAward{Name: award.Name, Value: award.Value})
+		w.WriteHeader(http.StatusOK)
+	})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctfd.mutex.Lock()
+		down := ctfd.down
+		ctfd.mutex.Unlock()
+		if down {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if r.Header.Get("Authorization") != "Token test-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		router.ServeHTTP(w, r)
+	}))
+	return ctfd, server
+}
+
+func (f *fakeCtfd) awardedPoints(teamId int) int {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	points := 0
+	for _, award := range f.awards[teamId] {
+		points += award.Value
+	}
+	return points
+}
+
+func (f *fakeCtfd) setDown(down bool) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.down = down
+}
+
+func newTestPusher(serverUrl string) *ScorePusher {
+	b := testutil.NewTestBundle()
+	b.Config.Ctfd = bundle.CtfdConfig{Enabled: true, BaseUrl: serverUrl, ApiToken: "test-token"}
+	return NewScorePusher(b)
+}
+
+func TestPushScore(t *testing.T) {
+	t.Run("awards the difference to the already pushed score", func(t *testing.T) {
+		ctfd, server := newFakeCtfd(map[string]int{"foobar": 1, "foobar-2": 2})
+		defer server.Close()
+		client := NewClient(bundle.CtfdConfig{BaseUrl: server.URL, ApiToken: "test-token"})
+
+		assert.Nil(t, client.PushScore(context.Background(), "foobar", 50))
+		assert.Nil(t, client.PushScore(context.Background(), "foobar", 80))
+		assert.Nil(t, client.PushScore(context.Background(), "foobar", 80))
+
+		assert.Equal(t, 80, ctfd.awardedPoints(1))
+		assert.Len(t, ctfd.awards[1], 2, "Should not create an award if the score didn't change")
+		assert.Equal(t, 0, ctfd.awardedPoints(2), "Should only match accounts with the exact same name")
+	})
+
+	t.Run("ignores awards not created by multi-juicer", func(t *testing.T) {
+		ctfd, server := newFakeCtfd(map[string]int{"foobar": 1})
+		defer server.Close()
+		ctfd.awards[1] = []ctfdAward{{Name: "First Blood", Value: 100}}
+		client := NewClient(bundle.CtfdConfig{BaseUrl: server.URL, ApiToken: "test-token"})
+
+		assert.Nil(t, client.PushScore(context.Background(), "foobar", 30))
+
+		assert.Equal(t, 130, ctfd.awardedPoints(1))
+	})
+
+	t.Run("returns ErrAccountNotFound for teams unknown to ctfd", func(t *testing.T) {
+		_, server := newFakeCtfd(map[string]int{})
+		defer server.Close()
+		client := NewClient(bundle.CtfdConfig{BaseUrl: server.URL, ApiToken: "test-token"})
+
+		assert.ErrorIs(t, client.PushScore(context.Background(), "foobar", 30), ErrAccountNotFound)
+	})
+}
+
+func TestScorePusher(t *testing.T) {
+	t.Run("keeps scores queued while ctfd is down", func(t *testing.T) {
+		ctfd, server := newFakeCtfd(map[string]int{"foobar": 1, "barfoo": 2})
+		defer server.Close()
+		pusher := newTestPusher(server.URL)
+
+		ctfd.setDown(true)
+		pusher.enqueue([]*scoring.TeamScore{{Name: "foobar", Score: 50, InstanceReadiness: true}, {Name: "barfoo", Score: 20, InstanceReadiness: true}})
+		assert.False(t, pusher.pushPending(context.Background()))
+		assert.Equal(t, map[string]int{"foobar": 50, "barfoo": 20}, pusher.pending)
+
+		ctfd.setDown(false)
+		pusher.enqueue([]*scoring.TeamScore{{Name: "foobar", Score: 60, InstanceReadiness: true}, {Name: "barfoo", Score: 20, InstanceReadiness: true}})
+		assert.True(t, pusher.pushPending(context.Background()))
+		assert.Empty(t, pusher.pending)
+		assert.Equal(t, 60, ctfd.awardedPoints(1), "Should only push the latest score")
+		assert.Equal(t, 20, ctfd.awardedPoints(2))
+	})
+
+	t.Run("doesn't queue unchanged scores", func(t *testing.T) {
+		_, server := newFakeCtfd(map[string]int{"foobar": 1})
+		defer server.Close()
+		pusher := newTestPusher(server.URL)
+
+		pusher.enqueue([]*scoring.TeamScore{{Name: "foobar", Score: 50, InstanceReadiness: true}})
+		assert.True(t, pusher.pushPending(context.Background()))
+		pusher.enqueue([]*scoring.TeamScore{{Name: "foobar", Score: 50, InstanceReadiness: true}})
+
+		assert.Empty(t, pusher.pending)
+	})
+
+	t.Run("skips withheld scores of not ready instances", func(t *testing.T) {
+		_, server := newFakeCtfd(map[string]int{"foobar": 1})
+		defer server.Close()
+		pusher := newTestPusher(server.URL)
+		pusher.bundle.Config.HideScoresOfNotReadyInstances = true
+
+		pusher.enqueue([]*scoring.TeamScore{{Name: "foobar", Score: 0, InstanceReadiness: false}})
+
+		assert.Empty(t, pusher.pending)
+	})
+
+	t.Run("skips teams without ctfd account", func(t *testing.T) {
+		_, server := newFakeCtfd(map[string]int{})
+		defer server.Close()
+		pusher := newTestPusher(server.URL)
+
+		pusher.enqueue([]*scoring.TeamScore{{Name: "foobar", Score: 50, InstanceReadiness: true}})
+
+		assert.True(t, pusher.pushPending(context.Background()))
+		assert.Empty(t, pusher.pending)
+	})
+}
@@ -0,0 +1,108 @@
+package scoring
+
+import (
+	"sort"
+	"time"
+)
+
+// maxSolveEvents bounds the number of solve events kept in memory for the activity feed
+const maxSolveEvents = 1000
+
+const (
+	SolveEventType      = "solve"
+	TeamJoinedEventType = "team-joined"
+)
+
+// SolveEvent is a challenge solve or a newly joined team detected by the scoring watcher
+type SolveEvent struct {
+	// Id increases with every event, so that clients can page through the feed
+	Id   int64
+	Type string
+	Team string
+	// Challenge is empty for team-joined events
+	Challenge string
+	// SolvedAt is the creation time of the instance for team-joined events
+	SolvedAt time.Time
+	// teamKey identifies the team across namespaces, see TeamKey
+	teamKey string
+}
+
+// recordSolveEvents adds the newly solved challenges of the updated scores to the activity feed. Must be called while holding the currentScoresMutex
+func (s *ScoringService) recordSolveEvents(updatedScores []*TeamScore) {
+	events := []SolveEvent{}
+	for _, score := range updatedScores {
+		if len(score.NewlySolved) == 0 || score.ExcludedFromScoreboard {
+			continue
+		}
+		solvedAt := make(map[string]time.Time, len(score.Challenges))
+		for _, challenge := range score.Challenges {
+			solvedAt[challenge.Key] = challenge.SolvedAt
+		}
+		for _, challenge := range score.NewlySolved {
+			events = append(events, SolveEvent{Type: SolveEventType, Team: score.Name, Challenge: challenge, SolvedAt: solvedAt[challenge], teamKey: score.Key()})
+		}
+	}
+	s.appendActivityEvents(events)
+}
+
+// recordTeamJoinedEvents adds an event for each team which got its first instance since the balancer started. Must be called while holding the currentScoresMutex
+func (s *ScoringService) recordTeamJoinedEvents(joinedScores []*TeamScore) {
+	events := []SolveEvent{}
+	for _, score := range joinedScores {
+		if score.ExcludedFromScoreboard {
+			continue
+		}
+		events = append(events, SolveEvent{Type: TeamJoinedEventType, Team: score.Name, SolvedAt: score.instanceCreatedAt, teamKey: score.Key()})
+	}
+	s.appendActivityEvents(events)
+}
+
+// appendActivityEvents assigns ids to the events and adds them to the bounded feed. Must be called while holding the currentScoresMutex
+func (s *ScoringService) appendActivityEvents(events []SolveEvent) {
+	// the updates are collected from a map, sorting keeps the feed chronological within a batch of updates
+	sort.SliceStable(events, func(i, j int) bool {
+		if !events[i].SolvedAt.Equal(events[j].SolvedAt) {
+			return events[i].SolvedAt.Before(events[j].SolvedAt)
+		}
+		return events[i].Team < events[j].Team
+	})
+
+	for _, event := range events {
+		s.lastSolveEventId++
+		event.Id = s.lastSolveEventId
+		s.solveEvents = append(s.solveEvents, event)
+	}
+	if len(s.solveEvents) > maxSolveEvents {
+		s.solveEvents = append([]SolveEvent{}, s.solveEvents[len(s.solveEvents)-maxSolveEvents:]...)
+	}
+}
+
+// GetSolveEvents returns up to limit events with an id greater than afterId, oldest first. Returns the latest events if afterId is 0.
+// Once the public scoreboard is frozen, events after the freeze are left out. Solves of teams whose scores are currently withheld are left out as well. Team-joined events are only included if includeTeamJoins is set
+func (s *ScoringService) GetSolveEvents(afterId int64, limit int, includeTeamJoins bool) []SolveEvent {
+	s.currentScoresMutex.Lock()
+	defer s.currentScoresMutex.Unlock()
+
+	s.freezeScoresIfDue()
+	events := make([]SolveEvent, 0, limit)
+	for _, event := range s.solveEvents {
+		if event.Id <= afterId || (event.Type == TeamJoinedEventType && !includeTeamJoins) {
+			continue
+		}
+		if s.frozenScoresSorted != nil && event.SolvedAt.After(s.frozenAt) {
+			continue
+		}
+		if event.Type == SolveEventType && s.isWithheld(event.teamKey) {
+			continue
+		}
+		events = append(events, event)
+	}
+
+	if afterId == 0 && len(events) > limit {
+		return events[len(events)-limit:]
+	}
+	if len(events) > limit {
+		return events[:limit]
+	}
+	return events
+}
@@ -0,0 +1,98 @@
+package scoring
+
+import (
+	"testing"
+	"time"
+
+	"github.com/juice-shop/multi-juicer/balancer/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSolveEvents(t *testing.T) {
+	solvedScore := func(team string, solvedAt time.Time) *TeamScore {
+		return &TeamScore{
+			Name:        team,
+			Challenges:  []ChallengeProgress{{Key: "scoreBoardChallenge", SolvedAt: solvedAt}},
+			NewlySolved: []string{"scoreBoardChallenge"},
+		}
+	}
+
+	t.Run("keeps only the latest events", func(t *testing.T) {
+		scoringService := NewScoringService(testutil.NewTestBundle())
+		start := time.Date(2024, 11, 1, 19, 0, 0, 0, time.UTC)
+
+		scoringService.currentScoresMutex.Lock()
+		for i := 0; i < maxSolveEvents+10; i++ {
+			scoringService.recordSolveEvents([]*TeamScore{solvedScore("foobar", start.Add(time.Duration(i)*time.Second))})
+		}
+		scoringService.currentScoresMutex.Unlock()
+
+		assert.Len(t, scoringService.solveEvents, maxSolveEvents)
+		assert.Equal(t, int64(11), scoringService.solveEvents[0].Id)
+
+		events := scoringService.GetSolveEvents(int64(maxSolveEvents+8), 10, true)
+		assert.Len(t, events, 2)
+		assert.Equal(t, int64(maxSolveEvents+9), events[0].Id)
+	})
+
+	t.Run("leaves out solves after the scoreboard freeze", func(t *testing.T) {
+		bundle := testutil.NewTestBundle()
+		freezeAt := time.Now().Add(-time.Minute)
+		bundle.Config.FreezeScoreboardAt = &freezeAt
+		scoringService := NewScoringService(bundle)
+		scoringService.scoresLoaded = true
+
+		scoringService.currentScoresMutex.Lock()
+		scoringService.recordSolveEvents([]*TeamScore{
+			solvedScore("foobar", freezeAt.Add(-time.Minute)),
+			solvedScore("barfoo", freezeAt.Add(time.Second)),
+		})
+		scoringService.currentScoresMutex.Unlock()
+
+		events := scoringService.GetSolveEvents(0, 10, true)
+		assert.Len(t, events, 1)
+		assert.Equal(t, "foobar", events[0].Team)
+	})
+
+	t.Run("leaves out solves of teams whose scores are withheld", func(t *testing.T) {
+		bundle := testutil.NewTestBundle()
+		bundle.Config.HideScoresOfNotReadyInstances = true
+		scoringService := NewScoringService(bundle)
+		solvedAt := time.Date(2024, 11, 1, 19, 0, 0, 0, time.UTC)
+		ready := solvedScore("foobar", solvedAt)
+		ready.InstanceReadiness = true
+		notReady := solvedScore("barfoo", solvedAt)
+
+		scoringService.currentScoresMutex.Lock()
+		scoringService.currentScores = map[string]*TeamScore{ready.Key(): ready, notReady.Key(): notReady}
+		scoringService.recordSolveEvents([]*TeamScore{ready, notReady})
+		scoringService.currentScoresMutex.Unlock()
+
+		events := scoringService.GetSolveEvents(0, 10, true)
+		assert.Len(t, events, 1)
+		assert.Equal(t, "foobar", events[0].Team)
+	})
+
+	t.Run("records team-joined events which can be left out", func(t *testing.T) {
+		scoringService := NewScoringService(testutil.NewTestBundle())
+		createdAt := time.Date(2024, 11, 1, 19, 0, 0, 0, time.UTC)
+
+		scoringService.currentScoresMutex.Lock()
+		scoringService.recordTeamJoinedEvents([]*TeamScore{
+			{Name: "foobar", instanceCreatedAt: createdAt},
+			{Name: "staff", instanceCreatedAt: createdAt, ExcludedFromScoreboard: true},
+		})
+		scoringService.recordSolveEvents([]*TeamScore{solvedScore("foobar", createdAt.Add(time.Minute))})
+		scoringService.currentScoresMutex.Unlock()
+
+		events := scoringService.GetSolveEvents(0, 10, true)
+		assert.Equal(t, []SolveEvent{
+			{Id: 1, Type: TeamJoinedEventType, Team: "foobar", SolvedAt: createdAt, teamKey: TeamKey("", "foobar")},
+			{Id: 2, Type: SolveEventType, Team: "foobar", Challenge: "scoreBoardChallenge", SolvedAt: createdAt.Add(time.Minute), teamKey: TeamKey("", "foobar")},
+		}, events)
+
+		events = scoringService.GetSolveEvents(0, 10, false)
+		assert.Len(t, events, 1)
+		assert.Equal(t, SolveEventType, events[0].Type)
+	})
+}
@@ -0,0 +1,46 @@
+package scoring
+
+import (
+	"context"
+	"time"
+)
+
+func (s *ScoringService) notifyDeploymentChange() {
+	s.deploymentChangesMutex.Lock()
+	defer s.deploymentChangesMutex.Unlock()
+	s.deploymentChanges++
+	close(s.deploymentChangeNotifier)
+	s.deploymentChangeNotifier = make(chan struct{})
+}
+
+// DeploymentChanges returns the number of JuiceShop deployment events seen by the scoring watcher. Pass it to WaitForDeploymentChanges to wait for the next change
+func (s *ScoringService) DeploymentChanges() int64 {
+	s.deploymentChangesMutex.Lock()
+	defer s.deploymentChangesMutex.Unlock()
+	return s.deploymentChanges
+}
+
+// WaitForDeploymentChanges blocks until a JuiceShop deployment got added, modified or deleted after lastSeenChanges. Returns the new change count and true, or false if maxWaitTime passed without changes or the context got canceled
+func (s *ScoringService) WaitForDeploymentChanges(ctx context.Context, lastSeenChanges int64) (int64, bool) {
+	timeout := time.NewTimer(maxWaitTime)
+	defer timeout.Stop()
+
+	for {
+		s.deploymentChangesMutex.Lock()
+		if s.deploymentChanges > lastSeenChanges {
+			changes := s.deploymentChanges
+			s.deploymentChangesMutex.Unlock()
+			return changes, true
+		}
+		changed := s.deploymentChangeNotifier
+		s.deploymentChangesMutex.Unlock()
+
+		select {
+		case <-changed:
+		case <-timeout.C:
+			return lastSeenChanges, false
+		case <-ctx.Done():
+			return lastSeenChanges, false
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package scoring
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/juice-shop/multi-juicer/balancer/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWaitForDeploymentChanges(t *testing.T) {
+	t.Run("returns immediately if changes happened since the last seen count", func(t *testing.T) {
+		scoringService := NewScoringService(testutil.NewTestBundle())
+		lastSeen := scoringService.DeploymentChanges()
+		scoringService.notifyDeploymentChange()
+
+		changes, changed := scoringService.WaitForDeploymentChanges(context.Background(), lastSeen)
+		assert.True(t, changed)
+		assert.Equal(t, lastSeen+1, changes)
+	})
+
+	t.Run("wakes up waiting goroutines on changes", func(t *testing.T) {
+		scoringService := NewScoringService(testutil.NewTestBundle())
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			scoringService.notifyDeploymentChange()
+		}()
+
+		changes, changed := scoringService.WaitForDeploymentChanges(context.Background(), scoringService.DeploymentChanges())
+		assert.True(t, changed)
+		assert.Equal(t, int64(1), changes)
+	})
+
+	t.Run("returns without changes once the context got canceled", func(t *testing.T) {
+		scoringService := NewScoringService(testutil.NewTestBundle())
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		changes, changed := scoringService.WaitForDeploymentChanges(ctx, 0)
+		assert.False(t, changed)
+		assert.Equal(t, int64(0), changes)
+	})
+}
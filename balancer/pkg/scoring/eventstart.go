@@ -0,0 +1,11 @@
+package scoring
+
+import "time"
+
+// EventStart returns the configured start of the event, or the start time of the scoring service if none is configured
+func (s *ScoringService) EventStart() time.Time {
+	if s.bundle.Config.EventStart != nil {
+		return *s.bundle.Config.EventStart
+	}
+	return s.startedAt
+}
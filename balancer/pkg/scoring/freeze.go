@@ -0,0 +1,110 @@
+package scoring
+
+import (
+	"context"
+	"time"
+)
+
+// freezeScoresIfDue keeps a copy of the current scoreboard once the configured freeze time has passed. Must be called while holding the currentScoresMutex, before applying score changes.
+// The scores are only frozen lazily on the first change or read after the freeze time, which is equivalent as the scoreboard can't have changed in between.
+// A balancer started after the freeze time without a score snapshot freezes the first scores it calculates
+func (s *ScoringService) freezeScoresIfDue() {
+	freezeAt := s.bundle.Config.FreezeScoreboardAt
+	if freezeAt == nil || s.frozenScoresSorted != nil || !s.scoresLoaded || time.Now().Before(*freezeAt) {
+		return
+	}
+	s.frozenScoresSorted = make([]*TeamScore, len(s.currentScoresSorted))
+	copy(s.frozenScoresSorted, s.currentScoresSorted)
+	s.frozenAt = *freezeAt
+	s.bundle.Log.Printf("Froze the public scoreboard with %d teams. Scoring continues for the admin routes", len(s.frozenScoresSorted))
+}
+
+// GetPublicTopScoresWithLastUpdate returns the scoreboard shown to the teams. Once the scoreboard is frozen these are the sorted scores as of the freeze, with the freeze time as last update
+func (s *ScoringService) GetPublicTopScoresWithLastUpdate() ([]*TeamScore, time.Time, bool) {
+	s.currentScoresMutex.Lock()
+	defer s.currentScoresMutex.Unlock()
+
+	s.freezeScoresIfDue()
+	scores, lastUpdate, frozen := s.publicScores()
+	copiedScores := make([]*TeamScore, len(scores))
+	copy(copiedScores, scores)
+	return copiedScores, lastUpdate, frozen
+}
+
+// WaitForPublicUpdatesNewerThan is like WaitForUpdatesNewerThan, but for the public scoreboard which stops updating once it's frozen
+// Also reports whether the returned scores are frozen
+func (s *ScoringService) WaitForPublicUpdatesNewerThan(ctx context.Context, lastSeenUpdate time.Time) ([]*TeamScore, bool) {
+	timeout := time.NewTimer(maxWaitTime)
+	defer timeout.Stop()
+
+	for {
+		s.currentScoresMutex.Lock()
+		s.freezeScoresIfDue()
+		scores, lastUpdate, frozen := s.publicScores()
+		if lastUpdate.After(lastSeenUpdate) {
+			s.currentScoresMutex.Unlock()
+			return scores, frozen
+		}
+		updated := s.updateNotifier
+		s.currentScoresMutex.Unlock()
+
+		select {
+		case <-updated:
+			// scores got updated, loop around to check if the public scoreboard changed
+		case <-timeout.C:
+			return nil, false
+		case <-ctx.Done():
+			return nil, false
+		}
+	}
+}
+
+// WaitForPublicTeamUpdatesNewerThan is like WaitForTeamUpdatesNewerThan, but for the public scoreboard. Once it's frozen the team never gets updated and the wait runs into the timeout
+func (s *ScoringService) WaitForPublicTeamUpdatesNewerThan(ctx context.Context, team string, lastSeenUpdate time.Time) (*TeamScore, int) {
+	timeout := time.NewTimer(maxWaitTime)
+	defer timeout.Stop()
+
+	for {
+		s.currentScoresMutex.Lock()
+		s.freezeScoresIfDue()
+		scores, _, _ := s.publicScores()
+		if score, ok := s.findPublicScore(scores, team); ok && score.LastUpdate.After(lastSeenUpdate) {
+			s.currentScoresMutex.Unlock()
+			return score, len(scores)
+		}
+		updated := s.updateNotifier
+		s.currentScoresMutex.Unlock()
+
+		select {
+		case <-updated:
+			// scores got updated, loop around to check if the team was part of the update
+		case <-timeout.C:
+			return nil, 0
+		case <-ctx.Done():
+			return nil, 0
+		}
+	}
+}
+
+// findPublicScore looks up a team of the balancers namespace in the public scores. Teams excluded from the scoreboard aren't part of them
+func (s *ScoringService) findPublicScore(scores []*TeamScore, team string) (*TeamScore, bool) {
+	key := TeamKey(s.bundle.RuntimeEnvironment.Namespace, team)
+	for _, score := range scores {
+		if score.Key() == key {
+			return score, true
+		}
+	}
+	return nil, false
+}
+
+// publicScores must be called while holding the currentScoresMutex
+func (s *ScoringService) publicScores() ([]*TeamScore, time.Time, bool) {
+	scores, lastUpdate, frozen := s.currentScoresSorted, s.lastUpdate, false
+	if s.frozenScoresSorted != nil {
+		scores, lastUpdate, frozen = s.frozenScoresSorted, s.frozenAt, true
+	}
+	if s.bundle.Config.HideScoresOfNotReadyInstances {
+		scores = s.withholdScoresOfNotReadyInstances(scores, lastUpdate)
+	}
+	return scores, lastUpdate, frozen
+}
@@ -0,0 +1,64 @@
+package scoring
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/juice-shop/multi-juicer/balancer/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScoreboardFreeze(t *testing.T) {
+	t.Run("public scoreboard is live until the freeze time", func(t *testing.T) {
+		bundle := testutil.NewTestBundle()
+		freezeAt := time.Now().Add(time.Hour)
+		bundle.Config.FreezeScoreboardAt = &freezeAt
+		scoringService := NewScoringServiceWithInitialScores(bundle, map[string]*TeamScore{
+			"foo": {Name: "foo", Score: 10, Challenges: []ChallengeProgress{}},
+		})
+
+		scores, _, frozen := scoringService.GetPublicTopScoresWithLastUpdate()
+		assert.False(t, frozen)
+		assert.Equal(t, scoringService.GetTopScores(), scores)
+	})
+
+	t.Run("public scoreboard keeps the scores of the freeze while the live scores continue", func(t *testing.T) {
+		bundle := testutil.NewTestBundle()
+		freezeAt := time.Now().Add(-time.Minute)
+		bundle.Config.FreezeScoreboardAt = &freezeAt
+		scoringService := NewScoringServiceWithInitialScores(bundle, map[string]*TeamScore{
+			"foo": {Name: "foo", Score: 10, Challenges: []ChallengeProgress{}},
+		})
+
+		// no deployments exist anymore, so recalculating drops the team from the live scores
+		assert.Nil(t, scoringService.CalculateAndCacheScoreBoard(context.Background()))
+		assert.Len(t, scoringService.GetTopScores(), 0)
+
+		scores, lastUpdate, frozen := scoringService.GetPublicTopScoresWithLastUpdate()
+		assert.True(t, frozen)
+		assert.True(t, freezeAt.Equal(lastUpdate))
+		assert.Len(t, scores, 1)
+		assert.Equal(t, "foo", scores[0].Name)
+
+		waitedScores, waitedFrozen := scoringService.WaitForPublicUpdatesNewerThan(context.Background(), freezeAt.Add(-time.Second))
+		assert.True(t, waitedFrozen)
+		assert.Equal(t, scores, waitedScores)
+	})
+
+	t.Run("waiting for updates of a frozen scoreboard doesn't return live updates", func(t *testing.T) {
+		bundle := testutil.NewTestBundle()
+		freezeAt := time.Now().Add(-time.Minute)
+		bundle.Config.FreezeScoreboardAt = &freezeAt
+		scoringService := NewScoringServiceWithInitialScores(bundle, map[string]*TeamScore{
+			"foo": {Name: "foo", Score: 10, Challenges: []ChallengeProgress{}},
+		})
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		go scoringService.CalculateAndCacheScoreBoard(context.Background())
+
+		scores, _ := scoringService.WaitForPublicUpdatesNewerThan(ctx, freezeAt)
+		assert.Nil(t, scores)
+	})
+}
@@ -0,0 +1,20 @@
+package scoring
+
+import (
+	"time"
+
+	"github.com/juice-shop/multi-juicer/balancer/pkg/bundle"
+)
+
+// holdScoreOfNewInstance returns the previous score of the team if its instance was created less than the configured grace period ago and reports fewer solved challenges than before.
+// Fresh instances briefly report no progress until the progress-watchdog restored it, which would otherwise drop the team to 0 points for a moment. Returns the current score otherwise
+func holdScoreOfNewInstance(config *bundle.Config, previous *TeamScore, current *TeamScore, now time.Time) *TeamScore {
+	gracePeriod := config.NewInstanceGracePeriodDuration()
+	if gracePeriod == 0 || previous == nil || current.instanceCreatedAt.IsZero() {
+		return current
+	}
+	if now.Sub(current.instanceCreatedAt) >= gracePeriod || len(current.Challenges) >= len(previous.Challenges) {
+		return current
+	}
+	return previous
+}
@@ -0,0 +1,97 @@
+package scoring
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/juice-shop/multi-juicer/balancer/pkg/bundle"
+	"github.com/juice-shop/multi-juicer/balancer/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestHoldScoreOfNewInstance(t *testing.T) {
+	now := time.Date(2024, 11, 1, 20, 0, 0, 0, time.UTC)
+	config := &bundle.Config{NewInstanceGracePeriod: "30s"}
+	previous := &TeamScore{Name: "foobar", Score: 10, Challenges: []ChallengeProgress{{Key: "scoreBoardChallenge"}}}
+	restoring := &TeamScore{Name: "foobar", Score: 0, Challenges: []ChallengeProgress{}, instanceCreatedAt: now.Add(-5 * time.Second)}
+
+	assert.Same(t, previous, holdScoreOfNewInstance(config, previous, restoring, now), "Should hold the score of instances within the grace period")
+	assert.Same(t, restoring, holdScoreOfNewInstance(config, previous, restoring, now.Add(time.Minute)), "Should stop holding the score once the grace period is over")
+	assert.Same(t, restoring, holdScoreOfNewInstance(config, nil, restoring, now), "Should score teams without a previous score")
+	assert.Same(t, restoring, holdScoreOfNewInstance(&bundle.Config{}, previous, restoring, now), "Should be disabled by default")
+
+	progressed := &TeamScore{Name: "foobar", Score: 50, Challenges: []ChallengeProgress{{Key: "scoreBoardChallenge"}, {Key: "nullByteChallenge"}}, instanceCreatedAt: now.Add(-5 * time.Second)}
+	assert.Same(t, progressed, holdScoreOfNewInstance(config, previous, progressed, now), "Should never hold back progress")
+}
+
+func TestNewInstanceGracePeriod(t *testing.T) {
+	createTeam := func(challenges string, createdAt time.Time) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              "juiceshop-foobar",
+				Namespace:         "test-namespace",
+				CreationTimestamp: metav1.NewTime(createdAt),
+				Annotations: map[string]string{
+					"multi-juicer.owasp-juice.shop/challenges": challenges,
+				},
+				Labels: map[string]string{
+					"app.kubernetes.io/name":    "juice-shop",
+					"app.kubernetes.io/part-of": "multi-juicer",
+					"team":                      "foobar",
+				},
+			},
+			Status: appsv1.DeploymentStatus{ReadyReplicas: 1},
+		}
+	}
+	knownScores := func() map[string]*TeamScore {
+		return map[string]*TeamScore{
+			"foobar": {Name: "foobar", Score: 10, Position: 1, Challenges: []ChallengeProgress{{Key: "scoreBoardChallenge", SolvedAt: time.Date(2024, 11, 1, 19, 55, 48, 0, time.UTC)}}},
+		}
+	}
+
+	t.Run("holds the restored score while a recreated instance restores its progress", func(t *testing.T) {
+		// the instance got recreated after the balancer restored the scores from a snapshot, but the progress-watchdog hasn't restored its progress yet
+		clientset := fake.NewSimpleClientset(createTeam("[]", time.Now().Add(-5*time.Second)))
+		bundle := testutil.NewTestBundleWithCustomFakeClient(clientset)
+		bundle.Config.NewInstanceGracePeriod = "30s"
+		scoringService := NewScoringServiceWithInitialScores(bundle, knownScores())
+
+		require.NoError(t, scoringService.CalculateAndCacheScoreBoard(context.Background()))
+		score, ok := scoringService.GetScoreForTeam("foobar")
+		require.True(t, ok)
+		assert.Equal(t, 10, score.Score)
+
+		// the progress got restored
+		restored := createTeam(`[{"key":"scoreBoardChallenge","solvedAt":"2024-11-01T19:55:48Z"},{"key":"nullByteChallenge","solvedAt":"2024-11-01T20:10:00Z"}]`, time.Now().Add(-5*time.Second))
+		scoringService.applyDeploymentUpdates(map[string]*appsv1.Deployment{TeamKey("test-namespace", "foobar"): restored})
+		score, _ = scoringService.GetScoreForTeam("foobar")
+		assert.Equal(t, 50, score.Score)
+	})
+
+	t.Run("scores instances which are older than the grace period", func(t *testing.T) {
+		clientset := fake.NewSimpleClientset(createTeam("[]", time.Now().Add(-time.Minute)))
+		bundle := testutil.NewTestBundleWithCustomFakeClient(clientset)
+		bundle.Config.NewInstanceGracePeriod = "30s"
+		scoringService := NewScoringServiceWithInitialScores(bundle, knownScores())
+
+		require.NoError(t, scoringService.CalculateAndCacheScoreBoard(context.Background()))
+		score, ok := scoringService.GetScoreForTeam("foobar")
+		require.True(t, ok)
+		assert.Equal(t, 0, score.Score)
+	})
+
+	t.Run("holds the score on incremental updates too", func(t *testing.T) {
+		bundle := testutil.NewTestBundle()
+		bundle.Config.NewInstanceGracePeriod = "30s"
+		scoringService := NewScoringServiceWithInitialScores(bundle, knownScores())
+
+		scoringService.applyDeploymentUpdates(map[string]*appsv1.Deployment{TeamKey("test-namespace", "foobar"): createTeam("[]", time.Now().Add(-5*time.Second))})
+		score, _ := scoringService.GetScoreForTeam("foobar")
+		assert.Equal(t, 10, score.Score)
+	})
+}
@@ -0,0 +1,16 @@
+package scoring
+
+import "time"
+
+// watcherLivenessThreshold is how long the scoring watcher can go without receiving events or re-listing the deployments before it's considered dead.
+// Bookmark events are sent roughly every minute and the watch gets restarted whenever the kubernetes api closes it, so a healthy watcher shows activity well within the threshold
+const watcherLivenessThreshold = 5 * time.Minute
+
+func (s *ScoringService) markWatcherActive() {
+	s.lastWatchActivity.Store(time.Now().UnixNano())
+}
+
+// IsWatcherAlive reports if the scoring watcher showed signs of life recently. A dead watcher silently freezes the scoreboard, so the balancer should get restarted
+func (s *ScoringService) IsWatcherAlive() bool {
+	return time.Since(time.Unix(0, s.lastWatchActivity.Load())) < watcherLivenessThreshold
+}
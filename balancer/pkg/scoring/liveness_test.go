@@ -0,0 +1,25 @@
+package scoring
+
+import (
+	"testing"
+	"time"
+
+	"github.com/juice-shop/multi-juicer/balancer/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsWatcherAlive(t *testing.T) {
+	t.Run("is alive after the scoring service got created", func(t *testing.T) {
+		scoringService := NewScoringService(testutil.NewTestBundle())
+		assert.True(t, scoringService.IsWatcherAlive())
+	})
+
+	t.Run("is dead if the watcher wasn't active within the threshold", func(t *testing.T) {
+		scoringService := NewScoringService(testutil.NewTestBundle())
+		scoringService.lastWatchActivity.Store(time.Now().Add(-watcherLivenessThreshold - time.Second).UnixNano())
+		assert.False(t, scoringService.IsWatcherAlive())
+
+		scoringService.markWatcherActive()
+		assert.True(t, scoringService.IsWatcherAlive())
+	})
+}
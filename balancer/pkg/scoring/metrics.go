@@ -0,0 +1,42 @@
+package scoring
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var scoreboardRecomputeDuration = prometheus.NewHistogramVec(
+	prometheus.HistogramOpts{
+		Name:    "multijuicer_scoreboard_recompute_seconds",
+		Help:    `Time it took to recompute the scoreboard. "full" recomputes all teams after listing the deployments, "incremental" only the teams changed by watch events.`,
+		Buckets: prometheus.ExponentialBuckets(0.0005, 2, 14),
+	},
+	[]string{"type"},
+)
+
+var scoreboardTeamsGauge = prometheus.NewGauge(
+	prometheus.GaugeOpts{
+		Name: "multijuicer_scoreboard_teams",
+		Help: `Number of teams on the scoreboard as of the last recompute.`,
+	},
+)
+
+var coalescedWatchEventsCounter = prometheus.NewCounter(
+	prometheus.CounterOpts{
+		Name: "multijuicer_scoring_coalesced_watch_events_total",
+		Help: `Number of deployment watch events which replaced a still pending event of the same team, so that only the latest state of the team got scored.`,
+	},
+)
+
+func init() {
+	prometheus.MustRegister(scoreboardRecomputeDuration)
+	prometheus.MustRegister(scoreboardTeamsGauge)
+	prometheus.MustRegister(coalescedWatchEventsCounter)
+}
+
+// observeRecompute records the duration of a recompute which started at start together with the resulting team count
+func observeRecompute(recomputeType string, start time.Time, teamCount int) {
+	scoreboardRecomputeDuration.WithLabelValues(recomputeType).Observe(time.Since(start).Seconds())
+	scoreboardTeamsGauge.Set(float64(teamCount))
+}
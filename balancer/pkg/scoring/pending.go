@@ -0,0 +1,38 @@
+package scoring
+
+import (
+	appsv1 "k8s.io/api/apps/v1"
+)
+
+// maxPendingDeployments caps the size of a batch. Once that many teams are pending they get applied right away instead of waiting for the debounce, so that a solve storm across many teams is processed in bounded batches
+const maxPendingDeployments = 100
+
+// pendingDeploymentUpdates collects the deployments modified since the last recalculation by TeamKey. Only the latest version of each deployment is kept, so stale intermediate states are never scored
+type pendingDeploymentUpdates struct {
+	deployments map[string]*appsv1.Deployment
+}
+
+func newPendingDeploymentUpdates() *pendingDeploymentUpdates {
+	return &pendingDeploymentUpdates{deployments: map[string]*appsv1.Deployment{}}
+}
+
+// add replaces any pending version of the deployment. Returns true once the batch is full and should be applied
+func (p *pendingDeploymentUpdates) add(deployment *appsv1.Deployment) bool {
+	teamKey := TeamKey(deployment.Namespace, deployment.Labels["team"])
+	if _, ok := p.deployments[teamKey]; ok {
+		coalescedWatchEventsCounter.Inc()
+	}
+	p.deployments[teamKey] = deployment
+	return len(p.deployments) >= maxPendingDeployments
+}
+
+func (p *pendingDeploymentUpdates) remove(teamKey string) {
+	delete(p.deployments, teamKey)
+}
+
+// take returns the pending deployments and starts a new batch
+func (p *pendingDeploymentUpdates) take() map[string]*appsv1.Deployment {
+	deployments := p.deployments
+	p.deployments = map[string]*appsv1.Deployment{}
+	return deployments
+}
@@ -0,0 +1,52 @@
+package scoring
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestPendingDeploymentUpdates(t *testing.T) {
+	createDeployment := func(team string, challenges string) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        fmt.Sprintf("juiceshop-%s", team),
+				Namespace:   "test-namespace",
+				Annotations: map[string]string{"multi-juicer.owasp-juice.shop/challenges": challenges},
+				Labels:      map[string]string{"team": team},
+			},
+		}
+	}
+
+	t.Run("only keeps the latest version of each team", func(t *testing.T) {
+		pending := newPendingDeploymentUpdates()
+		pending.add(createDeployment("foobar", `[]`))
+		pending.add(createDeployment("barfoo", `[]`))
+		latest := createDeployment("foobar", `[{"key":"scoreBoardChallenge","solvedAt":"2024-11-01T19:55:48.211Z"}]`)
+		pending.add(latest)
+
+		deployments := pending.take()
+		assert.Len(t, deployments, 2)
+		assert.Same(t, latest, deployments[TeamKey("test-namespace", "foobar")])
+		assert.Empty(t, pending.take(), "Taking the deployments should start a new batch")
+	})
+
+	t.Run("reports full batches", func(t *testing.T) {
+		pending := newPendingDeploymentUpdates()
+		for i := 0; i < maxPendingDeployments-1; i++ {
+			assert.False(t, pending.add(createDeployment(fmt.Sprintf("team-%d", i), `[]`)))
+		}
+		assert.False(t, pending.add(createDeployment("team-0", `[]`)), "Updates of already pending teams don't grow the batch")
+		assert.True(t, pending.add(createDeployment("one-too-many", `[]`)))
+	})
+
+	t.Run("removes deleted teams", func(t *testing.T) {
+		pending := newPendingDeploymentUpdates()
+		pending.add(createDeployment("foobar", `[]`))
+		pending.remove(TeamKey("test-namespace", "foobar"))
+		assert.Empty(t, pending.take())
+	})
+}
@@ -0,0 +1,89 @@
+package scoring
+
+import (
+	"slices"
+	"sort"
+)
+
+// replaceInSortedScores updates the sorted scoreboard after the score of a single team changed, without re-sorting all teams.
+// previous is the score currently on the scoreboard (nil for new teams), updated the new one (nil for deleted teams).
+// Excluded teams are removed from the scoreboard, but stay in the scores map.
+// Returns a new slice, as the previous one might still be used by requests. Like sortTeamsByScoreAndCalculatePositions, scores which change their position are replaced by copies in the scores map
+func replaceInSortedScores(sortedScores []*TeamScore, scores map[string]*TeamScore, previous *TeamScore, updated *TeamScore) []*TeamScore {
+	result := slices.Clone(sortedScores)
+
+	// first index which might have a different team / position than before
+	changedFrom := len(result)
+	// last index which might have a different team than before. If the teams after it kept their index, their positions only change if the position of the team before them changed
+	changedTo := -1
+
+	if previous != nil {
+		previousIndex := indexOfSortedScore(result, previous)
+		if previousIndex >= 0 {
+			result = slices.Delete(result, previousIndex, previousIndex+1)
+			changedFrom = min(changedFrom, previousIndex)
+			changedTo = max(changedTo, previousIndex)
+		}
+	}
+	if updated != nil && updated.ExcludedFromScoreboard {
+		updated.Position = 0
+	} else if updated != nil {
+		insertIndex := sort.Search(len(result), func(i int) bool {
+			return !teamScoreLess(result[i], updated)
+		})
+		result = slices.Insert(result, insertIndex, updated)
+		changedFrom = min(changedFrom, insertIndex)
+		changedTo = max(changedTo, insertIndex)
+	}
+
+	if len(result) != len(sortedScores) {
+		// all teams after the added / removed one moved by one, so their positions can change even if the position of the team before them didn't
+		changedTo = len(result) - 1
+	}
+
+	recalculatePositions(result, scores, changedFrom, changedTo)
+	return result
+}
+
+// indexOfSortedScore finds the index of the score via binary search. Returns -1 if the score isn't part of the slice
+func indexOfSortedScore(sortedScores []*TeamScore, score *TeamScore) int {
+	index := sort.Search(len(sortedScores), func(i int) bool {
+		return !teamScoreLess(sortedScores[i], score)
+	})
+	if index < len(sortedScores) && sortedScores[index].Key() == score.Key() {
+		return index
+	}
+	// fallback in case the score got modified in place, which would break the binary search
+	for i, sortedScore := range sortedScores {
+		if sortedScore.Key() == score.Key() {
+			return i
+		}
+	}
+	return -1
+}
+
+// recalculatePositions updates the positions starting at changedFrom, stopping at the first unchanged position after changedTo
+func recalculatePositions(sortedScores []*TeamScore, scores map[string]*TeamScore, changedFrom int, changedTo int) {
+	for i := changedFrom; i < len(sortedScores); i++ {
+		// teams with the same score have the same position
+		position := 1
+		if i > 0 {
+			position = sortedScores[i-1].Position
+			if sortedScores[i].Score < sortedScores[i-1].Score {
+				position = i + 1
+			}
+		}
+
+		if sortedScores[i].Position == position {
+			if i > changedTo {
+				return
+			}
+			continue
+		}
+
+		teamScoreCopy := *sortedScores[i]
+		teamScoreCopy.Position = position
+		sortedScores[i] = &teamScoreCopy
+		scores[teamScoreCopy.Key()] = &teamScoreCopy
+	}
+}
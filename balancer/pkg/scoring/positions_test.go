@@ -0,0 +1,184 @@
+package scoring
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// randomTeamScore creates a score with a few solves, using a small range of scores and solve times to get lots of ties
+func randomTeamScore(random *rand.Rand, name string) *TeamScore {
+	challenges := []ChallengeProgress{}
+	score := 0
+	for i := 0; i < random.Intn(5); i++ {
+		challenges = append(challenges, ChallengeProgress{
+			Key:      fmt.Sprintf("challenge-%d", i),
+			SolvedAt: time.Date(2024, 11, 1, 19, random.Intn(3), 0, 0, time.UTC),
+		})
+		score += 10 * (1 + random.Intn(2))
+	}
+	return &TeamScore{
+		Name:       name,
+		Score:      score,
+		Challenges: challenges,
+	}
+}
+
+func copyScores(scores map[string]*TeamScore) map[string]*TeamScore {
+	copied := make(map[string]*TeamScore, len(scores))
+	for team, score := range scores {
+		scoreCopy := *score
+		copied[team] = &scoreCopy
+	}
+	return copied
+}
+
+type sortedTeam struct {
+	Name     string
+	Position int
+}
+
+func toSortedTeams(scores []*TeamScore) []sortedTeam {
+	teams := make([]sortedTeam, len(scores))
+	for i, score := range scores {
+		teams[i] = sortedTeam{Name: score.Name, Position: score.Position}
+	}
+	return teams
+}
+
+func TestReplaceInSortedScores(t *testing.T) {
+	t.Run("matches a full re-sort for a random stream of updates", func(t *testing.T) {
+		random := rand.New(rand.NewSource(42))
+
+		scores := map[string]*TeamScore{}
+		for i := 0; i < 50; i++ {
+			team := fmt.Sprintf("team-%d", i)
+			scores[TeamKey("", team)] = randomTeamScore(random, team)
+		}
+		sorted := sortTeamsByScoreAndCalculatePositions(scores)
+
+		for i := 0; i < 2000; i++ {
+			name := fmt.Sprintf("team-%d", random.Intn(60))
+			team := TeamKey("", name)
+			previous := scores[team]
+
+			if previous != nil && random.Intn(10) == 0 {
+				delete(scores, team)
+				sorted = replaceInSortedScores(sorted, scores, previous, nil)
+			} else {
+				updated := randomTeamScore(random, name)
+				scores[team] = updated
+				sorted = replaceInSortedScores(sorted, scores, previous, updated)
+			}
+
+			expected := sortTeamsByScoreAndCalculatePositions(copyScores(scores))
+			if !assert.Equal(t, toSortedTeams(expected), toSortedTeams(sorted), "update %d", i) {
+				return
+			}
+			for _, score := range sorted {
+				assert.Same(t, scores[score.Key()], score, "map and sorted slice should contain the same scores")
+			}
+		}
+	})
+
+	t.Run("doesn't modify scores which might still be in use", func(t *testing.T) {
+		scores := map[string]*TeamScore{
+			TeamKey("", "foo"): {Name: "foo", Score: 20},
+			TeamKey("", "bar"): {Name: "bar", Score: 10},
+		}
+		sorted := sortTeamsByScoreAndCalculatePositions(scores)
+		previousSorted := sorted
+		bar := scores[TeamKey("", "bar")]
+
+		updated := &TeamScore{Name: "bar", Score: 30}
+		scores[TeamKey("", "bar")] = updated
+		sorted = replaceInSortedScores(sorted, scores, bar, updated)
+
+		assert.Equal(t, []sortedTeam{{Name: "bar", Position: 1}, {Name: "foo", Position: 2}}, toSortedTeams(sorted))
+		assert.Equal(t, []sortedTeam{{Name: "foo", Position: 1}, {Name: "bar", Position: 2}}, toSortedTeams(previousSorted))
+		assert.Equal(t, 2, bar.Position)
+	})
+
+	t.Run("keeps excluded teams off the scoreboard", func(t *testing.T) {
+		scores := map[string]*TeamScore{
+			TeamKey("", "foo"):   {Name: "foo", Score: 20},
+			TeamKey("", "staff"): {Name: "staff", Score: 50, ExcludedFromScoreboard: true},
+			TeamKey("", "bar"):   {Name: "bar", Score: 10},
+		}
+		sorted := sortTeamsByScoreAndCalculatePositions(scores)
+		assert.Equal(t, []sortedTeam{{Name: "foo", Position: 1}, {Name: "bar", Position: 2}}, toSortedTeams(sorted))
+		assert.Equal(t, 0, scores[TeamKey("", "staff")].Position, "Excluded teams should stay in the scores map without a position")
+
+		// excluding a team which is on the scoreboard removes it
+		previous := scores[TeamKey("", "foo")]
+		excluded := &TeamScore{Name: "foo", Score: 20, ExcludedFromScoreboard: true}
+		scores[TeamKey("", "foo")] = excluded
+		sorted = replaceInSortedScores(sorted, scores, previous, excluded)
+		assert.Equal(t, []sortedTeam{{Name: "bar", Position: 1}}, toSortedTeams(sorted))
+		assert.Equal(t, 0, excluded.Position)
+
+		// including it again puts it back
+		included := &TeamScore{Name: "foo", Score: 20}
+		scores[TeamKey("", "foo")] = included
+		sorted = replaceInSortedScores(sorted, scores, excluded, included)
+		assert.Equal(t, []sortedTeam{{Name: "foo", Position: 1}, {Name: "bar", Position: 2}}, toSortedTeams(sorted))
+	})
+}
+
+func TestSortTeamsAcrossNamespaces(t *testing.T) {
+	scores := map[string]*TeamScore{
+		TeamKey("event-a", "foo"): {Name: "foo", Namespace: "event-a", Score: 10},
+		TeamKey("event-b", "foo"): {Name: "foo", Namespace: "event-b", Score: 20},
+	}
+	sorted := sortTeamsByScoreAndCalculatePositions(scores)
+	assert.Len(t, sorted, 2, "Teams with the same name in different namespaces shouldn't collide")
+	assert.Equal(t, "event-b", sorted[0].Namespace)
+	assert.Equal(t, 1, scores[TeamKey("event-b", "foo")].Position)
+	assert.Equal(t, 2, scores[TeamKey("event-a", "foo")].Position)
+
+	// updating one of them leaves the other one untouched
+	previous := scores[TeamKey("event-a", "foo")]
+	updated := &TeamScore{Name: "foo", Namespace: "event-a", Score: 30}
+	scores[TeamKey("event-a", "foo")] = updated
+	sorted = replaceInSortedScores(sorted, scores, previous, updated)
+	assert.Equal(t, []string{"event-a", "event-b"}, []string{sorted[0].Namespace, sorted[1].Namespace})
+	assert.Equal(t, 20, scores[TeamKey("event-b", "foo")].Score)
+}
+
+// simulates a solve burst at a large event: random teams solving one challenge after another
+func benchmarkScoreUpdates(b *testing.B, update func(sorted []*TeamScore, scores map[string]*TeamScore, previous *TeamScore, updated *TeamScore) []*TeamScore) {
+	random := rand.New(rand.NewSource(42))
+	scores := map[string]*TeamScore{}
+	for i := 0; i < 500; i++ {
+		team := fmt.Sprintf("team-%d", i)
+		scores[TeamKey("", team)] = randomTeamScore(random, team)
+	}
+	sorted := sortTeamsByScoreAndCalculatePositions(scores)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		team := TeamKey("", fmt.Sprintf("team-%d", random.Intn(500)))
+		previous := scores[team]
+		updated := *previous
+		updated.Challenges = append(append([]ChallengeProgress{}, previous.Challenges...), ChallengeProgress{
+			Key:      fmt.Sprintf("challenge-%d", len(previous.Challenges)),
+			SolvedAt: time.Date(2024, 11, 1, 20, 0, i, 0, time.UTC),
+		})
+		updated.Score += 10 * (1 + random.Intn(6))
+		scores[team] = &updated
+		sorted = update(sorted, scores, previous, &updated)
+	}
+}
+
+func BenchmarkScoreUpdatesFullResort(b *testing.B) {
+	benchmarkScoreUpdates(b, func(sorted []*TeamScore, scores map[string]*TeamScore, previous *TeamScore, updated *TeamScore) []*TeamScore {
+		return sortTeamsByScoreAndCalculatePositions(scores)
+	})
+}
+
+func BenchmarkScoreUpdatesIncremental(b *testing.B) {
+	benchmarkScoreUpdates(b, replaceInSortedScores)
+}
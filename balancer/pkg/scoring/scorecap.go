@@ -0,0 +1,43 @@
+package scoring
+
+import (
+	"sort"
+	"time"
+
+	"github.com/juice-shop/multi-juicer/balancer/pkg/bundle"
+)
+
+// applyScoreCap clamps the score of the team to the configured maxTeamScore and records when the team reached the cap. Does nothing if no cap is configured
+func applyScoreCap(config *bundle.Config, teamScore *TeamScore, scoreAdjustment int, challengesMap map[string](bundle.JuiceShopChallenge)) *TeamScore {
+	maxTeamScore := config.MaxTeamScore
+	if maxTeamScore <= 0 || teamScore.Score <= maxTeamScore {
+		return teamScore
+	}
+	teamScore.Score = maxTeamScore
+	teamScore.Capped = true
+
+	solvesInOrder := make([]ChallengeProgress, len(teamScore.Challenges))
+	copy(solvesInOrder, teamScore.Challenges)
+	sort.SliceStable(solvesInOrder, func(i, j int) bool {
+		return solvesInOrder[i].SolvedAt.Before(solvesInOrder[j].SolvedAt)
+	})
+	// teams reaching the cap purely through admin adjustments keep CappedAt unset
+	runningScore := scoreAdjustment
+	for _, solve := range solvesInOrder {
+		runningScore += challengePoints(config, challengesMap[solve.Key])
+		if runningScore >= maxTeamScore {
+			cappedAt := solve.SolvedAt
+			teamScore.CappedAt = &cappedAt
+			break
+		}
+	}
+	return teamScore
+}
+
+// tiebreakTime is the time used to order teams with the same score, the earlier the better
+func tiebreakTime(teamScore *TeamScore) time.Time {
+	if teamScore.CappedAt != nil {
+		return *teamScore.CappedAt
+	}
+	return getLatestChallengeSolve(teamScore.Challenges)
+}
@@ -3,8 +3,13 @@ package scoring
 import (
 	"context"
 	"encoding/json"
+	"math"
+	"runtime"
+	"slices"
 	"sort"
+	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/juice-shop/multi-juicer/balancer/pkg/bundle"
@@ -14,16 +19,44 @@ import (
 )
 
 type TeamScore struct {
-	Name              string              `json:"name"`
+	Name string `json:"name"`
+	// Namespace of the JuiceShop deployment of the team. Team names are only unique within a namespace, see TeamKey
+	Namespace         string              `json:"namespace"`
 	Score             int                 `json:"score"`
 	Position          int                 `json:"position"`
 	Challenges        []ChallengeProgress `json:"challenges"`
 	LastUpdate        time.Time           `json:"lastUpdate"`
 	InstanceReadiness bool                `json:"readiness"`
+	// ReadyChangedAt is the last time the InstanceReadiness flipped. Allows clients to tell readiness changes apart from score changes. Nil if the readiness hasn't changed since the balancer started
+	ReadyChangedAt *time.Time `json:"readyChangedAt,omitempty"`
+	// NewlySolved are the challenge keys solved since the previous update of the team, e.g. for activity feeds. Empty for the first known score of a team
+	NewlySolved []string `json:"newlySolved,omitempty"`
+	// Capped is set if the score of the team got clamped to the configured maxTeamScore
+	Capped bool `json:"capped,omitempty"`
+	// CappedAt is the solve time of the challenge with which the team reached the score cap. Used to order capped teams, as their later solves don't count anymore
+	CappedAt *time.Time `json:"cappedAt,omitempty"`
+	// ExcludedFromScoreboard is set for practice / staff teams, which are scored but left off the scoreboard and don't get a position
+	ExcludedFromScoreboard bool `json:"excludedFromScoreboard,omitempty"`
+	// LastRestoredAt is the last time the progress-watchdog re-applied the saved progress of the team to its instance, e.g. after a pod restart. Nil if it never got restored
+	LastRestoredAt *time.Time `json:"lastRestoredAt,omitempty"`
+	// UnknownChallenges are solved challenge keys of the team which aren't part of the challenges.json of the balancer. They don't count towards the score
+	UnknownChallenges []string `json:"-"`
+	// instanceCreatedAt is the creation time of the JuiceShop deployment, see holdScoreOfNewInstance
+	instanceCreatedAt time.Time
+}
+
+// TeamKey identifies a team across namespaces. All maps of scores are keyed by it, as team names are only unique within a namespace
+func TeamKey(namespace string, team string) string {
+	return namespace + "/" + team
+}
+
+// Key returns the TeamKey of the team
+func (t *TeamScore) Key() string {
+	return TeamKey(t.Namespace, t.Name)
 }
 
 func (t *TeamScore) EqualsIgnoringLastUpdate(other *TeamScore) bool {
-	if t.Name != other.Name {
+	if t.Name != other.Name || t.Namespace != other.Namespace {
 		return false
 	}
 	if t.Score != other.Score {
@@ -40,6 +73,15 @@ func (t *TeamScore) EqualsIgnoringLastUpdate(other *TeamScore) bool {
 			return false
 		}
 	}
+	if !slices.Equal(t.UnknownChallenges, other.UnknownChallenges) {
+		return false
+	}
+	if t.ExcludedFromScoreboard != other.ExcludedFromScoreboard {
+		return false
+	}
+	if (t.LastRestoredAt == nil) != (other.LastRestoredAt == nil) || (t.LastRestoredAt != nil && !t.LastRestoredAt.Equal(*other.LastRestoredAt)) {
+		return false
+	}
 	return t.InstanceReadiness == other.InstanceReadiness
 }
 
@@ -51,6 +93,9 @@ type ChallengeProgress struct {
 
 var cachedChallengesMap map[string](bundle.JuiceShopChallenge)
 
+// solveTimePrecision is the precision the solve times get truncated to when breaking ties between teams with the same score
+var solveTimePrecision = bundle.DefaultSolveTimePrecision
+
 type ScoringService struct {
 	bundle              *bundle.Bundle
 	currentScores       map[string]*TeamScore
@@ -58,8 +103,49 @@ type ScoringService struct {
 	currentScoresMutex  *sync.Mutex
 
 	lastUpdate time.Time
+	// fallback for EventStart if no event start is configured
+	startedAt time.Time
+	// closed and replaced every time lastUpdate advances, so that all goroutines waiting for updates get woken up at once
+	updateNotifier chan struct{}
 
 	challengesMap map[string](bundle.JuiceShopChallenge)
+
+	challengeStats             []ChallengeStats
+	challengeStatsCalculatedAt time.Time
+
+	// copy of the sorted scores at the time the public scoreboard got frozen, nil if it isn't frozen (yet)
+	frozenScoresSorted []*TeamScore
+	frozenAt           time.Time
+	// public scoreboard with the scores of not ready instances withheld, cached until the scores change again
+	withheldScoresSorted       []*TeamScore
+	withheldScoresCalculatedAt time.Time
+	// scoresLoaded is false until the scores got calculated or restored from a snapshot, so that a balancer started after the freeze time doesn't freeze an empty scoreboard
+	scoresLoaded bool
+	// restoredFromSnapshot is set if the initial scores came from a ScoreSnapshotStore
+	restoredFromSnapshot bool
+
+	// solves detected by the watcher for the activity feed, oldest first and bounded to maxSolveEvents
+	solveEvents      []SolveEvent
+	lastSolveEventId int64
+
+	// unix nanos of the last sign of life of the scoring watcher, see IsWatcherAlive
+	lastWatchActivity atomic.Int64
+
+	// counts the deployment events seen by the watcher, so that the admin instance stream can push the instance list without polling, see WaitForDeploymentChanges
+	deploymentChangesMutex   sync.Mutex
+	deploymentChanges        int64
+	deploymentChangeNotifier chan struct{}
+
+	// optional, nil if snapshots are disabled
+	snapshotStore ScoreSnapshotStore
+	lastSnapshot  time.Time
+}
+
+// ChallengeStats aggregates how often a challenge got solved across all teams
+type ChallengeStats struct {
+	Key           string     `json:"key"`
+	SolveCount    int        `json:"solveCount"`
+	FirstSolvedAt *time.Time `json:"firstSolvedAt"`
 }
 
 func NewScoringService(bundle *bundle.Bundle) *ScoringService {
@@ -72,50 +158,170 @@ func NewScoringServiceWithInitialScores(b *bundle.Bundle, initialScores map[stri
 	for _, challenge := range b.JuiceShopChallenges {
 		cachedChallengesMap[challenge.Key] = challenge
 	}
+	solveTimePrecision = b.SolveTimePrecision()
+	if len(cachedChallengesMap) == 0 {
+		b.Log.Printf("WARNING: No JuiceShop challenges loaded. Every team will have a score of 0 and the balancer will report itself as not ready. Check that the challenges.json file of the balancer is present and not empty.")
+	}
 
-	return &ScoringService{
-		bundle:              b,
-		currentScores:       initialScores,
-		currentScoresSorted: sortTeamsByScoreAndCalculatePositions(initialScores),
-		currentScoresMutex:  &sync.Mutex{},
+	scoringService := &ScoringService{
+		bundle:             b,
+		currentScores:      keyScoresByTeamKey(b.RuntimeEnvironment.Namespace, initialScores),
+		currentScoresMutex: &sync.Mutex{},
 
-		lastUpdate: time.Now(),
+		lastUpdate:     time.Now(),
+		startedAt:      time.Now(),
+		updateNotifier: make(chan struct{}),
+
+		deploymentChangeNotifier: make(chan struct{}),
 
 		challengesMap: cachedChallengesMap,
+
+		scoresLoaded: len(initialScores) > 0,
+	}
+	scoringService.currentScoresSorted = sortTeamsByScoreAndCalculatePositions(scoringService.currentScores)
+	// the watcher gets the full threshold to start up
+	scoringService.markWatcherActive()
+	return scoringService
+}
+
+// HasChallenges reports if the challenges required to calculate scores got loaded
+func (s *ScoringService) HasChallenges() bool {
+	return len(s.challengesMap) > 0
+}
+
+// keyScoresByTeamKey re-keys initial scores by their TeamKey. Scores without a namespace, e.g. from snapshots taken before scores were keyed by namespace, are assigned to the namespace of the balancer
+func keyScoresByTeamKey(namespace string, scores map[string]*TeamScore) map[string]*TeamScore {
+	keyedScores := make(map[string]*TeamScore, len(scores))
+	for _, score := range scores {
+		if score.Namespace == "" {
+			scoreCopy := *score
+			scoreCopy.Namespace = namespace
+			score = &scoreCopy
+		}
+		keyedScores[score.Key()] = score
 	}
+	return keyedScores
 }
 
+// GetScores returns a copy of the current scores of all teams keyed by their TeamKey, safe to be used while the scoring watcher keeps updating the scores.
+// The TeamScores themselves are never modified after being added to the scores, updates always replace them
 func (s *ScoringService) GetScores() map[string]*TeamScore {
-	return s.currentScores
+	s.currentScoresMutex.Lock()
+	defer s.currentScoresMutex.Unlock()
+
+	scores := make(map[string]*TeamScore, len(s.currentScores))
+	for team, score := range s.currentScores {
+		scores[team] = score
+	}
+	return scores
 }
 
+// GetScoreForTeam returns the score of a team in the namespace of the balancer
 func (s *ScoringService) GetScoreForTeam(team string) (*TeamScore, bool) {
-	score, ok := s.currentScores[team]
+	return s.GetScoreForTeamInNamespace(s.bundle.RuntimeEnvironment.Namespace, team)
+}
+
+func (s *ScoringService) GetScoreForTeamInNamespace(namespace string, team string) (*TeamScore, bool) {
+	s.currentScoresMutex.Lock()
+	defer s.currentScoresMutex.Unlock()
+
+	score, ok := s.currentScores[TeamKey(namespace, team)]
 	return score, ok
 }
 
+// GetTopScores returns a copy of the scores of all teams sorted by their position
 func (s *ScoringService) GetTopScores() []*TeamScore {
-	return s.currentScoresSorted
+	s.currentScoresMutex.Lock()
+	defer s.currentScoresMutex.Unlock()
+
+	scores := make([]*TeamScore, len(s.currentScoresSorted))
+	copy(scores, s.currentScoresSorted)
+	return scores
 }
 
-func (s *ScoringService) WaitForUpdatesNewerThan(ctx context.Context, lastSeenUpdate time.Time) []*TeamScore {
-	if s.lastUpdate.After(lastSeenUpdate) {
-		// the last update was after the last seen update, so we can return the current scores without waiting
-		return s.currentScoresSorted
+// GetTopScoresWithLastUpdate returns the sorted scores together with the time they last changed, e.g. to derive cache validators from it
+func (s *ScoringService) GetTopScoresWithLastUpdate() ([]*TeamScore, time.Time) {
+	s.currentScoresMutex.Lock()
+	defer s.currentScoresMutex.Unlock()
+
+	scores := make([]*TeamScore, len(s.currentScoresSorted))
+	copy(scores, s.currentScoresSorted)
+	return scores, s.lastUpdate
+}
+
+// GetChallengeStats returns the solve count and first solve of every challenge as shown on the public scoreboard, so frozen and withheld scores aren't revealed through them.
+// The stats are cached until the public scoreboard changes again
+func (s *ScoringService) GetChallengeStats() []ChallengeStats {
+	s.currentScoresMutex.Lock()
+	defer s.currentScoresMutex.Unlock()
+
+	s.freezeScoresIfDue()
+	scores, lastUpdate, _ := s.publicScores()
+	if s.challengeStats != nil && s.challengeStatsCalculatedAt.Equal(lastUpdate) {
+		return s.challengeStats
 	}
 
-	const maxWaitTime = 25 * time.Second
+	solveCounts := map[string]int{}
+	firstSolves := map[string]time.Time{}
+	for _, teamScore := range scores {
+		for _, challenge := range teamScore.Challenges {
+			solveCounts[challenge.Key]++
+			if firstSolve, ok := firstSolves[challenge.Key]; !ok || challenge.SolvedAt.Before(firstSolve) {
+				firstSolves[challenge.Key] = challenge.SolvedAt
+			}
+		}
+	}
+
+	stats := make([]ChallengeStats, 0, len(s.bundle.JuiceShopChallenges))
+	for _, challenge := range s.bundle.JuiceShopChallenges {
+		challengeStats := ChallengeStats{
+			Key:        challenge.Key,
+			SolveCount: solveCounts[challenge.Key],
+		}
+		if firstSolve, ok := firstSolves[challenge.Key]; ok {
+			challengeStats.FirstSolvedAt = &firstSolve
+		}
+		stats = append(stats, challengeStats)
+	}
+
+	s.challengeStats = stats
+	s.challengeStatsCalculatedAt = lastUpdate
+	return stats
+}
+
+const maxWaitTime = 25 * time.Second
+
+// modifications of JuiceShop deployments are collected for this long before recalculating the scores, so that bursts of solves only re-sort the scoreboard once
+const scoreUpdateDebounce = 200 * time.Millisecond
+
+// how often the scores get persisted to the snapshot store (if configured)
+const snapshotInterval = 30 * time.Second
+
+// markScoresUpdated advances lastUpdate and wakes up all goroutines currently waiting for score updates. Must be called while holding the currentScoresMutex
+func (s *ScoringService) markScoresUpdated() {
+	s.lastUpdate = time.Now()
+	close(s.updateNotifier)
+	s.updateNotifier = make(chan struct{})
+}
+
+func (s *ScoringService) WaitForUpdatesNewerThan(ctx context.Context, lastSeenUpdate time.Time) []*TeamScore {
 	timeout := time.NewTimer(maxWaitTime)
-	ticker := time.NewTicker(50 * time.Millisecond)
 	defer timeout.Stop()
-	defer ticker.Stop()
 
 	for {
+		s.currentScoresMutex.Lock()
+		if s.lastUpdate.After(lastSeenUpdate) {
+			// the last update was after the last seen update, so we can return the current scores without waiting
+			scores := s.currentScoresSorted
+			s.currentScoresMutex.Unlock()
+			return scores
+		}
+		updated := s.updateNotifier
+		s.currentScoresMutex.Unlock()
+
 		select {
-		case <-ticker.C:
-			if s.lastUpdate.After(lastSeenUpdate) {
-				return s.currentScoresSorted
-			}
+		case <-updated:
+			// scores got updated, loop around to check if the update is newer than the last seen one
 		case <-timeout.C:
 			// Timeout was reached
 			return nil
@@ -127,28 +333,22 @@ func (s *ScoringService) WaitForUpdatesNewerThan(ctx context.Context, lastSeenUp
 }
 
 func (s *ScoringService) WaitForTeamUpdatesNewerThan(ctx context.Context, team string, lastSeenUpdate time.Time) *TeamScore {
-	if score, ok := s.currentScores[team]; ok {
-		if score.LastUpdate.After(lastSeenUpdate) {
-			// the last update was after the last seen update, so we can return the current scores without waiting
-			return score
-		}
-	}
-
-	const maxWaitTime = 25 * time.Second
 	timeout := time.NewTimer(maxWaitTime)
-	ticker := time.NewTicker(50 * time.Millisecond)
 	defer timeout.Stop()
-	defer ticker.Stop()
 
 	for {
+		s.currentScoresMutex.Lock()
+		if score, ok := s.currentScores[TeamKey(s.bundle.RuntimeEnvironment.Namespace, team)]; ok && score.LastUpdate.After(lastSeenUpdate) {
+			// the last update was after the last seen update, so we can return the current scores without waiting
+			s.currentScoresMutex.Unlock()
+			return score
+		}
+		updated := s.updateNotifier
+		s.currentScoresMutex.Unlock()
+
 		select {
-		case <-ticker.C:
-			if score, ok := s.currentScores[team]; ok {
-				if score.LastUpdate.After(lastSeenUpdate) {
-					// the last update was after the last seen update, so we can return the current scores without waiting
-					return score
-				}
-			}
+		case <-updated:
+			// scores got updated, loop around to check if the team was part of the update
 		case <-timeout.C:
 			// Timeout was reached
 			return nil
@@ -160,59 +360,90 @@ func (s *ScoringService) WaitForTeamUpdatesNewerThan(ctx context.Context, team s
 }
 
 func (s *ScoringService) StartingScoringWorker(ctx context.Context) {
+	backoff := &watchRestartBackoff{maxDelay: s.bundle.Config.WatchRestartMaxBackoffDuration()}
 	for {
 		select {
 		case <-ctx.Done():
 			s.bundle.Log.Printf("MultiJuicer context canceled. Exiting the scoring watcher.")
 			return
 		default:
-			s.startScoringWatcher(ctx)
+		}
+
+		watchStart := time.Now()
+		s.startScoringWatcher(ctx)
+		delay := backoff.next(time.Since(watchStart))
+
+		select {
+		case <-ctx.Done():
+			s.bundle.Log.Printf("MultiJuicer context canceled. Exiting the scoring watcher.")
+			return
+		case <-time.After(delay):
 		}
 	}
 }
 
 func (s *ScoringService) startScoringWatcher(ctx context.Context) {
 	watcher, err := s.bundle.ClientSet.AppsV1().Deployments(s.bundle.RuntimeEnvironment.Namespace).Watch(ctx, metav1.ListOptions{
-		LabelSelector: "app.kubernetes.io/name=juice-shop,app.kubernetes.io/part-of=multi-juicer",
+		LabelSelector: s.bundle.JuiceShopInstanceLabelSelector(),
+		// bookmarks are sent periodically even if no deployment changes, proving that the watch is still alive
+		AllowWatchBookmarks: true,
 	})
 
 	if err != nil {
 		s.bundle.Log.Printf("Failed to start the watcher for JuiceShop deployments: %v", err)
-		panic(err)
+		return
 	}
 	defer watcher.Stop()
+	s.markWatcherActive()
+
+	snapshotTicker := time.NewTicker(snapshotInterval)
+	defer snapshotTicker.Stop()
+
+	pendingDeployments := newPendingDeploymentUpdates()
+	// nil while there are no pending deployments, so that the select below never fires for it
+	var debounceTimer <-chan time.Time
 
 	for {
 		select {
+		case <-snapshotTicker.C:
+			s.saveSnapshotIfChanged(ctx)
+		case <-debounceTimer:
+			debounceTimer = nil
+			s.applyDeploymentUpdates(pendingDeployments.take())
 		case event, ok := <-watcher.ResultChan():
 			if !ok {
+				s.applyDeploymentUpdates(pendingDeployments.take())
 				s.bundle.Log.Printf("Watcher for JuiceShop deployments has been closed. Restarting the watcher.")
 				return
 			}
+			s.markWatcherActive()
+			if event.Type == watch.Added || event.Type == watch.Modified || event.Type == watch.Deleted {
+				s.notifyDeploymentChange()
+			}
 			switch event.Type {
 			case watch.Added, watch.Modified:
 				deployment := event.Object.(*appsv1.Deployment)
-				score := calculateScore(s.bundle, deployment, cachedChallengesMap)
-
-				if currentTeamScore, ok := s.currentScores[score.Name]; ok {
-					if currentTeamScore.EqualsIgnoringLastUpdate(score) {
-						// No need to update, if the score hasn't changed
-						continue
-					}
+				if pendingDeployments.add(deployment) {
+					debounceTimer = nil
+					s.applyDeploymentUpdates(pendingDeployments.take())
+				} else if debounceTimer == nil {
+					debounceTimer = time.After(scoreUpdateDebounce)
 				}
-
-				s.currentScoresMutex.Lock()
-				s.currentScores[score.Name] = score
-				s.currentScoresSorted = sortTeamsByScoreAndCalculatePositions(s.currentScores)
-				s.lastUpdate = time.Now()
-				s.currentScoresMutex.Unlock()
 			case watch.Deleted:
 				deployment := event.Object.(*appsv1.Deployment)
-				team := deployment.Labels["team"]
+				teamKey := TeamKey(deployment.Namespace, deployment.Labels["team"])
+				pendingDeployments.remove(teamKey)
+				if s.bundle.IsReadOnly() {
+					// the scoreboard is frozen, it gets recalculated once the read-only mode gets disabled
+					continue
+				}
 				s.currentScoresMutex.Lock()
-				delete(s.currentScores, team)
-				s.currentScoresSorted = sortTeamsByScoreAndCalculatePositions(s.currentScores)
-				s.lastUpdate = time.Now()
+				s.freezeScoresIfDue()
+				if currentTeamScore, ok := s.currentScores[teamKey]; ok {
+					delete(s.currentScores, teamKey)
+					s.currentScoresSorted = replaceInSortedScores(s.currentScoresSorted, s.currentScores, currentTeamScore, nil)
+					s.markScoresUpdated()
+				}
 				s.currentScoresMutex.Unlock()
 			default:
 			}
@@ -223,6 +454,56 @@ func (s *ScoringService) startScoringWatcher(ctx context.Context) {
 	}
 }
 
+// applyDeploymentUpdates recalculates the scores of the modified deployments and moves them to their new place on the scoreboard
+// Updates are dropped while the balancer is read-only, to freeze the scoreboard
+func (s *ScoringService) applyDeploymentUpdates(deployments map[string]*appsv1.Deployment) {
+	if len(deployments) == 0 || s.bundle.IsReadOnly() {
+		return
+	}
+	start := time.Now()
+
+	scores := make([]*TeamScore, 0, len(deployments))
+	for _, deployment := range deployments {
+		score := calculateScore(s.bundle.ScoringRules(), s.bundle.Log, deployment, cachedChallengesMap)
+		countUnknownChallenges(score)
+		scores = append(scores, score)
+	}
+
+	s.currentScoresMutex.Lock()
+	defer s.currentScoresMutex.Unlock()
+	defer func() { observeRecompute("incremental", start, len(s.currentScores)) }()
+	s.freezeScoresIfDue()
+
+	changed := []*TeamScore{}
+	joined := []*TeamScore{}
+	now := time.Now()
+	for _, score := range scores {
+		currentTeamScore, ok := s.currentScores[score.Key()]
+		if ok && holdScoreOfNewInstance(s.bundle.Config, currentTeamScore, score, now) == currentTeamScore {
+			s.bundle.Log.Printf("Holding team '%s' at its last known score as its instance was just created and hasn't restored its progress yet", score.Name)
+			continue
+		}
+		if ok && currentTeamScore.EqualsIgnoringLastUpdate(score) {
+			// No need to update, if the score hasn't changed
+			continue
+		}
+		score.ReadyChangedAt = readyChangedAt(currentTeamScore, score)
+		score.NewlySolved = newlySolvedChallenges(currentTeamScore, score)
+		s.currentScores[score.Key()] = score
+		s.currentScoresSorted = replaceInSortedScores(s.currentScoresSorted, s.currentScores, currentTeamScore, score)
+		changed = append(changed, score)
+		if !ok && s.scoresLoaded {
+			joined = append(joined, score)
+		}
+	}
+	if len(changed) == 0 {
+		return
+	}
+	s.recordTeamJoinedEvents(joined)
+	s.recordSolveEvents(changed)
+	s.markScoresUpdated()
+}
+
 func (s *ScoringService) CalculateAndCacheScoreBoard(context context.Context) error {
 	// Get all JuiceShop instances
 	juiceShops, err := getDeployments(context, s.bundle)
@@ -230,21 +511,68 @@ func (s *ScoringService) CalculateAndCacheScoreBoard(context context.Context) er
 		return err
 	}
 
-	// Calculate the new scores
+	start := time.Now()
+	// Calculate the new scores. Replaces all scores, so that teams which got deleted in the meantime (e.g. teams restored from a snapshot) are dropped
+	newScores := calculateScores(s.bundle, juiceShops.Items, s.challengesMap)
+	previousScores := s.GetScores()
+	for teamKey, score := range newScores {
+		newScores[teamKey] = holdScoreOfNewInstance(s.bundle.Config, previousScores[teamKey], score, start)
+	}
+	// sorting doesn't depend on the previous scores, so it's done before taking the lock to keep readers unblocked
+	newScoresSorted := sortTeamsByScoreAndCalculatePositions(newScores)
+
 	s.currentScoresMutex.Lock()
-	for _, juiceShop := range juiceShops.Items {
-		score := calculateScore(s.bundle, &juiceShop, s.challengesMap)
-		s.currentScores[score.Name] = score
+	s.freezeScoresIfDue()
+	for teamKey, score := range newScores {
+		score.ReadyChangedAt = readyChangedAt(s.currentScores[teamKey], score)
+		score.NewlySolved = newlySolvedChallenges(s.currentScores[teamKey], score)
 	}
-	s.currentScoresSorted = sortTeamsByScoreAndCalculatePositions(s.currentScores)
+	s.currentScores = newScores
+	s.currentScoresSorted = newScoresSorted
+	s.scoresLoaded = true
+	s.markScoresUpdated()
 	s.currentScoresMutex.Unlock()
+	s.markWatcherActive()
+	observeRecompute("full", start, len(newScores))
 
 	return nil
 }
 
+// calculateScores calculates the scores of all deployments concurrently, as calculateScore only depends on its inputs
+func calculateScores(bundle *bundle.Bundle, deployments []appsv1.Deployment, challengesMap map[string](bundle.JuiceShopChallenge)) map[string]*TeamScore {
+	scores := make([]*TeamScore, len(deployments))
+	// loaded once, so that all teams are scored with the same rules even if they get updated concurrently
+	scoringRules := bundle.ScoringRules()
+
+	workerCount := min(runtime.GOMAXPROCS(0), len(deployments))
+	indices := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < workerCount; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indices {
+				scores[i] = calculateScore(scoringRules, bundle.Log, &deployments[i], challengesMap)
+			}
+		}()
+	}
+	for i := range deployments {
+		indices <- i
+	}
+	close(indices)
+	wg.Wait()
+
+	scoresByTeam := make(map[string]*TeamScore, len(scores))
+	for _, score := range scores {
+		countUnknownChallenges(score)
+		scoresByTeam[score.Key()] = score
+	}
+	return scoresByTeam
+}
+
 func getDeployments(context context.Context, bundle *bundle.Bundle) (*appsv1.DeploymentList, error) {
 	deployments, err := bundle.ClientSet.AppsV1().Deployments(bundle.RuntimeEnvironment.Namespace).List(context, metav1.ListOptions{
-		LabelSelector: "app.kubernetes.io/name=juice-shop,app.kubernetes.io/part-of=multi-juicer",
+		LabelSelector: bundle.JuiceShopInstanceLabelSelector(),
 	})
 	if err != nil {
 		return nil, err
@@ -252,52 +580,169 @@ func getDeployments(context context.Context, bundle *bundle.Bundle) (*appsv1.Dep
 	return deployments, nil
 }
 
-func calculateScore(bundle *bundle.Bundle, teamDeployment *appsv1.Deployment, challengesMap map[string](bundle.JuiceShopChallenge)) *TeamScore {
+// ExcludeFromScoreboardAnnotation marks practice / staff teams which shouldn't show up on the scoreboard, if set to "true"
+const ExcludeFromScoreboardAnnotation = "multi-juicer.owasp-juice.shop/excludeFromScoreboard"
+
+// LastRestoredAtAnnotation is set by the progress-watchdog (as RFC3339 time) whenever it re-applied the saved progress of a team to its instance
+const LastRestoredAtAnnotation = "multi-juicer.owasp-juice.shop/lastRestoredAt"
+
+// scoreLogger is the part of the bundle logger needed to report broken annotations while calculating scores
+type scoreLogger interface {
+	Printf(format string, v ...any)
+}
+
+// calculateScore calculates the score of the team from the annotations of its deployment. Only depends on its arguments, so that the scoring rules can be tested without a bundle or a kubernetes client
+func calculateScore(config *bundle.Config, log scoreLogger, teamDeployment *appsv1.Deployment, challengesMap map[string](bundle.JuiceShopChallenge)) *TeamScore {
+	score := calculateChallengeScore(config, log, teamDeployment, challengesMap)
+	score.Namespace = teamDeployment.Namespace
+	score.instanceCreatedAt = teamDeployment.CreationTimestamp.Time
+	score.ExcludedFromScoreboard = teamDeployment.Annotations[ExcludeFromScoreboardAnnotation] == "true"
+	if lastRestoredAt := teamDeployment.Annotations[LastRestoredAtAnnotation]; lastRestoredAt != "" {
+		if restoredAt, err := time.Parse(time.RFC3339, lastRestoredAt); err == nil {
+			score.LastRestoredAt = &restoredAt
+		} else {
+			log.Printf("Ignoring invalid lastRestoredAt annotation of team '%s': %v", score.Name, err)
+		}
+	}
+	return score
+}
+
+func calculateChallengeScore(config *bundle.Config, log scoreLogger, teamDeployment *appsv1.Deployment, challengesMap map[string](bundle.JuiceShopChallenge)) *TeamScore {
 	solvedChallengesString := teamDeployment.Annotations["multi-juicer.owasp-juice.shop/challenges"]
 	team := teamDeployment.Labels["team"]
+	scoreAdjustment := getScoreAdjustment(log, teamDeployment)
 	if solvedChallengesString == "" {
-		return &TeamScore{
+		return applyScoreCap(config, &TeamScore{
 			Name:              team,
-			Score:             0,
+			Score:             scoreAdjustment,
 			Challenges:        []ChallengeProgress{},
-			InstanceReadiness: teamDeployment.Status.ReadyReplicas > 0,
+			InstanceReadiness: config.IsInstanceReady(teamDeployment),
 			LastUpdate:        time.Now(),
-		}
+		}, scoreAdjustment, challengesMap)
 	}
 
 	solvedChallenges := []ChallengeProgress{}
 	err := json.Unmarshal([]byte(solvedChallengesString), &solvedChallenges)
 
 	if err != nil {
-		bundle.Log.Printf("JuiceShop deployment '%s' has an invalid 'multi-juicer.owasp-juice.shop/challenges' annotation. Assuming 0 solved challenges for it as the score can't be calculated.", team)
-		return &TeamScore{
+		log.Printf("JuiceShop deployment '%s' has an invalid 'multi-juicer.owasp-juice.shop/challenges' annotation. Assuming 0 solved challenges for it as the score can't be calculated.", team)
+		return applyScoreCap(config, &TeamScore{
 			Name:              team,
-			Score:             0,
+			Score:             scoreAdjustment,
 			Challenges:        []ChallengeProgress{},
-			InstanceReadiness: teamDeployment.Status.ReadyReplicas > 0,
+			InstanceReadiness: config.IsInstanceReady(teamDeployment),
 			LastUpdate:        time.Now(),
-		}
+		}, scoreAdjustment, challengesMap)
 	}
 
-	score := 0
+	score := scoreAdjustment
 	solvedChallengeNames := []ChallengeProgress{}
+	var unknownChallenges []string
 	for _, challengeSolved := range solvedChallenges {
 		challenge, ok := challengesMap[challengeSolved.Key]
 		if !ok {
-			bundle.Log.Printf("JuiceShop deployment '%s' has a solved challenge '%s' that is not in the challenges map. The used JuiceShop version might be incompatible with this MultiJuicer version.", team, challengeSolved.Key)
+			log.Printf("JuiceShop deployment '%s' has a solved challenge '%s' that is not in the challenges map. The used JuiceShop version might be incompatible with this MultiJuicer version.", team, challengeSolved.Key)
+			unknownChallenges = append(unknownChallenges, challengeSolved.Key)
 			continue
 		}
-		score += challenge.Difficulty * 10
+		score += challengePoints(config, challenge)
 		solvedChallengeNames = append(solvedChallengeNames, challengeSolved)
 	}
+	score += categoryCompletionBonus(config, solvedChallengeNames, challengesMap)
 
-	return &TeamScore{
+	return applyScoreCap(config, &TeamScore{
 		Name:              team,
 		Score:             score,
 		Challenges:        solvedChallengeNames,
-		InstanceReadiness: teamDeployment.Status.ReadyReplicas > 0,
+		UnknownChallenges: unknownChallenges,
+		InstanceReadiness: config.IsInstanceReady(teamDeployment),
 		LastUpdate:        time.Now(),
+	}, scoreAdjustment, challengesMap)
+}
+
+// getScoreAdjustment returns the points manually granted (or deducted) by an admin via the scoreAdjustment annotation
+func getScoreAdjustment(log scoreLogger, teamDeployment *appsv1.Deployment) int {
+	adjustmentString, ok := teamDeployment.Annotations["multi-juicer.owasp-juice.shop/scoreAdjustment"]
+	if !ok || adjustmentString == "" {
+		return 0
+	}
+	adjustment, err := strconv.Atoi(adjustmentString)
+	if err != nil {
+		log.Printf("JuiceShop deployment '%s' has an invalid 'multi-juicer.owasp-juice.shop/scoreAdjustment' annotation. Ignoring the adjustment.", teamDeployment.Labels["team"])
+		return 0
+	}
+	return adjustment
+}
+
+// readyChangedAt derives when the readiness of the team last changed by comparing the new score with the previous one
+func readyChangedAt(previous *TeamScore, current *TeamScore) *time.Time {
+	if previous == nil {
+		return nil
+	}
+	if previous.InstanceReadiness != current.InstanceReadiness {
+		changedAt := current.LastUpdate
+		return &changedAt
+	}
+	return previous.ReadyChangedAt
+}
+
+// newlySolvedChallenges returns the keys of the challenges the team solved since the previous score. Returns nil if there is no previous score, as the solves can't be told apart from ones made before the balancer started
+func newlySolvedChallenges(previous *TeamScore, current *TeamScore) []string {
+	if previous == nil {
+		return nil
+	}
+	previouslySolved := make(map[string]bool, len(previous.Challenges))
+	for _, challenge := range previous.Challenges {
+		previouslySolved[challenge.Key] = true
+	}
+	var newlySolved []string
+	for _, challenge := range current.Challenges {
+		if !previouslySolved[challenge.Key] {
+			newlySolved = append(newlySolved, challenge.Key)
+		}
 	}
+	return newlySolved
+}
+
+// ChallengePoints returns the points a team gets for solving the challenge. Configured point overrides take precedence over the difficulty based points
+func ChallengePoints(bundle *bundle.Bundle, challenge bundle.JuiceShopChallenge) int {
+	return challengePoints(bundle.ScoringRules(), challenge)
+}
+
+func challengePoints(config *bundle.Config, challenge bundle.JuiceShopChallenge) int {
+	if points, ok := config.ChallengePointOverrides[challenge.Key]; ok {
+		return points
+	}
+	points := challenge.Difficulty * 10
+	if difficultyPoints, ok := config.DifficultyPoints[challenge.Difficulty]; ok {
+		points = difficultyPoints
+	}
+	if multiplier, ok := config.CategoryMultipliers[challenge.Category]; ok {
+		// rounded per challenge (half away from zero), so that the score of a team is always the sum of the points shown for its challenges
+		return int(math.Round(float64(points) * multiplier))
+	}
+	return points
+}
+
+// categoryCompletionBonus returns the bonus points of all categories in which the team solved every challenge
+func categoryCompletionBonus(config *bundle.Config, solvedChallenges []ChallengeProgress, challengesMap map[string](bundle.JuiceShopChallenge)) int {
+	if len(config.CategoryCompletionBonus) == 0 {
+		return 0
+	}
+	unsolvedByCategory := map[string]int{}
+	for _, challenge := range challengesMap {
+		unsolvedByCategory[challenge.Category]++
+	}
+	for _, solved := range solvedChallenges {
+		unsolvedByCategory[challengesMap[solved.Key].Category]--
+	}
+	bonus := 0
+	for category, categoryBonus := range config.CategoryCompletionBonus {
+		if total, ok := unsolvedByCategory[category]; ok && total == 0 {
+			bonus += categoryBonus
+		}
+	}
+	return bonus
 }
 
 func getLatestChallengeSolve(challenges []ChallengeProgress) time.Time {
@@ -310,25 +755,41 @@ func getLatestChallengeSolve(challenges []ChallengeProgress) time.Time {
 	return maxTime
 }
 
-func sortTeamsByScoreAndCalculatePositions(teamScores map[string]*TeamScore) []*TeamScore {
-	sortedTeamScores := make([]*TeamScore, len(teamScores))
+// teamScoreLess orders teams by their score. Teams with the same score are ordered by who reached the score first, then by name and namespace
+func teamScoreLess(a *TeamScore, b *TeamScore) bool {
+	if a.Score == b.Score {
+		// truncated, so that teams solving within the same second keep a stable order independent of sub-second differences
+		aTime := tiebreakTime(a).Truncate(solveTimePrecision)
+		bTime := tiebreakTime(b).Truncate(solveTimePrecision)
+		if aTime.Equal(bTime) {
+			if a.Name == b.Name {
+				return a.Namespace < b.Namespace
+			}
+			return a.Name < b.Name
+		}
+		return aTime.Before(bTime)
+	}
+	return a.Score > b.Score
+}
 
-	i := 0
-	for _, teamScore := range teamScores {
-		sortedTeamScores[i] = teamScore
-		i++
+// sortTeamsByScoreAndCalculatePositions replaces all scores in the map (keyed by TeamKey) with copies carrying their new position.
+// The previous TeamScores aren't modified, as they might still be read by requests which got them before the update
+func sortTeamsByScoreAndCalculatePositions(teamScores map[string]*TeamScore) []*TeamScore {
+	sortedTeamScores := make([]*TeamScore, 0, len(teamScores))
+
+	for team, teamScore := range teamScores {
+		teamScoreCopy := *teamScore
+		teamScores[team] = &teamScoreCopy
+		if teamScoreCopy.ExcludedFromScoreboard {
+			// kept in the scores map for the admin routes, but without a position
+			teamScoreCopy.Position = 0
+			continue
+		}
+		sortedTeamScores = append(sortedTeamScores, &teamScoreCopy)
 	}
 
 	sort.Slice(sortedTeamScores, func(i, j int) bool {
-		if sortedTeamScores[i].Score == sortedTeamScores[j].Score {
-			iTime := getLatestChallengeSolve(sortedTeamScores[i].Challenges)
-			jTime := getLatestChallengeSolve(sortedTeamScores[j].Challenges)
-			if iTime == jTime {
-				return sortedTeamScores[i].Name < sortedTeamScores[j].Name
-			}
-			return iTime.Before(jTime)
-		}
-		return sortedTeamScores[i].Score > sortedTeamScores[j].Score
+		return teamScoreLess(sortedTeamScores[i], sortedTeamScores[j])
 	})
 
 	// set the position of each team, teams with the same score have the same position
@@ -6,10 +6,15 @@ import (
 	"testing"
 	"time"
 
+	bu "github.com/juice-shop/multi-juicer/balancer/pkg/bundle"
 	"github.com/juice-shop/multi-juicer/balancer/pkg/testutil"
+	"github.com/prometheus/client_golang/prometheus"
+	promtestutil "github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
 	"github.com/stretchr/testify/assert"
 	appsv1 "k8s.io/api/apps/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/kubernetes/fake"
 	testcore "k8s.io/client-go/testing"
@@ -68,9 +73,10 @@ func TestScoreingService(t *testing.T) {
 		assert.Nil(t, err)
 		assert.Equal(t, []*TeamScore{
 			{
-				Name:     "foobar",
-				Score:    50,
-				Position: 1,
+				Name:      "foobar",
+				Namespace: "test-namespace",
+				Score:     50,
+				Position:  1,
 				Challenges: []ChallengeProgress{
 					{
 						Key:      "scoreBoardChallenge",
@@ -85,6 +91,7 @@ func TestScoreingService(t *testing.T) {
 			},
 			{
 				Name:              "barfoo",
+				Namespace:         "test-namespace",
 				Score:             0,
 				Position:          2,
 				Challenges:        []ChallengeProgress{},
@@ -111,9 +118,10 @@ func TestScoreingService(t *testing.T) {
 		assert.Nil(t, err)
 		assert.Equal(t, []*TeamScore{
 			{
-				Name:     "foobar",
-				Score:    50,
-				Position: 1,
+				Name:      "foobar",
+				Namespace: "test-namespace",
+				Score:     50,
+				Position:  1,
 				Challenges: []ChallengeProgress{
 					{
 						Key:      "scoreBoardChallenge",
@@ -127,9 +135,10 @@ func TestScoreingService(t *testing.T) {
 				InstanceReadiness: true,
 			},
 			{
-				Name:     "barfoo-1",
-				Score:    10,
-				Position: 2,
+				Name:      "barfoo-1",
+				Namespace: "test-namespace",
+				Score:     10,
+				Position:  2,
 				Challenges: []ChallengeProgress{
 					{
 						Key:      "scoreBoardChallenge",
@@ -139,9 +148,10 @@ func TestScoreingService(t *testing.T) {
 				InstanceReadiness: true,
 			},
 			{
-				Name:     "barfoo-2",
-				Score:    10,
-				Position: 2,
+				Name:      "barfoo-2",
+				Namespace: "test-namespace",
+				Score:     10,
+				Position:  2,
 				Challenges: []ChallengeProgress{
 					{
 						Key:      "scoreBoardChallenge",
@@ -152,6 +162,7 @@ func TestScoreingService(t *testing.T) {
 			},
 			{
 				Name:              "last",
+				Namespace:         "test-namespace",
 				Score:             0,
 				Position:          4, // should be 4 not 3 as there are two teams with the same score on position 2
 				Challenges:        []ChallengeProgress{},
@@ -176,25 +187,202 @@ func TestScoreingService(t *testing.T) {
 		assert.Nil(t, err)
 		assert.Equal(t, []*TeamScore{
 			{
-				Name:     "foobar",
-				Score:    40,
-				Position: 1,
+				Name:      "foobar",
+				Namespace: "test-namespace",
+				Score:     40,
+				Position:  1,
 				Challenges: []ChallengeProgress{
 					{
 						Key:      "nullByteChallenge",
 						SolvedAt: novemberFirst,
 					},
 				},
+				UnknownChallenges: []string{"unkown-challenge-key"},
 				InstanceReadiness: true,
 			},
 			{
 				Name:              "barfoo",
+				Namespace:         "test-namespace",
 				Score:             0,
 				Position:          2,
 				Challenges:        []ChallengeProgress{},
 				InstanceReadiness: true,
 			},
 		}, withoutTimestamps(scores))
+		assert.Equal(t, []UnknownChallenge{{Key: "unkown-challenge-key", Teams: []string{"foobar"}}}, scoringService.GetUnknownChallenges())
+	})
+
+	t.Run("challenge point overrides take precedence over the difficulty based points", func(t *testing.T) {
+		clientset := fake.NewSimpleClientset(
+			createTeam("foobar", `[{"key":"nullByteChallenge","solvedAt":"2024-11-01T19:55:48.211Z"}]`, "1"),
+			createTeam("barfoo", `[{"key":"scoreBoardChallenge","solvedAt":"2024-11-01T19:55:48.211Z"}]`, "1"),
+		)
+		bundle := testutil.NewTestBundleWithCustomFakeClient(clientset)
+		bundle.Config.ChallengePointOverrides = map[string]int{
+			"nullByteChallenge": 0,
+		}
+
+		scoringService := NewScoringService(bundle)
+		err := scoringService.CalculateAndCacheScoreBoard(context.Background())
+		assert.Nil(t, err)
+
+		scores := scoringService.GetTopScores()
+
+		assert.Equal(t, []*TeamScore{
+			{
+				Name:      "barfoo",
+				Namespace: "test-namespace",
+				Score:     10,
+				Position:  1,
+				Challenges: []ChallengeProgress{
+					{
+						Key:      "scoreBoardChallenge",
+						SolvedAt: novemberFirst,
+					},
+				},
+				InstanceReadiness: true,
+			},
+			{
+				Name:      "foobar",
+				Namespace: "test-namespace",
+				Score:     0,
+				Position:  2,
+				Challenges: []ChallengeProgress{
+					{
+						Key:      "nullByteChallenge",
+						SolvedAt: novemberFirst,
+					},
+				},
+				InstanceReadiness: true,
+			},
+		}, withoutTimestamps(scores))
+	})
+
+	t.Run("caps scores and orders capped teams by the time they reached the cap", func(t *testing.T) {
+		clientset := fake.NewSimpleClientset(
+			createTeam("foobar", `[{"key":"scoreBoardChallenge","solvedAt":"2024-11-01T20:10:00Z"},{"key":"nullByteChallenge","solvedAt":"2024-11-01T19:00:00Z"}]`, "2"),
+			createTeam("barfoo", `[{"key":"scoreBoardChallenge","solvedAt":"2024-11-01T18:00:00Z"},{"key":"nullByteChallenge","solvedAt":"2024-11-01T20:00:00Z"}]`, "2"),
+			createTeam("bazbar", `[{"key":"scoreBoardChallenge","solvedAt":"2024-11-01T18:00:00Z"}]`, "1"),
+		)
+		bundle := testutil.NewTestBundleWithCustomFakeClient(clientset)
+		bundle.Config.MaxTeamScore = 40
+
+		scoringService := NewScoringService(bundle)
+		err := scoringService.CalculateAndCacheScoreBoard(context.Background())
+		assert.Nil(t, err)
+
+		scores := scoringService.GetTopScores()
+		assert.Len(t, scores, 3)
+
+		// foobar reached the cap at 19:00 with the null byte challenge, barfoo only at 20:00
+		assert.Equal(t, "foobar", scores[0].Name)
+		assert.Equal(t, 40, scores[0].Score)
+		assert.True(t, scores[0].Capped)
+		assert.Equal(t, time.Date(2024, 11, 1, 19, 0, 0, 0, time.UTC), *scores[0].CappedAt)
+		assert.Equal(t, 1, scores[0].Position)
+
+		assert.Equal(t, "barfoo", scores[1].Name)
+		assert.Equal(t, 40, scores[1].Score)
+		assert.True(t, scores[1].Capped)
+		// teams with the same score share their position, the cap time only decides the order
+		assert.Equal(t, 1, scores[1].Position)
+
+		assert.Equal(t, "bazbar", scores[2].Name)
+		assert.Equal(t, 10, scores[2].Score)
+		assert.False(t, scores[2].Capped)
+		assert.Nil(t, scores[2].CappedAt)
+	})
+
+	t.Run("category multipliers change the score and ordering of teams", func(t *testing.T) {
+		clientset := fake.NewSimpleClientset(
+			createTeam("foobar", `[{"key":"nullByteChallenge","solvedAt":"2024-11-01T19:55:48.211Z"}]`, "1"),
+			createTeam("barfoo", `[{"key":"scoreBoardChallenge","solvedAt":"2024-11-01T19:55:48.211Z"}]`, "1"),
+		)
+		bundle := testutil.NewTestBundleWithCustomFakeClient(clientset)
+		bundle.JuiceShopChallenges[0].Category = "Miscellaneous"
+		bundle.JuiceShopChallenges[1].Category = "Injection"
+		bundle.Config.CategoryMultipliers = map[string]float64{
+			"Miscellaneous": 5,
+		}
+
+		scoringService := NewScoringService(bundle)
+		err := scoringService.CalculateAndCacheScoreBoard(context.Background())
+		assert.Nil(t, err)
+
+		scores := scoringService.GetTopScores()
+		assert.Equal(t, "barfoo", scores[0].Name)
+		assert.Equal(t, 50, scores[0].Score)
+		assert.Equal(t, "foobar", scores[1].Name)
+		assert.Equal(t, 40, scores[1].Score, "Categories without a multiplier should keep their points")
+	})
+
+	t.Run("points of category multipliers are rounded per challenge", func(t *testing.T) {
+		bundle := testutil.NewTestBundle()
+		bundle.Config.CategoryMultipliers = map[string]float64{
+			"Injection": 1.25,
+			"XSS":       0.5,
+		}
+
+		assert.Equal(t, 13, ChallengePoints(bundle, bu.JuiceShopChallenge{Key: "foo", Category: "Injection", Difficulty: 1}), "12.5 should be rounded up")
+		assert.Equal(t, 15, ChallengePoints(bundle, bu.JuiceShopChallenge{Key: "bar", Category: "XSS", Difficulty: 3}))
+		assert.Equal(t, 20, ChallengePoints(bundle, bu.JuiceShopChallenge{Key: "baz", Category: "Miscellaneous", Difficulty: 2}))
+
+		bundle.Config.ChallengePointOverrides = map[string]int{"foo": 7}
+		assert.Equal(t, 7, ChallengePoints(bundle, bu.JuiceShopChallenge{Key: "foo", Category: "Injection", Difficulty: 1}), "Overrides should not be multiplied")
+	})
+
+	t.Run("difficulty points replace the default points per difficulty", func(t *testing.T) {
+		bundle := testutil.NewTestBundle()
+		bundle.Config.DifficultyPoints = map[int]int{1: 100}
+		bundle.Config.CategoryMultipliers = map[string]float64{"Injection": 1.5}
+
+		assert.Equal(t, 100, ChallengePoints(bundle, bu.JuiceShopChallenge{Key: "foo", Category: "XSS", Difficulty: 1}))
+		assert.Equal(t, 150, ChallengePoints(bundle, bu.JuiceShopChallenge{Key: "bar", Category: "Injection", Difficulty: 1}), "Multipliers should apply to the difficulty points")
+		assert.Equal(t, 30, ChallengePoints(bundle, bu.JuiceShopChallenge{Key: "baz", Category: "XSS", Difficulty: 3}), "Difficulties without an entry should keep the default")
+	})
+
+	t.Run("grants the category completion bonus to teams which solved every challenge of the category", func(t *testing.T) {
+		clientset := fake.NewSimpleClientset(
+			createTeam("foobar", `[{"key":"nullByteChallenge","solvedAt":"2024-11-01T19:55:48.211Z"}]`, "1"),
+			createTeam("barfoo", `[{"key":"scoreBoardChallenge","solvedAt":"2024-11-01T19:55:48.211Z"}]`, "1"),
+		)
+		bundle := testutil.NewTestBundleWithCustomFakeClient(clientset)
+		bundle.JuiceShopChallenges[0].Category = "Miscellaneous"
+		bundle.JuiceShopChallenges[1].Category = "Injection"
+		bundle.Config.CategoryCompletionBonus = map[string]int{
+			"Injection": 100,
+		}
+
+		scoringService := NewScoringService(bundle)
+		err := scoringService.CalculateAndCacheScoreBoard(context.Background())
+		assert.Nil(t, err)
+
+		scores := scoringService.GetTopScores()
+		assert.Equal(t, "foobar", scores[0].Name)
+		assert.Equal(t, 140, scores[0].Score)
+		assert.Equal(t, "barfoo", scores[1].Name)
+		assert.Equal(t, 10, scores[1].Score, "Categories without a bonus shouldn't grant extra points")
+	})
+
+	t.Run("adds score adjustments made by admins to the score", func(t *testing.T) {
+		adjustedTeam := createTeam("foobar", `[{"key":"scoreBoardChallenge","solvedAt":"2024-11-01T19:55:48.211Z"}]`, "1")
+		adjustedTeam.Annotations["multi-juicer.owasp-juice.shop/scoreAdjustment"] = "-5"
+		teamWithoutProgress := createTeam("barfoo", "", "0")
+		teamWithoutProgress.Annotations["multi-juicer.owasp-juice.shop/scoreAdjustment"] = "20"
+		invalidAdjustment := createTeam("test-team", `[{"key":"scoreBoardChallenge","solvedAt":"2024-11-01T19:55:48.211Z"}]`, "1")
+		invalidAdjustment.Annotations["multi-juicer.owasp-juice.shop/scoreAdjustment"] = "lots"
+
+		clientset := fake.NewSimpleClientset(adjustedTeam, teamWithoutProgress, invalidAdjustment)
+		bundle := testutil.NewTestBundleWithCustomFakeClient(clientset)
+
+		scoringService := NewScoringService(bundle)
+		err := scoringService.CalculateAndCacheScoreBoard(context.Background())
+		assert.Nil(t, err)
+
+		scores := scoringService.GetScores()
+		assert.Equal(t, 5, scores[TeamKey("test-namespace", "foobar")].Score)
+		assert.Equal(t, 20, scores[TeamKey("test-namespace", "barfoo")].Score)
+		assert.Equal(t, 10, scores[TeamKey("test-namespace", "test-team")].Score)
 	})
 
 	t.Run("properly sets readiness", func(t *testing.T) {
@@ -213,6 +401,7 @@ func TestScoreingService(t *testing.T) {
 		assert.Equal(t, []*TeamScore{
 			{
 				Name:              "foobar",
+				Namespace:         "test-namespace",
 				Score:             0,
 				Position:          1,
 				Challenges:        []ChallengeProgress{},
@@ -234,16 +423,260 @@ func TestScoreingService(t *testing.T) {
 		err := scoringService.CalculateAndCacheScoreBoard(ctx)
 		assert.Nil(t, err)
 		go scoringService.StartingScoringWorker(ctx)
-		assert.Equal(t, 10, scoringService.GetScores()["foobar"].Score)
+		assert.Equal(t, 10, scoringService.GetScores()[TeamKey("test-namespace", "foobar")].Score)
+		assert.Nil(t, scoringService.GetScores()[TeamKey("test-namespace", "foobar")].NewlySolved, "solves made before the balancer started aren't newly solved")
 
 		watcher := watch.NewFake()
 		clientset.PrependWatchReactor("deployments", testcore.DefaultWatchReactor(watcher, nil))
 		watcher.Modify(createTeam("foobar", `[{"key":"scoreBoardChallenge","solvedAt":"2024-11-01T19:55:48.211Z"},{"key":"nullByteChallenge","solvedAt":"2024-11-01T19:55:48.211Z"}]`, "2"))
 
 		assert.Eventually(t, func() bool {
-			return scoringService.GetScores()["foobar"].Score == 50
+			return scoringService.GetScores()[TeamKey("test-namespace", "foobar")].Score == 50
+		}, 1*time.Second, 10*time.Millisecond)
+		assert.Equal(t, []string{"nullByteChallenge"}, scoringService.GetScores()[TeamKey("test-namespace", "foobar")].NewlySolved)
+	})
+
+	t.Run("watcher marks when the readiness of an instance changes", func(t *testing.T) {
+		clientset := fake.NewClientset(
+			createTeamWithInstanceReadiness("foobar", `[]`, "0", false),
+		)
+		bundle := testutil.NewTestBundleWithCustomFakeClient(clientset)
+		scoringService := NewScoringService(bundle)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		t.Cleanup(cancel)
+
+		err := scoringService.CalculateAndCacheScoreBoard(ctx)
+		assert.Nil(t, err)
+		score, _ := scoringService.GetScoreForTeam("foobar")
+		assert.Nil(t, score.ReadyChangedAt)
+
+		watcher := watch.NewFake()
+		clientset.PrependWatchReactor("deployments", testcore.DefaultWatchReactor(watcher, nil))
+		go scoringService.StartingScoringWorker(ctx)
+
+		watcher.Modify(createTeamWithInstanceReadiness("foobar", `[]`, "0", true))
+		assert.Eventually(t, func() bool {
+			score, _ := scoringService.GetScoreForTeam("foobar")
+			return score.InstanceReadiness && score.ReadyChangedAt != nil
+		}, 1*time.Second, 10*time.Millisecond)
+		score, _ = scoringService.GetScoreForTeam("foobar")
+		readyChangedAt := *score.ReadyChangedAt
+
+		// score changes keep the time of the last readiness change
+		watcher.Modify(createTeamWithInstanceReadiness("foobar", `[{"key":"scoreBoardChallenge","solvedAt":"2024-11-01T19:55:48.211Z"}]`, "1", true))
+		assert.Eventually(t, func() bool {
+			score, _ := scoringService.GetScoreForTeam("foobar")
+			return score.Score == 10
+		}, 1*time.Second, 10*time.Millisecond)
+		score, _ = scoringService.GetScoreForTeam("foobar")
+		assert.Equal(t, readyChangedAt, *score.ReadyChangedAt)
+	})
+
+	t.Run("watcher doesn't update scores while the balancer is read-only", func(t *testing.T) {
+		clientset := fake.NewClientset(
+			createTeam("foobar", `[{"key":"scoreBoardChallenge","solvedAt":"2024-11-01T19:55:48.211Z"}]`, "1"),
+			createTeam("barfoo", `[]`, "0"),
+		)
+		bundle := testutil.NewTestBundleWithCustomFakeClient(clientset)
+		scoringService := NewScoringService(bundle)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		t.Cleanup(cancel)
+
+		err := scoringService.CalculateAndCacheScoreBoard(ctx)
+		assert.Nil(t, err)
+
+		watcher := watch.NewFake()
+		clientset.PrependWatchReactor("deployments", testcore.DefaultWatchReactor(watcher, nil))
+		go scoringService.StartingScoringWorker(ctx)
+
+		scoringService.currentScoresMutex.Lock()
+		lastSeenUpdate := scoringService.lastUpdate
+		scoringService.currentScoresMutex.Unlock()
+
+		bundle.SetReadOnly(true)
+		watcher.Modify(createTeam("foobar", `[{"key":"scoreBoardChallenge","solvedAt":"2024-11-01T19:55:48.211Z"},{"key":"nullByteChallenge","solvedAt":"2024-11-01T19:55:48.211Z"}]`, "2"))
+		watcher.Delete(createTeam("barfoo", `[]`, "0"))
+
+		waitCtx, waitCancel := context.WithTimeout(ctx, 500*time.Millisecond)
+		defer waitCancel()
+		assert.Nil(t, scoringService.WaitForUpdatesNewerThan(waitCtx, lastSeenUpdate))
+		assert.Equal(t, 10, scoringService.GetScores()[TeamKey("test-namespace", "foobar")].Score)
+		assert.Len(t, scoringService.GetTopScores(), 2)
+	})
+
+	t.Run("watcher coalesces bursts of modifications into a single update", func(t *testing.T) {
+		clientset := fake.NewClientset(
+			createTeam("foobar", `[]`, "0"),
+		)
+		bundle := testutil.NewTestBundleWithCustomFakeClient(clientset)
+		scoringService := NewScoringService(bundle)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		t.Cleanup(cancel)
+
+		err := scoringService.CalculateAndCacheScoreBoard(ctx)
+		assert.Nil(t, err)
+
+		watcher := watch.NewFake()
+		clientset.PrependWatchReactor("deployments", testcore.DefaultWatchReactor(watcher, nil))
+		go scoringService.StartingScoringWorker(ctx)
+
+		scoringService.currentScoresMutex.Lock()
+		lastSeenUpdate := scoringService.lastUpdate
+		scoringService.currentScoresMutex.Unlock()
+
+		watcher.Modify(createTeam("foobar", `[{"key":"scoreBoardChallenge","solvedAt":"2024-11-01T19:55:48.211Z"}]`, "1"))
+		watcher.Modify(createTeam("foobar", `[{"key":"scoreBoardChallenge","solvedAt":"2024-11-01T19:55:48.211Z"},{"key":"nullByteChallenge","solvedAt":"2024-11-01T19:55:49.211Z"}]`, "2"))
+
+		updateCount := 0
+		for {
+			waitCtx, waitCancel := context.WithTimeout(ctx, 500*time.Millisecond)
+			scores := scoringService.WaitForUpdatesNewerThan(waitCtx, lastSeenUpdate)
+			waitCancel()
+			if scores == nil {
+				break
+			}
+			updateCount++
+			scoringService.currentScoresMutex.Lock()
+			lastSeenUpdate = scoringService.lastUpdate
+			scoringService.currentScoresMutex.Unlock()
+		}
+
+		assert.Equal(t, 1, updateCount)
+		score, _ := scoringService.GetScoreForTeam("foobar")
+		assert.Equal(t, 50, score.Score)
+	})
+
+	// only fails reliably when the tests are run with -race
+	t.Run("scores can be read while the watcher applies updates", func(t *testing.T) {
+		clientset := fake.NewClientset(
+			createTeam("foobar", `[{"key":"scoreBoardChallenge","solvedAt":"2024-11-01T19:55:48.211Z"}]`, "1"),
+			createTeam("barfoo", `[]`, "0"),
+		)
+		bundle := testutil.NewTestBundleWithCustomFakeClient(clientset)
+		scoringService := NewScoringService(bundle)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		t.Cleanup(cancel)
+
+		err := scoringService.CalculateAndCacheScoreBoard(ctx)
+		assert.Nil(t, err)
+
+		watcher := watch.NewFake()
+		clientset.PrependWatchReactor("deployments", testcore.DefaultWatchReactor(watcher, nil))
+		go scoringService.StartingScoringWorker(ctx)
+
+		readerDone := make(chan struct{})
+		go func() {
+			defer close(readerDone)
+			for i := 0; i < 1000; i++ {
+				for _, score := range scoringService.GetScores() {
+					_ = score.Score + score.Position
+				}
+				for _, score := range scoringService.GetTopScores() {
+					_ = score.Score + score.Position
+				}
+				if score, ok := scoringService.GetScoreForTeam("foobar"); ok {
+					_ = score.Position
+				}
+			}
+		}()
+
+		for i := 0; i < 20; i++ {
+			if i%2 == 0 {
+				watcher.Modify(createTeam("barfoo", `[{"key":"nullByteChallenge","solvedAt":"2024-11-01T19:55:48.211Z"}]`, "1"))
+			} else {
+				watcher.Modify(createTeam("barfoo", `[]`, "0"))
+			}
+		}
+		<-readerDone
+
+		assert.Eventually(t, func() bool {
+			score, ok := scoringService.GetScoreForTeam("barfoo")
+			return ok && score.Score == 0 && score.Position == 2
 		}, 1*time.Second, 10*time.Millisecond)
 	})
+
+	t.Run("waiting for updates returns once the watcher applies a new score", func(t *testing.T) {
+		clientset := fake.NewClientset(
+			createTeam("foobar", `[{"key":"scoreBoardChallenge","solvedAt":"2024-11-01T19:55:48.211Z"}]`, "1"),
+		)
+		bundle := testutil.NewTestBundleWithCustomFakeClient(clientset)
+		scoringService := NewScoringService(bundle)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		t.Cleanup(cancel)
+
+		err := scoringService.CalculateAndCacheScoreBoard(ctx)
+		assert.Nil(t, err)
+
+		watcher := watch.NewFake()
+		clientset.PrependWatchReactor("deployments", testcore.DefaultWatchReactor(watcher, nil))
+		go scoringService.StartingScoringWorker(ctx)
+
+		lastSeenUpdate := time.Now()
+		updates := make(chan []*TeamScore)
+		teamUpdates := make(chan *TeamScore)
+		go func() {
+			updates <- scoringService.WaitForUpdatesNewerThan(ctx, lastSeenUpdate)
+		}()
+		go func() {
+			teamUpdates <- scoringService.WaitForTeamUpdatesNewerThan(ctx, "foobar", lastSeenUpdate)
+		}()
+
+		watcher.Modify(createTeam("foobar", `[{"key":"scoreBoardChallenge","solvedAt":"2024-11-01T19:55:48.211Z"},{"key":"nullByteChallenge","solvedAt":"2024-11-01T19:55:48.211Z"}]`, "2"))
+
+		select {
+		case scores := <-updates:
+			assert.Len(t, scores, 1)
+			assert.Equal(t, 50, scores[0].Score)
+		case <-time.After(1 * time.Second):
+			t.Fatal("WaitForUpdatesNewerThan did not return after the score was updated")
+		}
+		select {
+		case score := <-teamUpdates:
+			assert.Equal(t, 50, score.Score)
+		case <-time.After(1 * time.Second):
+			t.Fatal("WaitForTeamUpdatesNewerThan did not return after the score was updated")
+		}
+	})
+
+	t.Run("waiting for updates returns nil once the context is canceled", func(t *testing.T) {
+		bundle := testutil.NewTestBundleWithCustomFakeClient(fake.NewClientset())
+		scoringService := NewScoringService(bundle)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			cancel()
+		}()
+
+		assert.Nil(t, scoringService.WaitForUpdatesNewerThan(ctx, time.Now()))
+	})
+
+	t.Run("records the recompute duration and team count", func(t *testing.T) {
+		clientset := fake.NewSimpleClientset(
+			createTeam("foobar", `[]`, "0"),
+			createTeam("barfoo", `[]`, "0"),
+		)
+		scoringService := NewScoringService(testutil.NewTestBundleWithCustomFakeClient(clientset))
+		observationsBefore := histogramSampleCount(t, "full")
+
+		err := scoringService.CalculateAndCacheScoreBoard(context.Background())
+		assert.Nil(t, err)
+
+		assert.Equal(t, float64(2), promtestutil.ToFloat64(scoreboardTeamsGauge))
+		assert.Equal(t, observationsBefore+1, histogramSampleCount(t, "full"))
+	})
+}
+
+func histogramSampleCount(t *testing.T, recomputeType string) uint64 {
+	metric := &dto.Metric{}
+	err := scoreboardRecomputeDuration.WithLabelValues(recomputeType).(prometheus.Histogram).Write(metric)
+	assert.Nil(t, err)
+	return metric.GetHistogram().GetSampleCount()
 }
 
 func TestScoreingSorting(t *testing.T) {
@@ -312,4 +745,207 @@ func TestScoreingSorting(t *testing.T) {
 			{Name: "1-second-last-place", Position: 6},
 		}, sortedTeamWithPositions)
 	})
+
+	t.Run("ignores sub-second differences of the solve times when breaking ties", func(t *testing.T) {
+		solvedAt := time.Date(2024, 11, 1, 19, 55, 48, 0, time.UTC)
+		scores := map[string]*TeamScore{
+			"b-team": createTeamScore("b-team", 10, ChallengeProgress{Key: "scoreBoardChallenge", SolvedAt: solvedAt.Add(100 * time.Millisecond)}),
+			"a-team": createTeamScore("a-team", 10, ChallengeProgress{Key: "scoreBoardChallenge", SolvedAt: solvedAt.Add(900 * time.Millisecond)}),
+		}
+
+		sortedTeams := sortTeamsByScoreAndCalculatePositions(scores)
+
+		assert.Equal(t, "a-team", sortedTeams[0].Name, "solves within the same second should be ordered by name")
+		assert.Equal(t, "b-team", sortedTeams[1].Name)
+	})
+
+	t.Run("compares the exact solve times if the precision is set to zero", func(t *testing.T) {
+		solveTimePrecision = 0
+		t.Cleanup(func() { solveTimePrecision = bu.DefaultSolveTimePrecision })
+
+		solvedAt := time.Date(2024, 11, 1, 19, 55, 48, 0, time.UTC)
+		scores := map[string]*TeamScore{
+			"b-team": createTeamScore("b-team", 10, ChallengeProgress{Key: "scoreBoardChallenge", SolvedAt: solvedAt.Add(100 * time.Millisecond)}),
+			"a-team": createTeamScore("a-team", 10, ChallengeProgress{Key: "scoreBoardChallenge", SolvedAt: solvedAt.Add(900 * time.Millisecond)}),
+		}
+
+		sortedTeams := sortTeamsByScoreAndCalculatePositions(scores)
+
+		assert.Equal(t, "b-team", sortedTeams[0].Name)
+		assert.Equal(t, "a-team", sortedTeams[1].Name)
+	})
+}
+
+func BenchmarkCalculateAndCacheScoreBoard(b *testing.B) {
+	deployments := make([]runtime.Object, 0, 500)
+	for i := 0; i < 500; i++ {
+		team := fmt.Sprintf("team-%d", i)
+		challenges := `[{"key":"scoreBoardChallenge","solvedAt":"2024-11-01T19:55:48.211Z"}]`
+		if i%2 == 0 {
+			challenges = `[{"key":"scoreBoardChallenge","solvedAt":"2024-11-01T19:55:48.211Z"},{"key":"nullByteChallenge","solvedAt":"2024-11-01T20:01:12.000Z"}]`
+		}
+		deployments = append(deployments, &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      fmt.Sprintf("juiceshop-%s", team),
+				Namespace: "test-namespace",
+				Annotations: map[string]string{
+					"multi-juicer.owasp-juice.shop/challenges": challenges,
+				},
+				Labels: map[string]string{
+					"app.kubernetes.io/name":    "juice-shop",
+					"app.kubernetes.io/part-of": "multi-juicer",
+					"team":                      team,
+				},
+			},
+		})
+	}
+	bundle := testutil.NewTestBundleWithCustomFakeClient(fake.NewSimpleClientset(deployments...))
+	scoringService := NewScoringService(bundle)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := scoringService.CalculateAndCacheScoreBoard(context.Background()); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+type recordingLogger struct {
+	messages []string
+}
+
+func (l *recordingLogger) Printf(format string, v ...any) {
+	l.messages = append(l.messages, fmt.Sprintf(format, v...))
+}
+
+func TestCalculateScore(t *testing.T) {
+	challengesMap := map[string]bu.JuiceShopChallenge{
+		"scoreBoardChallenge": {Key: "scoreBoardChallenge", Category: "Miscellaneous", Difficulty: 1},
+		"nullByteChallenge":   {Key: "nullByteChallenge", Category: "Improper Input Validation", Difficulty: 4},
+		"xssChallenge":        {Key: "xssChallenge", Category: "XSS", Difficulty: 2},
+	}
+	deployment := func(annotations map[string]string, readyReplicas int32) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels:      map[string]string{"team": "foobar"},
+				Annotations: annotations,
+			},
+			Status: appsv1.DeploymentStatus{ReadyReplicas: readyReplicas},
+		}
+	}
+	solvedAt := time.Date(2024, 11, 1, 19, 55, 48, 0, time.UTC)
+
+	testCases := []struct {
+		name                      string
+		config                    bu.Config
+		annotations               map[string]string
+		expectedScore             int
+		expectedChallenges        []ChallengeProgress
+		expectedUnknownChallenges []string
+		expectedCapped            bool
+		expectedLogMessages       int
+	}{
+		{
+			name:               "no progress annotation",
+			annotations:        map[string]string{},
+			expectedScore:      0,
+			expectedChallenges: []ChallengeProgress{},
+		},
+		{
+			name: "difficulty based points",
+			annotations: map[string]string{
+				"multi-juicer.owasp-juice.shop/challenges": `[{"key":"scoreBoardChallenge","solvedAt":"2024-11-01T19:55:48Z"},{"key":"nullByteChallenge","solvedAt":"2024-11-01T19:55:48Z"}]`,
+			},
+			expectedScore:      50,
+			expectedChallenges: []ChallengeProgress{{Key: "scoreBoardChallenge", SolvedAt: solvedAt}, {Key: "nullByteChallenge", SolvedAt: solvedAt}},
+		},
+		{
+			name: "invalid progress annotation",
+			annotations: map[string]string{
+				"multi-juicer.owasp-juice.shop/challenges":      `not json`,
+				"multi-juicer.owasp-juice.shop/scoreAdjustment": "15",
+			},
+			expectedScore:       15,
+			expectedChallenges:  []ChallengeProgress{},
+			expectedLogMessages: 1,
+		},
+		{
+			name: "invalid score adjustment annotation",
+			annotations: map[string]string{
+				"multi-juicer.owasp-juice.shop/challenges":      `[{"key":"xssChallenge","solvedAt":"2024-11-01T19:55:48Z"}]`,
+				"multi-juicer.owasp-juice.shop/scoreAdjustment": "lots",
+			},
+			expectedScore:       20,
+			expectedChallenges:  []ChallengeProgress{{Key: "xssChallenge", SolvedAt: solvedAt}},
+			expectedLogMessages: 1,
+		},
+		{
+			name: "unknown challenge keys",
+			annotations: map[string]string{
+				"multi-juicer.owasp-juice.shop/challenges": `[{"key":"xssChallenge","solvedAt":"2024-11-01T19:55:48Z"},{"key":"futureChallenge","solvedAt":"2024-11-01T19:55:48Z"}]`,
+			},
+			expectedScore:             20,
+			expectedChallenges:        []ChallengeProgress{{Key: "xssChallenge", SolvedAt: solvedAt}},
+			expectedUnknownChallenges: []string{"futureChallenge"},
+			expectedLogMessages:       1,
+		},
+		{
+			name: "overrides, multipliers and category completion bonus",
+			config: bu.Config{
+				ChallengePointOverrides: map[string]int{"scoreBoardChallenge": 1},
+				CategoryMultipliers:     map[string]float64{"XSS": 1.5},
+				CategoryCompletionBonus: map[string]int{"XSS": 100, "Miscellaneous": 7},
+			},
+			annotations: map[string]string{
+				"multi-juicer.owasp-juice.shop/challenges": `[{"key":"xssChallenge","solvedAt":"2024-11-01T19:55:48Z"}]`,
+			},
+			expectedScore:      130,
+			expectedChallenges: []ChallengeProgress{{Key: "xssChallenge", SolvedAt: solvedAt}},
+		},
+		{
+			name:   "score cap including adjustments",
+			config: bu.Config{MaxTeamScore: 30},
+			annotations: map[string]string{
+				"multi-juicer.owasp-juice.shop/challenges":      `[{"key":"xssChallenge","solvedAt":"2024-11-01T19:55:48Z"}]`,
+				"multi-juicer.owasp-juice.shop/scoreAdjustment": "25",
+			},
+			expectedScore:      30,
+			expectedChallenges: []ChallengeProgress{{Key: "xssChallenge", SolvedAt: solvedAt}},
+			expectedCapped:     true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			logger := &recordingLogger{}
+			score := calculateScore(&testCase.config, logger, deployment(testCase.annotations, 1), challengesMap)
+
+			assert.Equal(t, "foobar", score.Name)
+			assert.Equal(t, testCase.expectedScore, score.Score)
+			assert.Equal(t, testCase.expectedChallenges, score.Challenges)
+			assert.Equal(t, testCase.expectedUnknownChallenges, score.UnknownChallenges)
+			assert.Equal(t, testCase.expectedCapped, score.Capped)
+			assert.True(t, score.InstanceReadiness)
+			assert.Len(t, logger.messages, testCase.expectedLogMessages)
+		})
+	}
+
+	t.Run("reports instances without ready replicas as not ready", func(t *testing.T) {
+		score := calculateScore(&bu.Config{}, &recordingLogger{}, deployment(map[string]string{}, 0), challengesMap)
+		assert.False(t, score.InstanceReadiness)
+	})
+
+	t.Run("reads when the progress of the team got restored", func(t *testing.T) {
+		score := calculateScore(&bu.Config{}, &recordingLogger{}, deployment(map[string]string{LastRestoredAtAnnotation: "2024-11-01T20:00:00Z"}, 1), challengesMap)
+		assert.Equal(t, time.Date(2024, 11, 1, 20, 0, 0, 0, time.UTC), *score.LastRestoredAt)
+
+		logger := &recordingLogger{}
+		score = calculateScore(&bu.Config{}, logger, deployment(map[string]string{LastRestoredAtAnnotation: "yesterday"}, 1), challengesMap)
+		assert.Nil(t, score.LastRestoredAt)
+		assert.Len(t, logger.messages, 1)
+
+		restored := calculateScore(&bu.Config{}, &recordingLogger{}, deployment(map[string]string{LastRestoredAtAnnotation: "2024-11-01T20:00:00Z"}, 1), challengesMap)
+		notRestored := calculateScore(&bu.Config{}, &recordingLogger{}, deployment(map[string]string{}, 1), challengesMap)
+		assert.False(t, notRestored.EqualsIgnoringLastUpdate(restored), "Restores should be propagated as score updates")
+	})
 }
@@ -0,0 +1,170 @@
+package scoring
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/juice-shop/multi-juicer/balancer/pkg/bundle"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// ScoreSnapshot is a point in time copy of the scoreboard. Used to warm up the scoring service after a balancer restart
+type ScoreSnapshot struct {
+	LastUpdate time.Time             `json:"lastUpdate"`
+	Scores     map[string]*TeamScore `json:"scores"`
+}
+
+// ScoreSnapshotStore persists scoreboard snapshots somewhere outside of the balancer process
+type ScoreSnapshotStore interface {
+	Save(ctx context.Context, snapshot *ScoreSnapshot) error
+	// Load returns the last saved snapshot, or nil if no snapshot has been saved yet
+	Load(ctx context.Context) (*ScoreSnapshot, error)
+}
+
+const configMapSnapshotKey = "snapshot.json"
+
+// maxSnapshotSize keeps the snapshot below the 1MiB size limit of ConfigMaps, leaving room for the metadata of the ConfigMap
+const maxSnapshotSize = 1000 * 1024
+
+// ConfigMapSnapshotStore stores the snapshot as json in a kubernetes ConfigMap
+type ConfigMapSnapshotStore struct {
+	clientSet kubernetes.Interface
+	namespace string
+	name      string
+}
+
+func NewConfigMapSnapshotStore(clientSet kubernetes.Interface, namespace string, name string) *ConfigMapSnapshotStore {
+	return &ConfigMapSnapshotStore{
+		clientSet: clientSet,
+		namespace: namespace,
+		name:      name,
+	}
+}
+
+func (c *ConfigMapSnapshotStore) Save(ctx context.Context, snapshot *ScoreSnapshot) error {
+	snapshotBytes, err := json.Marshal(snapshot)
+	if err != nil {
+		return fmt.Errorf("failed to encode score snapshot: %w", err)
+	}
+	if len(snapshotBytes) > maxSnapshotSize {
+		return fmt.Errorf("score snapshot of %d bytes exceeds the max ConfigMap size, keeping the previous snapshot", len(snapshotBytes))
+	}
+
+	configMap, err := c.clientSet.CoreV1().ConfigMaps(c.namespace).Get(ctx, c.name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		_, err = c.clientSet.CoreV1().ConfigMaps(c.namespace).Create(ctx, &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: c.name,
+				Labels: map[string]string{
+					"app.kubernetes.io/name":    "balancer",
+					"app.kubernetes.io/part-of": "multi-juicer",
+				},
+			},
+			Data: map[string]string{
+				configMapSnapshotKey: string(snapshotBytes),
+			},
+		}, metav1.CreateOptions{})
+		if err != nil {
+			return fmt.Errorf("failed to create score snapshot config map: %w", err)
+		}
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("failed to get score snapshot config map: %w", err)
+	}
+
+	if configMap.Data == nil {
+		configMap.Data = map[string]string{}
+	}
+	configMap.Data[configMapSnapshotKey] = string(snapshotBytes)
+	_, err = c.clientSet.CoreV1().ConfigMaps(c.namespace).Update(ctx, configMap, metav1.UpdateOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to update score snapshot config map: %w", err)
+	}
+	return nil
+}
+
+func (c *ConfigMapSnapshotStore) Load(ctx context.Context) (*ScoreSnapshot, error) {
+	configMap, err := c.clientSet.CoreV1().ConfigMaps(c.namespace).Get(ctx, c.name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to get score snapshot config map: %w", err)
+	}
+
+	snapshotJson, ok := configMap.Data[configMapSnapshotKey]
+	if !ok {
+		return nil, nil
+	}
+
+	var snapshot ScoreSnapshot
+	if err := json.Unmarshal([]byte(snapshotJson), &snapshot); err != nil {
+		return nil, fmt.Errorf("failed to decode score snapshot: %w", err)
+	}
+	if snapshot.Scores == nil {
+		snapshot.Scores = map[string]*TeamScore{}
+	}
+	return &snapshot, nil
+}
+
+// NewScoringServiceFromSnapshot creates a scoring service seeded with the last snapshot of the store.
+// The service keeps persisting snapshots to the store while the scoring worker is running
+func NewScoringServiceFromSnapshot(ctx context.Context, b *bundle.Bundle, store ScoreSnapshotStore) *ScoringService {
+	snapshot, err := store.Load(ctx)
+	if err != nil {
+		b.Log.Printf("Failed to load score snapshot, starting with an empty scoreboard: %v", err)
+	}
+	if snapshot == nil {
+		scoringService := NewScoringService(b)
+		scoringService.snapshotStore = store
+		return scoringService
+	}
+
+	b.Log.Printf("Restored scores of %d teams from snapshot taken at %s", len(snapshot.Scores), snapshot.LastUpdate.Format(time.RFC3339))
+	scoringService := NewScoringServiceWithInitialScores(b, snapshot.Scores)
+	scoringService.lastUpdate = snapshot.LastUpdate
+	scoringService.snapshotStore = store
+	scoringService.restoredFromSnapshot = true
+	return scoringService
+}
+
+// RestoredFromSnapshot is true if the scores got seeded from a snapshot, so that they can be served before the first recalculation finished
+func (s *ScoringService) RestoredFromSnapshot() bool {
+	return s.restoredFromSnapshot
+}
+
+// saveSnapshotIfChanged persists the current scores if they changed since the last saved snapshot
+func (s *ScoringService) saveSnapshotIfChanged(ctx context.Context) {
+	if s.snapshotStore == nil {
+		return
+	}
+
+	s.currentScoresMutex.Lock()
+	if !s.lastUpdate.After(s.lastSnapshot) {
+		s.currentScoresMutex.Unlock()
+		return
+	}
+	snapshot := &ScoreSnapshot{
+		LastUpdate: s.lastUpdate,
+		Scores:     make(map[string]*TeamScore, len(s.currentScores)),
+	}
+	for team, score := range s.currentScores {
+		// copied as the snapshot gets marshalled after releasing the mutex, while the scoring keeps updating the scores concurrently
+		scoreCopy := *score
+		snapshot.Scores[team] = &scoreCopy
+	}
+	s.currentScoresMutex.Unlock()
+
+	if err := s.snapshotStore.Save(ctx, snapshot); err != nil {
+		s.bundle.Log.Printf("Failed to save score snapshot: %v", err)
+		return
+	}
+
+	s.currentScoresMutex.Lock()
+	s.lastSnapshot = snapshot.LastUpdate
+	s.currentScoresMutex.Unlock()
+}
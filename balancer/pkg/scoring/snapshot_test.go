@@ -0,0 +1,117 @@
+package scoring
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/juice-shop/multi-juicer/balancer/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestConfigMapSnapshotStore(t *testing.T) {
+	novemberFirst := time.Date(2024, 11, 1, 19, 55, 48, 211000000, time.UTC)
+
+	t.Run("returns no snapshot if none has been saved yet", func(t *testing.T) {
+		store := NewConfigMapSnapshotStore(fake.NewClientset(), "test-namespace", "score-snapshot")
+
+		snapshot, err := store.Load(context.Background())
+		assert.NoError(t, err)
+		assert.Nil(t, snapshot)
+	})
+
+	t.Run("loads previously saved snapshots", func(t *testing.T) {
+		store := NewConfigMapSnapshotStore(fake.NewClientset(), "test-namespace", "score-snapshot")
+
+		for _, score := range []int{10, 50} {
+			err := store.Save(context.Background(), &ScoreSnapshot{
+				LastUpdate: novemberFirst,
+				Scores: map[string]*TeamScore{
+					"foobar": {Name: "foobar", Score: score, Position: 1, Challenges: []ChallengeProgress{}},
+				},
+			})
+			require.NoError(t, err)
+		}
+
+		snapshot, err := store.Load(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, novemberFirst, snapshot.LastUpdate)
+		assert.Equal(t, 50, snapshot.Scores["foobar"].Score)
+	})
+
+	t.Run("scoring service gets seeded from the snapshot and keeps persisting updates", func(t *testing.T) {
+		clientset := fake.NewClientset()
+		store := NewConfigMapSnapshotStore(clientset, "test-namespace", "score-snapshot")
+		err := store.Save(context.Background(), &ScoreSnapshot{
+			LastUpdate: novemberFirst,
+			Scores: map[string]*TeamScore{
+				"foobar": {Name: "foobar", Score: 10, Position: 1, Challenges: []ChallengeProgress{}},
+			},
+		})
+		require.NoError(t, err)
+
+		bundle := testutil.NewTestBundleWithCustomFakeClient(clientset)
+		scoringService := NewScoringServiceFromSnapshot(context.Background(), bundle, store)
+
+		score, ok := scoringService.GetScoreForTeam("foobar")
+		require.True(t, ok)
+		assert.Equal(t, 10, score.Score)
+		assert.Equal(t, "test-namespace", score.Namespace, "Scores of snapshots taken before scores were keyed by namespace belong to the namespace of the balancer")
+		assert.Len(t, scoringService.GetTopScores(), 1)
+
+		// no deployment exists for the team anymore, so recalculating the scores drops it
+		err = scoringService.CalculateAndCacheScoreBoard(context.Background())
+		require.NoError(t, err)
+		scoringService.saveSnapshotIfChanged(context.Background())
+
+		snapshot, err := store.Load(context.Background())
+		require.NoError(t, err)
+		assert.Empty(t, snapshot.Scores)
+		assert.True(t, snapshot.LastUpdate.After(novemberFirst))
+	})
+	t.Run("restored scoring service serves the snapshot before the first recalculation", func(t *testing.T) {
+		clientset := fake.NewClientset()
+		store := NewConfigMapSnapshotStore(clientset, "test-namespace", "score-snapshot")
+		err := store.Save(context.Background(), &ScoreSnapshot{
+			LastUpdate: novemberFirst,
+			Scores: map[string]*TeamScore{
+				TeamKey("test-namespace", "foobar"): {Name: "foobar", Namespace: "test-namespace", Score: 50, Position: 1, Challenges: []ChallengeProgress{}},
+				TeamKey("test-namespace", "barfoo"): {Name: "barfoo", Namespace: "test-namespace", Score: 10, Position: 2, Challenges: []ChallengeProgress{}},
+			},
+		})
+		require.NoError(t, err)
+
+		bundle := testutil.NewTestBundleWithCustomFakeClient(clientset)
+		scoringService := NewScoringServiceFromSnapshot(context.Background(), bundle, store)
+
+		assert.True(t, scoringService.RestoredFromSnapshot())
+		topScores, lastUpdate, _ := scoringService.GetPublicTopScoresWithLastUpdate()
+		require.Len(t, topScores, 2)
+		assert.Equal(t, "foobar", topScores[0].Name)
+		assert.Equal(t, "barfoo", topScores[1].Name)
+		assert.Equal(t, novemberFirst, lastUpdate)
+
+		assert.False(t, NewScoringService(bundle).RestoredFromSnapshot())
+	})
+
+	t.Run("refuses to save snapshots exceeding the max ConfigMap size", func(t *testing.T) {
+		clientset := fake.NewClientset()
+		store := NewConfigMapSnapshotStore(clientset, "test-namespace", "score-snapshot")
+		require.NoError(t, store.Save(context.Background(), &ScoreSnapshot{LastUpdate: novemberFirst, Scores: map[string]*TeamScore{}}))
+
+		scores := map[string]*TeamScore{}
+		for i := 0; i < 10000; i++ {
+			team := fmt.Sprintf("team-%d", i)
+			scores[TeamKey("test-namespace", team)] = &TeamScore{Name: team, Namespace: "test-namespace", Challenges: []ChallengeProgress{{Key: "scoreBoardChallenge", SolvedAt: novemberFirst}}}
+		}
+		err := store.Save(context.Background(), &ScoreSnapshot{LastUpdate: novemberFirst.Add(time.Hour), Scores: scores})
+		assert.Error(t, err)
+
+		snapshot, err := store.Load(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, novemberFirst, snapshot.LastUpdate, "Should keep the previous snapshot")
+	})
+}
@@ -0,0 +1,55 @@
+package scoring
+
+import (
+	"sort"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var unknownChallengeKeysCounter = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "multijuicer_unknown_challenge_keys_total",
+		Help: `Number of times a solved challenge wasn't part of the challenges.json of the balancer while calculating scores. Indicates that the challenges.json doesn't match the version of the JuiceShop instances.`,
+	},
+	[]string{"key"},
+)
+
+func init() {
+	prometheus.MustRegister(unknownChallengeKeysCounter)
+}
+
+// countUnknownChallenges records the unknown challenges of a freshly calculated score in the metrics
+func countUnknownChallenges(score *TeamScore) {
+	for _, key := range score.UnknownChallenges {
+		unknownChallengeKeysCounter.WithLabelValues(key).Inc()
+	}
+}
+
+// UnknownChallenge is a solved challenge key which isn't part of the challenges.json, together with the teams which solved it
+type UnknownChallenge struct {
+	Key   string   `json:"key"`
+	Teams []string `json:"teams"`
+}
+
+// GetUnknownChallenges returns the solved challenge keys of all current teams which aren't part of the challenges.json, sorted by key
+func (s *ScoringService) GetUnknownChallenges() []UnknownChallenge {
+	s.currentScoresMutex.Lock()
+	defer s.currentScoresMutex.Unlock()
+
+	teamsByKey := map[string][]string{}
+	for _, score := range s.currentScores {
+		for _, key := range score.UnknownChallenges {
+			teamsByKey[key] = append(teamsByKey[key], score.Name)
+		}
+	}
+
+	unknownChallenges := make([]UnknownChallenge, 0, len(teamsByKey))
+	for key, teams := range teamsByKey {
+		sort.Strings(teams)
+		unknownChallenges = append(unknownChallenges, UnknownChallenge{Key: key, Teams: teams})
+	}
+	sort.Slice(unknownChallenges, func(i, j int) bool {
+		return unknownChallenges[i].Key < unknownChallenges[j].Key
+	})
+	return unknownChallenges
+}
@@ -0,0 +1,32 @@
+package scoring
+
+import (
+	"math/rand"
+	"time"
+)
+
+// minWatchRestartDelay is the delay before restarting a watch after the first failure
+const minWatchRestartDelay = 1 * time.Second
+
+// healthyWatchDuration is how long a watch has to run before it counts as healthy, which resets the backoff
+const healthyWatchDuration = 1 * time.Minute
+
+// watchRestartBackoff doubles the delay between restarts of a watch which keeps failing, e.g. because of missing RBAC permissions, so that it doesn't hammer the API server and the logs
+type watchRestartBackoff struct {
+	maxDelay time.Duration
+	delay    time.Duration
+}
+
+// next returns the delay before restarting the watch, given how long the previous watch ran.
+// The delay is jittered between half and the full backoff, so that multiple balancer replicas don't reconnect in lockstep
+func (b *watchRestartBackoff) next(watchDuration time.Duration) time.Duration {
+	if watchDuration >= healthyWatchDuration || b.delay == 0 {
+		b.delay = minWatchRestartDelay
+	} else {
+		b.delay *= 2
+	}
+	if b.delay > b.maxDelay {
+		b.delay = b.maxDelay
+	}
+	return b.delay/2 + time.Duration(rand.Int63n(int64(b.delay/2)+1))
+}
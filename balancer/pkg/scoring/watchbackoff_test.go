@@ -0,0 +1,36 @@
+package scoring
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWatchRestartBackoff(t *testing.T) {
+	assertDelayBetween := func(t *testing.T, delay time.Duration, min time.Duration, max time.Duration) {
+		assert.GreaterOrEqual(t, delay, min)
+		assert.LessOrEqual(t, delay, max)
+	}
+
+	t.Run("doubles the delay for watches which keep failing up to the max", func(t *testing.T) {
+		backoff := &watchRestartBackoff{maxDelay: 10 * time.Second}
+
+		assertDelayBetween(t, backoff.next(0), 500*time.Millisecond, 1*time.Second)
+		assertDelayBetween(t, backoff.next(0), 1*time.Second, 2*time.Second)
+		assertDelayBetween(t, backoff.next(0), 2*time.Second, 4*time.Second)
+		assertDelayBetween(t, backoff.next(0), 4*time.Second, 8*time.Second)
+		for i := 0; i < 10; i++ {
+			assertDelayBetween(t, backoff.next(0), 5*time.Second, 10*time.Second)
+		}
+	})
+
+	t.Run("resets after a long-lived watch", func(t *testing.T) {
+		backoff := &watchRestartBackoff{maxDelay: 10 * time.Second}
+		for i := 0; i < 5; i++ {
+			backoff.next(time.Second)
+		}
+
+		assertDelayBetween(t, backoff.next(healthyWatchDuration), 500*time.Millisecond, 1*time.Second)
+	})
+}
@@ -0,0 +1,39 @@
+package scoring
+
+import "time"
+
+// withholdScoresOfNotReadyInstances returns the scoreboard with teams whose instance isn't ready shown without points and solved challenges. Must be called while holding the currentScoresMutex.
+// Withheld teams tie with all other teams without points. As their challenges are withheld as well, the solve time tiebreak doesn't apply to them and they are ordered by name among the teams without points
+func (s *ScoringService) withholdScoresOfNotReadyInstances(scores []*TeamScore, lastUpdate time.Time) []*TeamScore {
+	if s.withheldScoresSorted != nil && s.withheldScoresCalculatedAt.Equal(lastUpdate) {
+		return s.withheldScoresSorted
+	}
+
+	withheldScores := make(map[string]*TeamScore, len(scores))
+	for _, score := range scores {
+		if score.InstanceReadiness {
+			withheldScores[score.Key()] = score
+			continue
+		}
+		withheldScore := *score
+		withheldScore.Score = 0
+		withheldScore.Challenges = []ChallengeProgress{}
+		withheldScore.NewlySolved = nil
+		withheldScore.Capped = false
+		withheldScore.CappedAt = nil
+		withheldScores[score.Key()] = &withheldScore
+	}
+
+	s.withheldScoresSorted = sortTeamsByScoreAndCalculatePositions(withheldScores)
+	s.withheldScoresCalculatedAt = lastUpdate
+	return s.withheldScoresSorted
+}
+
+// isWithheld checks if the solves of the team are currently withheld from the public scoreboard. Must be called while holding the currentScoresMutex
+func (s *ScoringService) isWithheld(teamKey string) bool {
+	if !s.bundle.Config.HideScoresOfNotReadyInstances {
+		return false
+	}
+	score, ok := s.currentScores[teamKey]
+	return ok && !score.InstanceReadiness
+}
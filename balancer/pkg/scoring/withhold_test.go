@@ -0,0 +1,77 @@
+package scoring
+
+import (
+	"testing"
+	"time"
+
+	"github.com/juice-shop/multi-juicer/balancer/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithholdScoresOfNotReadyInstances(t *testing.T) {
+	initialScores := func() map[string]*TeamScore {
+		return map[string]*TeamScore{
+			"ready":     {Name: "ready", Score: 10, Challenges: []ChallengeProgress{{Key: "scoreBoardChallenge", SolvedAt: time.Date(2024, 11, 1, 19, 55, 48, 0, time.UTC)}}, InstanceReadiness: true},
+			"not-ready": {Name: "not-ready", Score: 50, Challenges: []ChallengeProgress{{Key: "nullByteChallenge", SolvedAt: time.Date(2024, 11, 1, 19, 50, 0, 0, time.UTC)}}, NewlySolved: []string{"nullByteChallenge"}, InstanceReadiness: false},
+			"new":       {Name: "new", Score: 0, Challenges: []ChallengeProgress{}, InstanceReadiness: true},
+		}
+	}
+
+	t.Run("public scoreboard shows the full scores by default", func(t *testing.T) {
+		bundle := testutil.NewTestBundle()
+		scoringService := NewScoringServiceWithInitialScores(bundle, initialScores())
+
+		scores, _, _ := scoringService.GetPublicTopScoresWithLastUpdate()
+		assert.Equal(t, "not-ready", scores[0].Name)
+		assert.Equal(t, 50, scores[0].Score)
+	})
+
+	t.Run("withholds the scores of not ready instances from the public scoreboard only", func(t *testing.T) {
+		bundle := testutil.NewTestBundle()
+		bundle.Config.HideScoresOfNotReadyInstances = true
+		scoringService := NewScoringServiceWithInitialScores(bundle, initialScores())
+
+		scores, _, _ := scoringService.GetPublicTopScoresWithLastUpdate()
+		assert.Len(t, scores, 3)
+		assert.Equal(t, "ready", scores[0].Name)
+		assert.Equal(t, 1, scores[0].Position)
+		assert.Equal(t, "new", scores[1].Name)
+		assert.Equal(t, 2, scores[1].Position)
+		assert.Equal(t, "not-ready", scores[2].Name)
+		assert.Equal(t, 0, scores[2].Score)
+		assert.Equal(t, 2, scores[2].Position)
+		assert.Empty(t, scores[2].Challenges)
+		assert.Nil(t, scores[2].NewlySolved, "Should not reveal the newly solved challenges either")
+
+		liveScore, ok := scoringService.GetScoreForTeam("not-ready")
+		assert.True(t, ok)
+		assert.Equal(t, 50, liveScore.Score, "Admins should still see the real score")
+		assert.Equal(t, 1, liveScore.Position)
+	})
+
+	t.Run("withheld teams don't reveal that they reached the score cap", func(t *testing.T) {
+		bundle := testutil.NewTestBundle()
+		bundle.Config.HideScoresOfNotReadyInstances = true
+		bundle.Config.MaxTeamScore = 40
+		earlyCap := time.Date(2024, 11, 1, 19, 0, 0, 0, time.UTC)
+		lateCap := time.Date(2024, 11, 1, 20, 0, 0, 0, time.UTC)
+		scoringService := NewScoringServiceWithInitialScores(bundle, map[string]*TeamScore{
+			"b-capped": {Name: "b-capped", Score: 40, Capped: true, CappedAt: &earlyCap, Challenges: []ChallengeProgress{{Key: "nullByteChallenge", SolvedAt: earlyCap}}, InstanceReadiness: false},
+			"a-capped": {Name: "a-capped", Score: 40, Capped: true, CappedAt: &lateCap, Challenges: []ChallengeProgress{{Key: "nullByteChallenge", SolvedAt: lateCap}}, InstanceReadiness: false},
+		})
+
+		scores, _, _ := scoringService.GetPublicTopScoresWithLastUpdate()
+		assert.Len(t, scores, 2)
+		assert.Equal(t, "a-capped", scores[0].Name, "Withheld teams should be ordered by name, not by when they reached the cap")
+		assert.Equal(t, "b-capped", scores[1].Name)
+		for _, score := range scores {
+			assert.Equal(t, 0, score.Score)
+			assert.False(t, score.Capped)
+			assert.Nil(t, score.CappedAt)
+		}
+
+		liveScore, ok := scoringService.GetScoreForTeam("b-capped")
+		assert.True(t, ok)
+		assert.True(t, liveScore.Capped, "Admins should still see the cap")
+	})
+}
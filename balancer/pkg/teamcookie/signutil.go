@@ -1,8 +1,10 @@
 package teamcookie
 
 import (
+	"crypto/subtle"
 	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/juice-shop/multi-juicer/balancer/pkg/bundle"
 	"github.com/juice-shop/multi-juicer/balancer/pkg/signutil"
@@ -20,3 +22,22 @@ func GetTeamFromRequest(bundle *bundle.Bundle, req *http.Request) (string, error
 
 	return cookieTeamname, nil
 }
+
+// GetTeamFromAdminRequest is used by the admin routes to identify the caller.
+// Next to the team cookie it also accepts a `Authorization: Bearer <token>` header matching the configured admin api token, to allow scripting the admin endpoints.
+func GetTeamFromAdminRequest(bundle *bundle.Bundle, req *http.Request) (string, error) {
+	authorizationHeader := req.Header.Get("Authorization")
+	if !strings.HasPrefix(authorizationHeader, "Bearer ") {
+		return GetTeamFromRequest(bundle, req)
+	}
+
+	token := strings.TrimPrefix(authorizationHeader, "Bearer ")
+	apiToken := bundle.Config.AdminConfig.ApiToken
+	if apiToken == "" {
+		return "", fmt.Errorf("admin api token authentication is not configured")
+	}
+	if subtle.ConstantTimeCompare([]byte(token), []byte(apiToken)) != 1 {
+		return "", fmt.Errorf("invalid admin api token")
+	}
+	return bundle.AdminTeamName(), nil
+}
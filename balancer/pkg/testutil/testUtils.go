@@ -1,6 +1,8 @@
 package testutil
 
 import (
+	"context"
+	"errors"
 	"log"
 	"os"
 
@@ -32,6 +34,9 @@ func NewTestBundleWithCustomFakeClient(clientset kubernetes.Interface) *bundle.B
 		GetJuiceShopUrlForTeam: func(team string, bundle *bundle.Bundle) string {
 			return "http://localhost:8080"
 		},
+		GetJuiceShopResourceUsage: func(ctx context.Context, bundle *bundle.Bundle) (map[string]bundle.JuiceShopResourceUsage, error) {
+			return nil, errors.New("metrics api is not available in tests")
+		},
 		JuiceShopChallenges: []bundle.JuiceShopChallenge{
 			{
 				Key:        "scoreBoardChallenge",
@@ -71,6 +76,7 @@ func NewTestBundleWithCustomFakeClient(clientset kubernetes.Interface) *bundle.B
 			},
 			AdminConfig: &bundle.AdminConfig{
 				Password: "mock-admin-password",
+				ApiToken: "mock-admin-api-token",
 			},
 		},
 	}
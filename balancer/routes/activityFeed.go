@@ -29,7 +29,8 @@ func (a BySolvedAt) Less(i, j int) bool { return a[i].SolvedAt.After(a[j].Solved
 
 func handleActivityFeed(bundle *b.Bundle, scoringService *scoring.ScoringService) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		allTeamScores := scoringService.GetScores()
+		// based on the public scoreboard, so that the feed doesn't reveal solves made after a freeze
+		allTeamScores, _, _ := scoringService.GetPublicTopScoresWithLastUpdate()
 		allEvents := make([]ActivityEvent, 0)
 		firstSolves := make(map[string]time.Time) // Map challengeKey -> first solve time
 
@@ -39,7 +40,7 @@ func handleActivityFeed(bundle *b.Bundle, scoringService *scoring.ScoringService
 		}
 
 		// 1. Collect all solve events from all teams
-		for teamName, teamScore := range allTeamScores {
+		for _, teamScore := range allTeamScores {
 			for _, solvedChallenge := range teamScore.Challenges {
 				challengeDetails, ok := challengeMap[solvedChallenge.Key]
 				if !ok {
@@ -47,10 +48,10 @@ func handleActivityFeed(bundle *b.Bundle, scoringService *scoring.ScoringService
 				}
 
 				event := ActivityEvent{
-					Team:          teamName,
+					Team:          bundle.PublicTeamName(teamScore.Name),
 					ChallengeKey:  solvedChallenge.Key,
 					ChallengeName: challengeDetails.Name,
-					Points:        challengeDetails.Difficulty * 10,
+					Points:        scoring.ChallengePoints(bundle, challengeDetails),
 					SolvedAt:      solvedChallenge.SolvedAt,
 				}
 				allEvents = append(allEvents, event)
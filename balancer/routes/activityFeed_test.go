@@ -9,6 +9,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/juice-shop/multi-juicer/balancer/pkg/bundle"
 	"github.com/juice-shop/multi-juicer/balancer/pkg/scoring"
 	"github.com/juice-shop/multi-juicer/balancer/pkg/testutil"
 	"github.com/stretchr/testify/assert"
@@ -34,6 +35,20 @@ func createTeamWithSolvedChallenges(team string, challengesJSON string) *appsv1.
 	}
 }
 
+// newFrozenScoringService freezes the public scoreboard with the current state of the deployments and lets the team solve challengesAfterFreeze afterwards
+func newFrozenScoringService(t *testing.T, clientset *fake.Clientset, team string, challengesAfterFreeze string) (*bundle.Bundle, *scoring.ScoringService) {
+	bundle := testutil.NewTestBundleWithCustomFakeClient(clientset)
+	freezeAt := time.Now().Add(-time.Minute)
+	bundle.Config.FreezeScoreboardAt = &freezeAt
+	scoringService := scoring.NewScoringService(bundle)
+	require.NoError(t, scoringService.CalculateAndCacheScoreBoard(context.Background()))
+
+	_, err := clientset.AppsV1().Deployments("test-namespace").Update(context.Background(), createTeamWithSolvedChallenges(team, challengesAfterFreeze), metav1.UpdateOptions{})
+	require.NoError(t, err)
+	require.NoError(t, scoringService.CalculateAndCacheScoreBoard(context.Background()))
+	return bundle, scoringService
+}
+
 func TestActivityFeedHandler(t *testing.T) {
 
 	t.Run("with multiple solves, should return sorted events with correct first solve", func(t *testing.T) {
@@ -159,4 +174,48 @@ func TestActivityFeedHandler(t *testing.T) {
 		assert.Len(t, feed, 15, "Feed should be limited to 15 events")
 		assert.Equal(t, newestSolveTime.UTC().Truncate(time.Second), feed[0].SolvedAt.UTC().Truncate(time.Second))
 	})
+
+	t.Run("doesn't include solves made after the scoreboard got frozen", func(t *testing.T) {
+		clientset := fake.NewSimpleClientset(
+			createTeamWithSolvedChallenges("team-alpha", `[{"key":"scoreBoardChallenge","solvedAt":"2024-11-01T19:00:00Z"}]`),
+		)
+		bundle, scoringService := newFrozenScoringService(t, clientset, "team-alpha", `[{"key":"scoreBoardChallenge","solvedAt":"2024-11-01T19:00:00Z"},{"key":"nullByteChallenge","solvedAt":"2024-11-01T20:00:00Z"}]`)
+		server := http.NewServeMux()
+		AddRoutes(server, bundle, scoringService)
+
+		req, _ := http.NewRequest("GET", "/balancer/api/v2/activity-feed", nil)
+		rr := httptest.NewRecorder()
+		server.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		var feed []ActivityEvent
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &feed))
+		require.Len(t, feed, 1)
+		assert.Equal(t, "scoreBoardChallenge", feed[0].ChallengeKey)
+	})
+
+	t.Run("doesn't include solves of teams whose scores are withheld", func(t *testing.T) {
+		notReadyTeam := createTeamWithSolvedChallenges("team-bravo", `[{"key":"nullByteChallenge","solvedAt":"2024-11-01T20:00:00Z"}]`)
+		notReadyTeam.Status.ReadyReplicas = 0
+		clientset := fake.NewSimpleClientset(
+			createTeamWithSolvedChallenges("team-alpha", `[{"key":"scoreBoardChallenge","solvedAt":"2024-11-01T19:00:00Z"}]`),
+			notReadyTeam,
+		)
+		bundle := testutil.NewTestBundleWithCustomFakeClient(clientset)
+		bundle.Config.HideScoresOfNotReadyInstances = true
+		scoringService := scoring.NewScoringService(bundle)
+		require.NoError(t, scoringService.CalculateAndCacheScoreBoard(context.Background()))
+		server := http.NewServeMux()
+		AddRoutes(server, bundle, scoringService)
+
+		req, _ := http.NewRequest("GET", "/balancer/api/v2/activity-feed", nil)
+		rr := httptest.NewRecorder()
+		server.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		var feed []ActivityEvent
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &feed))
+		require.Len(t, feed, 1)
+		assert.Equal(t, "team-alpha", feed[0].Team)
+	})
 }
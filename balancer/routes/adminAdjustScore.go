@@ -0,0 +1,116 @@
+package routes
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/juice-shop/multi-juicer/balancer/pkg/bundle"
+	"github.com/juice-shop/multi-juicer/balancer/pkg/teamcookie"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+)
+
+type adminAdjustScoreRequest struct {
+	Delta  int    `json:"delta"`
+	Reason string `json:"reason"`
+}
+
+type adminAdjustScoreResponse struct {
+	Team            string `json:"team"`
+	ScoreAdjustment int    `json:"scoreAdjustment"`
+}
+
+// handleAdminAdjustScore grants or deducts points of a team, e.g. to resolve disputes.
+// Adjustments add up and are stored on the deployment, so that they are included when the scoring service calculates the score of the team
+func handleAdminAdjustScore(bundle *bundle.Bundle) http.Handler {
+	return http.HandlerFunc(
+		func(responseWriter http.ResponseWriter, req *http.Request) {
+			team, err := teamcookie.GetTeamFromAdminRequest(bundle, req)
+			if err != nil || !bundle.IsAdminTeam(team) {
+				writeJSONError(responseWriter, http.StatusUnauthorized, "unauthorized", "admin login required")
+				return
+			}
+
+			teamToAdjust := req.PathValue("team")
+			if !isValidTeamName(teamToAdjust) {
+				writeJSONError(responseWriter, http.StatusBadRequest, "invalid_team_name", "invalid team name")
+				return
+			}
+
+			var adjustment adminAdjustScoreRequest
+			if err := json.NewDecoder(req.Body).Decode(&adjustment); err != nil {
+				writeJSONError(responseWriter, http.StatusBadRequest, "invalid_json", "invalid json")
+				return
+			}
+			if adjustment.Delta == 0 {
+				writeJSONError(responseWriter, http.StatusBadRequest, "invalid_delta", "delta must not be 0")
+				return
+			}
+			if adjustment.Reason == "" {
+				writeJSONError(responseWriter, http.StatusBadRequest, "missing_reason", "reason is required")
+				return
+			}
+
+			// the patch includes the resourceVersion the adjustment is based on, concurrent adjustments then conflict and get retried instead of overwriting each other
+			scoreAdjustment := 0
+			err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+				deployment, err := bundle.ClientSet.AppsV1().Deployments(bundle.RuntimeEnvironment.Namespace).Get(req.Context(), fmt.Sprintf("juiceshop-%s", teamToAdjust), metav1.GetOptions{})
+				if err != nil {
+					return err
+				}
+
+				previousAdjustment := 0
+				if previousAdjustmentString, ok := deployment.Annotations["multi-juicer.owasp-juice.shop/scoreAdjustment"]; ok && previousAdjustmentString != "" {
+					previousAdjustment, err = strconv.Atoi(previousAdjustmentString)
+					if err != nil {
+						bundle.Log.Printf("Team '%s' has an invalid score adjustment '%s', overwriting it", teamToAdjust, previousAdjustmentString)
+						previousAdjustment = 0
+					}
+				}
+				scoreAdjustment = previousAdjustment + adjustment.Delta
+
+				patch, err := json.Marshal(map[string]interface{}{
+					"metadata": map[string]interface{}{
+						"resourceVersion": deployment.ResourceVersion,
+						"annotations": map[string]interface{}{
+							"multi-juicer.owasp-juice.shop/scoreAdjustment": fmt.Sprintf("%d", scoreAdjustment),
+						},
+					},
+				})
+				if err != nil {
+					return fmt.Errorf("failed to convert score adjustment patch to json: %w", err)
+				}
+
+				_, err = bundle.ClientSet.AppsV1().Deployments(bundle.RuntimeEnvironment.Namespace).Patch(req.Context(), deployment.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+				return err
+			})
+			if errors.IsNotFound(err) {
+				writeJSONError(responseWriter, http.StatusNotFound, "team_not_found", "team not found")
+				return
+			} else if err != nil {
+				bundle.Log.Printf("Failed to adjust score of team '%s': %s", teamToAdjust, err)
+				writeJSONError(responseWriter, http.StatusInternalServerError, "internal_error", "internal server error")
+				return
+			}
+
+			bundle.Log.Printf("Admin '%s' adjusted the score of team '%s' by %d points (total adjustment: %d). Reason: %s", team, teamToAdjust, adjustment.Delta, scoreAdjustment, adjustment.Reason)
+
+			responseBytes, err := json.Marshal(adminAdjustScoreResponse{
+				Team:            teamToAdjust,
+				ScoreAdjustment: scoreAdjustment,
+			})
+			if err != nil {
+				bundle.Log.Printf("Failed to marshal score adjustment response: %s", err)
+				writeJSONError(responseWriter, http.StatusInternalServerError, "internal_error", "internal server error")
+				return
+			}
+			responseWriter.Header().Set("Content-Type", "application/json")
+			responseWriter.WriteHeader(http.StatusOK)
+			responseWriter.Write(responseBytes)
+		},
+	)
+}
@@ -0,0 +1,174 @@
+package routes
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/juice-shop/multi-juicer/balancer/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	testcore "k8s.io/client-go/testing"
+)
+
+func TestAdminAdjustScoreHandler(t *testing.T) {
+	createDeploymentForTeam := func(team string, annotations map[string]string) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        fmt.Sprintf("juiceshop-%s", team),
+				Namespace:   "test-namespace",
+				Annotations: annotations,
+				Labels: map[string]string{
+					"app.kubernetes.io/name":    "juice-shop",
+					"app.kubernetes.io/part-of": "multi-juicer",
+					"team":                      team,
+				},
+			},
+		}
+	}
+
+	t.Run("adjusting scores requires admin login", func(t *testing.T) {
+		req, _ := http.NewRequest("POST", "/balancer/api/admin/teams/foobar/adjust", bytes.NewBufferString(`{"delta":10,"reason":"found a bug"}`))
+		req.Header.Set("Cookie", fmt.Sprintf("team=%s", testutil.SignTestTeamname("foobar")))
+		rr := httptest.NewRecorder()
+
+		server := http.NewServeMux()
+
+		clientset := fake.NewSimpleClientset(createDeploymentForTeam("foobar", map[string]string{}))
+		bundle := testutil.NewTestBundleWithCustomFakeClient(clientset)
+		AddRoutes(server, bundle, nil)
+
+		server.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+		assert.Len(t, clientset.Actions(), 0)
+	})
+
+	t.Run("requires a delta and a reason", func(t *testing.T) {
+		for _, body := range []string{`{"delta":10}`, `{"reason":"found a bug"}`, `not json`} {
+			req, _ := http.NewRequest("POST", "/balancer/api/admin/teams/foobar/adjust", bytes.NewBufferString(body))
+			req.Header.Set("Cookie", fmt.Sprintf("team=%s", testutil.SignTestTeamname("admin")))
+			rr := httptest.NewRecorder()
+
+			server := http.NewServeMux()
+
+			clientset := fake.NewSimpleClientset(createDeploymentForTeam("foobar", map[string]string{}))
+			bundle := testutil.NewTestBundleWithCustomFakeClient(clientset)
+			AddRoutes(server, bundle, nil)
+
+			server.ServeHTTP(rr, req)
+
+			assert.Equal(t, http.StatusBadRequest, rr.Code, body)
+			assert.Len(t, clientset.Actions(), 0)
+		}
+	})
+
+	t.Run("returns 404 for teams which don't exist", func(t *testing.T) {
+		req, _ := http.NewRequest("POST", "/balancer/api/admin/teams/foobar/adjust", bytes.NewBufferString(`{"delta":10,"reason":"found a bug"}`))
+		req.Header.Set("Cookie", fmt.Sprintf("team=%s", testutil.SignTestTeamname("admin")))
+		rr := httptest.NewRecorder()
+
+		server := http.NewServeMux()
+
+		clientset := fake.NewSimpleClientset()
+		bundle := testutil.NewTestBundleWithCustomFakeClient(clientset)
+		AddRoutes(server, bundle, nil)
+
+		server.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("adds the delta to previous adjustments", func(t *testing.T) {
+		req, _ := http.NewRequest("POST", "/balancer/api/admin/teams/foobar/adjust", bytes.NewBufferString(`{"delta":-30,"reason":"shared flags with another team"}`))
+		req.Header.Set("Cookie", fmt.Sprintf("team=%s", testutil.SignTestTeamname("admin")))
+		rr := httptest.NewRecorder()
+
+		server := http.NewServeMux()
+
+		clientset := fake.NewSimpleClientset(createDeploymentForTeam("foobar", map[string]string{
+			"multi-juicer.owasp-juice.shop/challenges":      "[]",
+			"multi-juicer.owasp-juice.shop/scoreAdjustment": "10",
+		}))
+		bundle := testutil.NewTestBundleWithCustomFakeClient(clientset)
+		AddRoutes(server, bundle, nil)
+
+		server.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.JSONEq(t, `{"team":"foobar","scoreAdjustment":-20}`, rr.Body.String())
+
+		deployment, err := clientset.AppsV1().Deployments("test-namespace").Get(context.Background(), "juiceshop-foobar", metav1.GetOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, "-20", deployment.Annotations["multi-juicer.owasp-juice.shop/scoreAdjustment"])
+		// unrelated annotations are kept
+		assert.Equal(t, "[]", deployment.Annotations["multi-juicer.owasp-juice.shop/challenges"])
+	})
+
+	t.Run("concurrent adjustments don't overwrite each other", func(t *testing.T) {
+		deployment := createDeploymentForTeam("foobar", map[string]string{})
+		deployment.ResourceVersion = "1"
+		clientset := fake.NewSimpleClientset(deployment)
+		// the fake clientset ignores resource versions, this mimics the conflict detection of the api server
+		deploymentsResource := appsv1.SchemeGroupVersion.WithResource("deployments")
+		var patchMutex sync.Mutex
+		clientset.PrependReactor("patch", "deployments", func(action testcore.Action) (bool, runtime.Object, error) {
+			patchMutex.Lock()
+			defer patchMutex.Unlock()
+
+			patchAction := action.(testcore.PatchAction)
+			var patch struct {
+				Metadata metav1.ObjectMeta `json:"metadata"`
+			}
+			if err := json.Unmarshal(patchAction.GetPatch(), &patch); err != nil {
+				return true, nil, err
+			}
+			obj, err := clientset.Tracker().Get(deploymentsResource, patchAction.GetNamespace(), patchAction.GetName())
+			if err != nil {
+				return true, nil, err
+			}
+			current := obj.(*appsv1.Deployment).DeepCopy()
+			if patch.Metadata.ResourceVersion != current.ResourceVersion {
+				return true, nil, errors.NewConflict(deploymentsResource.GroupResource(), current.Name, fmt.Errorf("the object has been modified"))
+			}
+			for key, value := range patch.Metadata.Annotations {
+				current.Annotations[key] = value
+			}
+			resourceVersion, _ := strconv.Atoi(current.ResourceVersion)
+			current.ResourceVersion = strconv.Itoa(resourceVersion + 1)
+			return true, current, clientset.Tracker().Update(deploymentsResource, current, current.Namespace)
+		})
+		bundle := testutil.NewTestBundleWithCustomFakeClient(clientset)
+		server := http.NewServeMux()
+		AddRoutes(server, bundle, nil)
+
+		const concurrentAdjustments = 5
+		var wg sync.WaitGroup
+		for i := 0; i < concurrentAdjustments; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				req, _ := http.NewRequest("POST", "/balancer/api/admin/teams/foobar/adjust", bytes.NewBufferString(`{"delta":10,"reason":"found a bug"}`))
+				req.Header.Set("Cookie", fmt.Sprintf("team=%s", testutil.SignTestTeamname("admin")))
+				rr := httptest.NewRecorder()
+				server.ServeHTTP(rr, req)
+				assert.Equal(t, http.StatusOK, rr.Code)
+			}()
+		}
+		wg.Wait()
+
+		updated, err := clientset.AppsV1().Deployments("test-namespace").Get(context.Background(), "juiceshop-foobar", metav1.GetOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, "50", updated.Annotations["multi-juicer.owasp-juice.shop/scoreAdjustment"])
+	})
+}
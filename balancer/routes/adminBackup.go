@@ -0,0 +1,206 @@
+package routes
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/juice-shop/multi-juicer/balancer/pkg/bundle"
+	"github.com/juice-shop/multi-juicer/balancer/pkg/teamcookie"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+type AdminBackup struct {
+	Teams []AdminBackupTeam `json:"teams"`
+}
+
+type AdminBackupTeam struct {
+	Team       string                 `json:"team"`
+	Challenges []AdminBackupChallenge `json:"challenges"`
+	// ScoreAdjustment are the points granted or deducted by admins, see handleAdminAdjustScore. Restoring a backup without it keeps the current adjustment of the team
+	ScoreAdjustment *int `json:"scoreAdjustment,omitempty"`
+}
+
+// AdminBackupChallenge keeps solvedAt as the raw string of the annotation, so that restoring a backup writes back exactly what was there before
+type AdminBackupChallenge struct {
+	Key      string `json:"key"`
+	SolvedAt string `json:"solvedAt"`
+}
+
+// maxBackupSize limits the size of restored backups, enough for a few thousand teams which solved every challenge
+const maxBackupSize = 16 * 1024 * 1024
+
+type AdminRestoreResponse struct {
+	Results []AdminRestoreResult `json:"results"`
+}
+
+type AdminRestoreResult struct {
+	Team    string `json:"team"`
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty"`
+}
+
+// handleAdminBackup exports the solved challenges of all teams, e.g. to move an event to a new cluster
+func handleAdminBackup(bundle *bundle.Bundle) http.Handler {
+	return http.HandlerFunc(
+		func(responseWriter http.ResponseWriter, req *http.Request) {
+			team, err := teamcookie.GetTeamFromAdminRequest(bundle, req)
+			if err != nil || !bundle.IsAdminTeam(team) {
+				writeJSONError(responseWriter, http.StatusUnauthorized, "unauthorized", "admin login required")
+				return
+			}
+
+			deployments, err := bundle.ClientSet.AppsV1().Deployments(bundle.RuntimeEnvironment.Namespace).List(req.Context(), metav1.ListOptions{
+				LabelSelector: bundle.JuiceShopInstanceLabelSelector(),
+			})
+			if err != nil {
+				bundle.Log.Printf("Failed to list deployments: %s", err)
+				writeJSONError(responseWriter, http.StatusInternalServerError, "kubernetes_error", "unable to get instances")
+				return
+			}
+
+			backup := AdminBackup{Teams: []AdminBackupTeam{}}
+			for _, teamDeployment := range deployments.Items {
+				teamName := teamDeployment.Labels["team"]
+				challenges := []AdminBackupChallenge{}
+				if challengesJson := teamDeployment.Annotations["multi-juicer.owasp-juice.shop/challenges"]; challengesJson != "" {
+					if err := json.Unmarshal([]byte(challengesJson), &challenges); err != nil {
+						bundle.Log.Printf("Team '%s' has an invalid challenges annotation, backing it up without solved challenges: %s", teamName, err)
+						challenges = []AdminBackupChallenge{}
+					}
+				}
+				teamBackup := AdminBackupTeam{Team: teamName, Challenges: challenges}
+				if adjustmentString := teamDeployment.Annotations["multi-juicer.owasp-juice.shop/scoreAdjustment"]; adjustmentString != "" {
+					if adjustment, err := strconv.Atoi(adjustmentString); err == nil {
+						teamBackup.ScoreAdjustment = &adjustment
+					} else {
+						bundle.Log.Printf("Team '%s' has an invalid score adjustment '%s', backing it up without it", teamName, adjustmentString)
+					}
+				}
+				backup.Teams = append(backup.Teams, teamBackup)
+			}
+
+			responseBytes, err := json.Marshal(backup)
+			if err != nil {
+				bundle.Log.Printf("Failed to marshal backup: %s", err)
+				writeJSONError(responseWriter, http.StatusInternalServerError, "internal_error", "internal server error")
+				return
+			}
+			responseWriter.Header().Set("Content-Type", "application/json")
+			responseWriter.WriteHeader(http.StatusOK)
+			responseWriter.Write(responseBytes)
+		},
+	)
+}
+
+// handleAdminRestore writes the solved challenges of a backup back to the deployments of the teams.
+// Only restores teams which already exist. The progress-watchdog then applies the restored progress to the JuiceShop instances with its next sync
+func handleAdminRestore(bundle *bundle.Bundle) http.Handler {
+	return http.HandlerFunc(
+		func(responseWriter http.ResponseWriter, req *http.Request) {
+			team, err := teamcookie.GetTeamFromAdminRequest(bundle, req)
+			if err != nil || !bundle.IsAdminTeam(team) {
+				writeJSONError(responseWriter, http.StatusUnauthorized, "unauthorized", "admin login required")
+				return
+			}
+
+			var backup AdminBackup
+			if err := json.NewDecoder(http.MaxBytesReader(responseWriter, req.Body, maxBackupSize)).Decode(&backup); err != nil {
+				writeJSONError(responseWriter, http.StatusBadRequest, "invalid_json", "invalid json")
+				return
+			}
+
+			results := []AdminRestoreResult{}
+			for _, teamBackup := range backup.Teams {
+				err := restoreTeamProgress(req, bundle, teamBackup)
+				if err != nil {
+					bundle.Log.Printf("Failed to restore progress of team '%s': %s", teamBackup.Team, err)
+					results = append(results, AdminRestoreResult{Team: teamBackup.Team, Success: false, Error: err.Error()})
+					continue
+				}
+				results = append(results, AdminRestoreResult{Team: teamBackup.Team, Success: true})
+			}
+			bundle.Log.Printf("Restored progress of %d teams from a backup", len(results))
+
+			responseBytes, err := json.Marshal(AdminRestoreResponse{Results: results})
+			if err != nil {
+				bundle.Log.Printf("Failed to marshal restore response: %s", err)
+				writeJSONError(responseWriter, http.StatusInternalServerError, "internal_error", "internal server error")
+				return
+			}
+			responseWriter.Header().Set("Content-Type", "application/json")
+			responseWriter.WriteHeader(http.StatusOK)
+			responseWriter.Write(responseBytes)
+		},
+	)
+}
+
+func restoreTeamProgress(req *http.Request, bundle *bundle.Bundle, teamBackup AdminBackupTeam) error {
+	if !isValidTeamName(teamBackup.Team) {
+		return fmt.Errorf("invalid team name")
+	}
+
+	challenges := teamBackup.Challenges
+	if challenges == nil {
+		challenges = []AdminBackupChallenge{}
+	}
+	if err := validateBackupChallenges(bundle, challenges); err != nil {
+		return err
+	}
+	challengesJson, err := json.Marshal(challenges)
+	if err != nil {
+		return fmt.Errorf("failed to encode challenges: %w", err)
+	}
+
+	// same annotations the progress-watchdog writes when persisting the progress of a team
+	annotations := map[string]interface{}{
+		"multi-juicer.owasp-juice.shop/challenges":       string(challengesJson),
+		"multi-juicer.owasp-juice.shop/challengesSolved": fmt.Sprintf("%d", len(challenges)),
+	}
+	if teamBackup.ScoreAdjustment != nil {
+		annotations["multi-juicer.owasp-juice.shop/scoreAdjustment"] = fmt.Sprintf("%d", *teamBackup.ScoreAdjustment)
+	}
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": annotations,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode patch: %w", err)
+	}
+
+	_, err = bundle.ClientSet.AppsV1().Deployments(bundle.RuntimeEnvironment.Namespace).Patch(req.Context(), fmt.Sprintf("juiceshop-%s", teamBackup.Team), types.MergePatchType, patch, metav1.PatchOptions{})
+	if errors.IsNotFound(err) {
+		return fmt.Errorf("team not found")
+	} else if err != nil {
+		return fmt.Errorf("failed to update deployment: %w", err)
+	}
+	return nil
+}
+
+// validateBackupChallenges makes sure only challenges known to the balancer get restored, each at most once and with a valid solve time
+func validateBackupChallenges(bundle *bundle.Bundle, challenges []AdminBackupChallenge) error {
+	knownChallenges := make(map[string]bool, len(bundle.JuiceShopChallenges))
+	for _, challenge := range bundle.JuiceShopChallenges {
+		knownChallenges[challenge.Key] = true
+	}
+
+	restoredChallenges := make(map[string]bool, len(challenges))
+	for _, challenge := range challenges {
+		if !knownChallenges[challenge.Key] {
+			return fmt.Errorf("unknown challenge '%s'", challenge.Key)
+		}
+		if restoredChallenges[challenge.Key] {
+			return fmt.Errorf("duplicate challenge '%s'", challenge.Key)
+		}
+		restoredChallenges[challenge.Key] = true
+		if _, err := time.Parse(time.RFC3339, challenge.SolvedAt); err != nil {
+			return fmt.Errorf("invalid solvedAt of challenge '%s'", challenge.Key)
+		}
+	}
+	return nil
+}
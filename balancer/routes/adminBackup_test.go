@@ -0,0 +1,143 @@
+package routes
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/juice-shop/multi-juicer/balancer/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestAdminBackupHandlers(t *testing.T) {
+	createTeam := func(team string, challenges string) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      fmt.Sprintf("juiceshop-%s", team),
+				Namespace: "test-namespace",
+				Annotations: map[string]string{
+					"multi-juicer.owasp-juice.shop/challenges": challenges,
+				},
+				Labels: map[string]string{
+					"app.kubernetes.io/name":    "juice-shop",
+					"app.kubernetes.io/part-of": "multi-juicer",
+					"team":                      team,
+				},
+			},
+		}
+	}
+
+	t.Run("backup and restore require admin login", func(t *testing.T) {
+		server := http.NewServeMux()
+		clientset := fake.NewSimpleClientset(createTeam("foobar", "[]"))
+		bundle := testutil.NewTestBundleWithCustomFakeClient(clientset)
+		AddRoutes(server, bundle, nil)
+
+		req, _ := http.NewRequest("GET", "/balancer/api/admin/backup", nil)
+		req.Header.Set("Cookie", fmt.Sprintf("team=%s", testutil.SignTestTeamname("foobar")))
+		rr := httptest.NewRecorder()
+		server.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+
+		req, _ = http.NewRequest("POST", "/balancer/api/admin/restore", bytes.NewBufferString(`{"teams":[]}`))
+		req.Header.Set("Cookie", fmt.Sprintf("team=%s", testutil.SignTestTeamname("foobar")))
+		rr = httptest.NewRecorder()
+		server.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+		assert.Len(t, clientset.Actions(), 0)
+	})
+
+	t.Run("backup contains the solved challenges of all teams", func(t *testing.T) {
+		server := http.NewServeMux()
+		adjustedTeam := createTeam("foobar", `[{"key":"scoreBoardChallenge","solvedAt":"2024-11-01T19:55:48.211Z"}]`)
+		adjustedTeam.Annotations["multi-juicer.owasp-juice.shop/scoreAdjustment"] = "-20"
+		clientset := fake.NewSimpleClientset(
+			adjustedTeam,
+			createTeam("barfoo", ""),
+		)
+		bundle := testutil.NewTestBundleWithCustomFakeClient(clientset)
+		AddRoutes(server, bundle, nil)
+
+		req, _ := http.NewRequest("GET", "/balancer/api/admin/backup", nil)
+		req.Header.Set("Cookie", fmt.Sprintf("team=%s", testutil.SignTestTeamname("admin")))
+		rr := httptest.NewRecorder()
+		server.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		var backup AdminBackup
+		adjustment := -20
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &backup))
+		assert.ElementsMatch(t, []AdminBackupTeam{
+			{Team: "foobar", Challenges: []AdminBackupChallenge{{Key: "scoreBoardChallenge", SolvedAt: "2024-11-01T19:55:48.211Z"}}, ScoreAdjustment: &adjustment},
+			{Team: "barfoo", Challenges: []AdminBackupChallenge{}},
+		}, backup.Teams)
+	})
+
+	t.Run("restore writes the progress back and reports failures per team", func(t *testing.T) {
+		server := http.NewServeMux()
+		clientset := fake.NewSimpleClientset(createTeam("foobar", "[]"), createTeam("barfoo", "[]"))
+		bundle := testutil.NewTestBundleWithCustomFakeClient(clientset)
+		AddRoutes(server, bundle, nil)
+
+		backup := `{"teams":[
+			{"team":"foobar","challenges":[{"key":"scoreBoardChallenge","solvedAt":"2024-11-01T19:55:48.211Z"}],"scoreAdjustment":-20},
+			{"team":"barfoo","challenges":[{"key":"scoreBoardChallenge","solvedAt":"2024-11-01T19:55:48.211Z"}]},
+			{"team":"missing-team","challenges":[]},
+			{"team":"Invalid Name","challenges":[]},
+			{"team":"unknown","challenges":[{"key":"notAChallenge","solvedAt":"2024-11-01T19:55:48.211Z"}]},
+			{"team":"duplicate","challenges":[{"key":"scoreBoardChallenge","solvedAt":"2024-11-01T19:55:48.211Z"},{"key":"scoreBoardChallenge","solvedAt":"2024-11-01T19:55:48.211Z"}]},
+			{"team":"bad-time","challenges":[{"key":"scoreBoardChallenge","solvedAt":"yesterday"}]}
+		]}`
+		req, _ := http.NewRequest("POST", "/balancer/api/admin/restore", bytes.NewBufferString(backup))
+		req.Header.Set("Cookie", fmt.Sprintf("team=%s", testutil.SignTestTeamname("admin")))
+		rr := httptest.NewRecorder()
+		server.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		var response AdminRestoreResponse
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		assert.Equal(t, []AdminRestoreResult{
+			{Team: "foobar", Success: true},
+			{Team: "barfoo", Success: true},
+			{Team: "missing-team", Success: false, Error: "team not found"},
+			{Team: "Invalid Name", Success: false, Error: "invalid team name"},
+			{Team: "unknown", Success: false, Error: "unknown challenge 'notAChallenge'"},
+			{Team: "duplicate", Success: false, Error: "duplicate challenge 'scoreBoardChallenge'"},
+			{Team: "bad-time", Success: false, Error: "invalid solvedAt of challenge 'scoreBoardChallenge'"},
+		}, response.Results)
+
+		deployment, err := clientset.AppsV1().Deployments("test-namespace").Get(context.Background(), "juiceshop-foobar", metav1.GetOptions{})
+		assert.NoError(t, err)
+		assert.JSONEq(t, `[{"key":"scoreBoardChallenge","solvedAt":"2024-11-01T19:55:48.211Z"}]`, deployment.Annotations["multi-juicer.owasp-juice.shop/challenges"])
+		assert.Equal(t, "1", deployment.Annotations["multi-juicer.owasp-juice.shop/challengesSolved"])
+		assert.Equal(t, "-20", deployment.Annotations["multi-juicer.owasp-juice.shop/scoreAdjustment"])
+
+		deployment, err = clientset.AppsV1().Deployments("test-namespace").Get(context.Background(), "juiceshop-barfoo", metav1.GetOptions{})
+		assert.NoError(t, err)
+		_, hasAdjustment := deployment.Annotations["multi-juicer.owasp-juice.shop/scoreAdjustment"]
+		assert.False(t, hasAdjustment, "Backups without an adjustment should keep the current one")
+	})
+
+	t.Run("restore rejects backups which are too large", func(t *testing.T) {
+		server := http.NewServeMux()
+		clientset := fake.NewSimpleClientset()
+		bundle := testutil.NewTestBundleWithCustomFakeClient(clientset)
+		AddRoutes(server, bundle, nil)
+
+		req, _ := http.NewRequest("POST", "/balancer/api/admin/restore", bytes.NewBufferString(`{"teams":[`+strings.Repeat(" ", maxBackupSize)+`]}`))
+		req.Header.Set("Cookie", fmt.Sprintf("team=%s", testutil.SignTestTeamname("admin")))
+		rr := httptest.NewRecorder()
+		server.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Len(t, clientset.Actions(), 0)
+	})
+}
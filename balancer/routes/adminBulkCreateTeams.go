@@ -0,0 +1,161 @@
+package routes
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+
+	b "github.com/juice-shop/multi-juicer/balancer/pkg/bundle"
+	"github.com/juice-shop/multi-juicer/balancer/pkg/teamcookie"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// maxBulkCreateTeams limits how many teams can be created with a single request
+const maxBulkCreateTeams = 500
+
+// maxConcurrentBulkCreations bounds how many teams get created at the same time, so that pre-seeding an event doesn't overwhelm the kubernetes api
+const maxConcurrentBulkCreations = 10
+
+const (
+	bulkCreateStatusCreated = "created"
+	bulkCreateStatusSkipped = "skipped"
+	bulkCreateStatusFailed  = "failed"
+)
+
+type AdminBulkCreateTeamsResponse struct {
+	Teams []BulkCreatedTeam `json:"teams"`
+}
+
+// BulkCreatedTeam is the result for a single team of a bulk creation, in the order of the requested team names
+type BulkCreatedTeam struct {
+	Team string `json:"team"`
+	// Status is either "created", "skipped" (team already exists or is listed twice) or "failed"
+	Status string `json:"status"`
+	// Passcode is only set for created teams, it's the only time the passcode gets returned
+	Passcode string `json:"passcode,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// handleAdminBulkCreateTeams pre-creates the instances of the teams in the request body (a json array of team names), e.g. before an event. Existing teams are skipped
+func handleAdminBulkCreateTeams(bundle *b.Bundle) http.Handler {
+	return http.HandlerFunc(
+		func(responseWriter http.ResponseWriter, req *http.Request) {
+			team, err := teamcookie.GetTeamFromAdminRequest(bundle, req)
+			if err != nil || !bundle.IsAdminTeam(team) {
+				writeJSONError(responseWriter, http.StatusUnauthorized, "unauthorized", "admin login required")
+				return
+			}
+
+			var teams []string
+			if err := json.NewDecoder(http.MaxBytesReader(responseWriter, req.Body, maxBulkCreateTeams*32)).Decode(&teams); err != nil {
+				writeJSONError(responseWriter, http.StatusBadRequest, "invalid_body", "body must be a json array of team names")
+				return
+			}
+			if len(teams) == 0 || len(teams) > maxBulkCreateTeams {
+				writeJSONError(responseWriter, http.StatusBadRequest, "invalid_body", "between 1 and 500 teams can be created at once")
+				return
+			}
+
+			deployments, err := bundle.ClientSet.AppsV1().Deployments(bundle.RuntimeEnvironment.Namespace).List(req.Context(), metav1.ListOptions{
+				LabelSelector: bundle.JuiceShopInstanceLabelSelector(),
+			})
+			if err != nil {
+				bundle.Log.Printf("Failed to list deployments for the bulk team creation: %s", err)
+				writeJSONError(responseWriter, http.StatusInternalServerError, "kubernetes_error", "unable to get instances")
+				return
+			}
+			knownTeams := make(map[string]bool, len(deployments.Items)+len(teams))
+			for _, deployment := range deployments.Items {
+				knownTeams[deployment.Labels["team"]] = true
+			}
+			instanceCount := len(deployments.Items)
+
+			results := make([]BulkCreatedTeam, len(teams))
+			toCreate := []int{}
+			for i, name := range teams {
+				results[i] = BulkCreatedTeam{Team: name}
+				switch {
+				case !isValidTeamName(name) || bundle.IsAdminTeam(name):
+					results[i].Status = bulkCreateStatusFailed
+					results[i].Error = "invalid team name"
+				case knownTeams[name]:
+					results[i].Status = bulkCreateStatusSkipped
+				case instanceCount+1 >= bundle.Config.MaxInstances:
+					// same limit as for teams joining on their own, see isMaxInstanceLimitReached
+					results[i].Status = bulkCreateStatusFailed
+					results[i].Error = "max instance limit reached"
+				default:
+					knownTeams[name] = true
+					instanceCount++
+					toCreate = append(toCreate, i)
+				}
+			}
+
+			if len(toCreate) > 0 {
+				// resolves the cached owner reference up front, so that the concurrent creations only read it
+				if _, err := getOwnerReferences(req.Context(), bundle); err != nil {
+					bundle.Log.Printf("Failed to get the owner reference for the bulk team creation: %s", err)
+					writeJSONError(responseWriter, http.StatusInternalServerError, "kubernetes_error", "unable to get the balancer deployment")
+					return
+				}
+			}
+
+			slots := make(chan struct{}, maxConcurrentBulkCreations)
+			var wg sync.WaitGroup
+			for _, i := range toCreate {
+				wg.Add(1)
+				slots <- struct{}{}
+				go func(result *BulkCreatedTeam) {
+					defer wg.Done()
+					defer func() { <-slots }()
+					createTeamForBulkCreation(req, bundle, result)
+				}(&results[i])
+			}
+			wg.Wait()
+
+			created := 0
+			for _, result := range results {
+				if result.Status == bulkCreateStatusCreated {
+					created++
+				}
+			}
+			bundle.Log.Printf("Admin '%s' created %d of %d team(s) in bulk", team, created, len(teams))
+
+			responseBytes, err := json.Marshal(AdminBulkCreateTeamsResponse{Teams: results})
+			if err != nil {
+				bundle.Log.Printf("Failed to marshal response: %s", err)
+				writeJSONError(responseWriter, http.StatusInternalServerError, "internal_error", "failed to encode response")
+				return
+			}
+
+			responseWriter.Header().Set("Content-Type", "application/json")
+			responseWriter.WriteHeader(http.StatusOK)
+			responseWriter.Write(responseBytes)
+		},
+	)
+}
+
+func createTeamForBulkCreation(req *http.Request, bundle *b.Bundle, result *BulkCreatedTeam) {
+	passcode, passcodeHash, err := generatePasscode(bundle)
+	if err != nil {
+		bundle.Log.Printf("Failed to hash passcode!: %s", err)
+		result.Status = bulkCreateStatusFailed
+		result.Error = "failed to generate passcode"
+		return
+	}
+	if err := createDeploymentForTeam(req.Context(), bundle, result.Team, passcodeHash); err != nil {
+		bundle.Log.Printf("Failed to create deployment of team '%s': %s", result.Team, err)
+		result.Status = bulkCreateStatusFailed
+		result.Error = "failed to create deployment"
+		return
+	}
+	if err := createServiceForTeam(req.Context(), bundle, result.Team); err != nil {
+		bundle.Log.Printf("Failed to create service of team '%s': %s", result.Team, err)
+		result.Status = bulkCreateStatusFailed
+		result.Error = "failed to create service"
+		return
+	}
+	result.Status = bulkCreateStatusCreated
+	result.Passcode = passcode
+	loginCounter.WithLabelValues("registration", "user").Inc()
+}
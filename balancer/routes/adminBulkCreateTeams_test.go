@@ -0,0 +1,136 @@
+package routes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/juice-shop/multi-juicer/balancer/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	testcore "k8s.io/client-go/testing"
+)
+
+func TestAdminBulkCreateTeamsHandler(t *testing.T) {
+	createTeam := func(team string) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      fmt.Sprintf("juiceshop-%s", team),
+				Namespace: "test-namespace",
+				Labels: map[string]string{
+					"app.kubernetes.io/name":    "juice-shop",
+					"app.kubernetes.io/part-of": "multi-juicer",
+					"team":                      team,
+				},
+			},
+		}
+	}
+	balancerDeployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "balancer",
+			Namespace: "test-namespace",
+			UID:       "34c0bb8a-240b-4f2a-84ae-2eb2258298f9",
+		},
+	}
+
+	// the uid of the balancer deployment is cached across requests
+	t.Cleanup(func() { deploymentUid = "" })
+
+	bulkCreate := func(clientset *fake.Clientset, maxInstances int, team string, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("POST", "/balancer/api/admin/teams/bulk", strings.NewReader(body))
+		req.Header.Set("Cookie", fmt.Sprintf("team=%s", testutil.SignTestTeamname(team)))
+		rr := httptest.NewRecorder()
+		bundle := testutil.NewTestBundleWithCustomFakeClient(clientset)
+		bundle.Config.MaxInstances = maxInstances
+		server := http.NewServeMux()
+		AddRoutes(server, bundle, nil)
+		server.ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("creates the teams and reports the result of each team", func(t *testing.T) {
+		clientset := fake.NewSimpleClientset(balancerDeployment, createTeam("existing"))
+
+		rr := bulkCreate(clientset, 100, "admin", `["team-1","existing","Not Valid","team-2","team-1","admin"]`)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		var response AdminBulkCreateTeamsResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		assert.Equal(t, []BulkCreatedTeam{
+			{Team: "team-1", Status: "created", Passcode: "12345678"},
+			{Team: "existing", Status: "skipped"},
+			{Team: "Not Valid", Status: "failed", Error: "invalid team name"},
+			{Team: "team-2", Status: "created", Passcode: "12345678"},
+			{Team: "team-1", Status: "skipped"},
+			{Team: "admin", Status: "failed", Error: "invalid team name"},
+		}, response.Teams)
+
+		for _, team := range []string{"team-1", "team-2"} {
+			_, err := clientset.AppsV1().Deployments("test-namespace").Get(context.Background(), fmt.Sprintf("juiceshop-%s", team), metav1.GetOptions{})
+			assert.NoError(t, err)
+			_, err = clientset.CoreV1().Services("test-namespace").Get(context.Background(), fmt.Sprintf("juiceshop-%s", team), metav1.GetOptions{})
+			assert.NoError(t, err)
+		}
+	})
+
+	t.Run("stops creating teams once the max instance limit is reached", func(t *testing.T) {
+		clientset := fake.NewSimpleClientset(balancerDeployment, createTeam("existing"))
+
+		rr := bulkCreate(clientset, 3, "admin", `["team-1","team-2","team-3"]`)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		var response AdminBulkCreateTeamsResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		assert.Equal(t, []BulkCreatedTeam{
+			{Team: "team-1", Status: "created", Passcode: "12345678"},
+			{Team: "team-2", Status: "failed", Error: "max instance limit reached"},
+			{Team: "team-3", Status: "failed", Error: "max instance limit reached"},
+		}, response.Teams)
+	})
+
+	t.Run("reports teams which couldn't be created", func(t *testing.T) {
+		clientset := fake.NewSimpleClientset(balancerDeployment)
+		clientset.PrependReactor("create", "services", func(action testcore.Action) (bool, runtime.Object, error) {
+			return true, nil, fmt.Errorf("services are forbidden")
+		})
+
+		rr := bulkCreate(clientset, 100, "admin", `["team-1"]`)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.JSONEq(t, `{"teams":[{"team":"team-1","status":"failed","error":"failed to create service"}]}`, rr.Body.String())
+	})
+
+	t.Run("rejects invalid bodies", func(t *testing.T) {
+		clientset := fake.NewSimpleClientset(balancerDeployment)
+
+		rr := bulkCreate(clientset, 100, "admin", `{"teams":["team-1"]}`)
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		rr = bulkCreate(clientset, 100, "admin", `[]`)
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+		teams := make([]string, maxBulkCreateTeams+1)
+		for i := 0; i < len(teams); i++ {
+			teams[i] = fmt.Sprintf("team-%d", i)
+		}
+		body, _ := json.Marshal(teams)
+		rr = bulkCreate(clientset, 1000, "admin", string(body))
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("requires an admin login", func(t *testing.T) {
+		clientset := fake.NewSimpleClientset(balancerDeployment)
+
+		rr := bulkCreate(clientset, 100, "foobar", `["team-1"]`)
+
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+		assert.JSONEq(t, `{"error":"admin login required","code":"unauthorized"}`, rr.Body.String())
+	})
+}
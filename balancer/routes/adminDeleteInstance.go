@@ -13,28 +13,28 @@ import (
 func handleAdminDeleteInstance(bundle *bundle.Bundle) http.Handler {
 	return http.HandlerFunc(
 		func(responseWriter http.ResponseWriter, req *http.Request) {
-			team, err := teamcookie.GetTeamFromRequest(bundle, req)
-			if err != nil || team != "admin" {
-				http.Error(responseWriter, "", http.StatusUnauthorized)
+			team, err := teamcookie.GetTeamFromAdminRequest(bundle, req)
+			if err != nil || !bundle.IsAdminTeam(team) {
+				writeJSONError(responseWriter, http.StatusUnauthorized, "unauthorized", "admin login required")
 				return
 			}
 
 			teamToDelete := req.PathValue("team")
 			if !isValidTeamName(teamToDelete) {
-				http.Error(responseWriter, "invalid team name", http.StatusBadRequest)
+				writeJSONError(responseWriter, http.StatusBadRequest, "invalid_team_name", "invalid team name")
 				return
 			}
 
 			err = bundle.ClientSet.AppsV1().Deployments(bundle.RuntimeEnvironment.Namespace).Delete(req.Context(), fmt.Sprintf("juiceshop-%s", teamToDelete), metav1.DeleteOptions{})
 			if err != nil && !errors.IsNotFound(err) {
 				bundle.Log.Printf("Failed to delete deployment for team '%s': %s", teamToDelete, err)
-				http.Error(responseWriter, "", http.StatusInternalServerError)
+				writeJSONError(responseWriter, http.StatusInternalServerError, "internal_error", "internal server error")
 				return
 			}
 			err = bundle.ClientSet.CoreV1().Services(bundle.RuntimeEnvironment.Namespace).Delete(req.Context(), fmt.Sprintf("juiceshop-%s", teamToDelete), metav1.DeleteOptions{})
 			if err != nil && !errors.IsNotFound(err) {
 				bundle.Log.Printf("Failed to delete service for team '%s': %s", teamToDelete, err)
-				http.Error(responseWriter, "", http.StatusInternalServerError)
+				writeJSONError(responseWriter, http.StatusInternalServerError, "internal_error", "internal server error")
 				return
 			}
 
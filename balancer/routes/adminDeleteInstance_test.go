@@ -75,7 +75,7 @@ func TestAdminDeleteInstanceHandler(t *testing.T) {
 		server.ServeHTTP(rr, req)
 
 		assert.Equal(t, http.StatusUnauthorized, rr.Code)
-		assert.Equal(t, "\n", rr.Body.String())
+		assert.JSONEq(t, `{"error":"admin login required","code":"unauthorized"}`, rr.Body.String())
 	})
 
 	t.Run("rejects invalid team names", func(t *testing.T) {
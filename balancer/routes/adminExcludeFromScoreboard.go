@@ -0,0 +1,74 @@
+package routes
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/juice-shop/multi-juicer/balancer/pkg/bundle"
+	"github.com/juice-shop/multi-juicer/balancer/pkg/scoring"
+	"github.com/juice-shop/multi-juicer/balancer/pkg/teamcookie"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+type AdminExcludeFromScoreboardRequest struct {
+	Excluded bool `json:"excluded"`
+}
+
+// handleAdminExcludeFromScoreboard marks a team as practice / staff team, which is left off the public scoreboard but still visible to admins. Setting excluded to false puts the team back on the scoreboard
+func handleAdminExcludeFromScoreboard(bundle *bundle.Bundle) http.Handler {
+	return http.HandlerFunc(
+		func(responseWriter http.ResponseWriter, req *http.Request) {
+			team, err := teamcookie.GetTeamFromAdminRequest(bundle, req)
+			if err != nil || !bundle.IsAdminTeam(team) {
+				writeJSONError(responseWriter, http.StatusUnauthorized, "unauthorized", "admin login required")
+				return
+			}
+
+			teamToExclude := req.PathValue("team")
+			if !isValidTeamName(teamToExclude) {
+				writeJSONError(responseWriter, http.StatusBadRequest, "invalid_team_name", "invalid team name")
+				return
+			}
+
+			var body AdminExcludeFromScoreboardRequest
+			if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+				writeJSONError(responseWriter, http.StatusBadRequest, "invalid_json", "invalid request body")
+				return
+			}
+
+			// a null value removes the annotation in a merge patch
+			var excluded interface{}
+			if body.Excluded {
+				excluded = "true"
+			}
+			patch, err := json.Marshal(map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"annotations": map[string]interface{}{
+						scoring.ExcludeFromScoreboardAnnotation: excluded,
+					},
+				},
+			})
+			if err != nil {
+				writeJSONError(responseWriter, http.StatusInternalServerError, "internal_error", "internal server error")
+				return
+			}
+
+			_, err = bundle.ClientSet.AppsV1().Deployments(bundle.RuntimeEnvironment.Namespace).Patch(req.Context(), fmt.Sprintf("juiceshop-%s", teamToExclude), types.MergePatchType, patch, metav1.PatchOptions{})
+			if errors.IsNotFound(err) {
+				writeJSONError(responseWriter, http.StatusNotFound, "team_not_found", "team not found")
+				return
+			} else if err != nil {
+				bundle.Log.Printf("Failed to update the scoreboard exclusion of team '%s': %s", teamToExclude, err)
+				writeJSONError(responseWriter, http.StatusInternalServerError, "internal_error", "internal server error")
+				return
+			}
+			bundle.Log.Printf("Set scoreboard exclusion of team '%s' to %t", teamToExclude, body.Excluded)
+
+			responseWriter.WriteHeader(http.StatusOK)
+			responseWriter.Write([]byte{})
+		},
+	)
+}
@@ -0,0 +1,82 @@
+package routes
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/juice-shop/multi-juicer/balancer/pkg/scoring"
+	"github.com/juice-shop/multi-juicer/balancer/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestAdminExcludeFromScoreboardHandler(t *testing.T) {
+	createTeam := func(team string, annotations map[string]string) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        fmt.Sprintf("juiceshop-%s", team),
+				Namespace:   "test-namespace",
+				Annotations: annotations,
+				Labels: map[string]string{
+					"app.kubernetes.io/name":    "juice-shop",
+					"app.kubernetes.io/part-of": "multi-juicer",
+					"team":                      team,
+				},
+			},
+		}
+	}
+	setExcluded := func(clientset *fake.Clientset, cookieTeam string, team string, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("PUT", fmt.Sprintf("/balancer/api/admin/teams/%s/exclude-from-scoreboard", team), strings.NewReader(body))
+		req.Header.Set("Cookie", fmt.Sprintf("team=%s", testutil.SignTestTeamname(cookieTeam)))
+		rr := httptest.NewRecorder()
+
+		server := http.NewServeMux()
+		bundle := testutil.NewTestBundleWithCustomFakeClient(clientset)
+		AddRoutes(server, bundle, nil)
+		server.ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("requires admin login", func(t *testing.T) {
+		clientset := fake.NewSimpleClientset(createTeam("foobar", map[string]string{}))
+		rr := setExcluded(clientset, "foobar", "foobar", `{"excluded":true}`)
+
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+		assert.Len(t, clientset.Actions(), 0)
+	})
+
+	t.Run("returns 404 for teams which don't exist", func(t *testing.T) {
+		rr := setExcluded(fake.NewSimpleClientset(), "admin", "foobar", `{"excluded":true}`)
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("rejects invalid bodies", func(t *testing.T) {
+		clientset := fake.NewSimpleClientset(createTeam("foobar", map[string]string{}))
+		rr := setExcluded(clientset, "admin", "foobar", `not json`)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Len(t, clientset.Actions(), 0)
+	})
+
+	t.Run("sets and removes the exclusion annotation", func(t *testing.T) {
+		clientset := fake.NewSimpleClientset(createTeam("foobar", map[string]string{}))
+
+		rr := setExcluded(clientset, "admin", "foobar", `{"excluded":true}`)
+		assert.Equal(t, http.StatusOK, rr.Code)
+		deployment, err := clientset.AppsV1().Deployments("test-namespace").Get(context.Background(), "juiceshop-foobar", metav1.GetOptions{})
+		assert.Nil(t, err)
+		assert.Equal(t, "true", deployment.Annotations[scoring.ExcludeFromScoreboardAnnotation])
+
+		rr = setExcluded(clientset, "admin", "foobar", `{"excluded":false}`)
+		assert.Equal(t, http.StatusOK, rr.Code)
+		deployment, err = clientset.AppsV1().Deployments("test-namespace").Get(context.Background(), "juiceshop-foobar", metav1.GetOptions{})
+		assert.Nil(t, err)
+		assert.NotContains(t, deployment.Annotations, scoring.ExcludeFromScoreboardAnnotation)
+	})
+}
@@ -0,0 +1,79 @@
+package routes
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/juice-shop/multi-juicer/balancer/pkg/bundle"
+	"github.com/juice-shop/multi-juicer/balancer/pkg/scoring"
+	"github.com/juice-shop/multi-juicer/balancer/pkg/teamcookie"
+)
+
+// handleAdminInstancesStream pushes the instance list as server-sent events. The list is sent once on connect and again whenever the scoring watcher sees a JuiceShop deployment get added, modified or deleted
+func handleAdminInstancesStream(bundle *bundle.Bundle, scoringService *scoring.ScoringService) http.Handler {
+	return http.HandlerFunc(
+		func(responseWriter http.ResponseWriter, req *http.Request) {
+			team, err := teamcookie.GetTeamFromAdminRequest(bundle, req)
+			if err != nil || !bundle.IsAdminTeam(team) {
+				writeJSONError(responseWriter, http.StatusUnauthorized, "unauthorized", "admin login required")
+				return
+			}
+			flusher, ok := responseWriter.(http.Flusher)
+			if !ok {
+				writeJSONError(responseWriter, http.StatusInternalServerError, "streaming_unsupported", "streaming is not supported")
+				return
+			}
+
+			// read before listing, so that changes happening while the list gets fetched trigger another event
+			lastSeenChanges := scoringService.DeploymentChanges()
+			instances, err := listAdminInstances(req.Context(), bundle)
+			if err != nil {
+				bundle.Log.Printf("Failed to list deployments: %s", err)
+				writeKubernetesListError(responseWriter, bundle, err)
+				return
+			}
+
+			responseWriter.Header().Set("Content-Type", "text/event-stream")
+			responseWriter.Header().Set("Cache-Control", "no-cache")
+			responseWriter.WriteHeader(http.StatusOK)
+
+			for {
+				if err := writeInstancesEvent(responseWriter, instances); err != nil {
+					return
+				}
+				flusher.Flush()
+
+				changed := false
+				for !changed {
+					lastSeenChanges, changed = scoringService.WaitForDeploymentChanges(req.Context(), lastSeenChanges)
+					if req.Context().Err() != nil {
+						return
+					}
+					if !changed {
+						// comment lines keep proxies from closing the idle connection
+						if _, err := fmt.Fprint(responseWriter, ": keep-alive\n\n"); err != nil {
+							return
+						}
+						flusher.Flush()
+					}
+				}
+
+				instances, err = listAdminInstances(req.Context(), bundle)
+				if err != nil {
+					bundle.Log.Printf("Failed to list deployments for the instance stream: %s", err)
+					return
+				}
+			}
+		},
+	)
+}
+
+func writeInstancesEvent(responseWriter http.ResponseWriter, instances []AdminListJuiceShopInstance) error {
+	data, err := json.Marshal(AdminListInstancesResponse{Instances: instances})
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(responseWriter, "event: instances\ndata: %s\n\n", data)
+	return err
+}
@@ -0,0 +1,108 @@
+package routes
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/juice-shop/multi-juicer/balancer/pkg/scoring"
+	"github.com/juice-shop/multi-juicer/balancer/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes/fake"
+	testcore "k8s.io/client-go/testing"
+)
+
+func TestAdminInstancesStreamHandler(t *testing.T) {
+	createTeam := func(team string) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      fmt.Sprintf("juiceshop-%s", team),
+				Namespace: "test-namespace",
+				Annotations: map[string]string{
+					"multi-juicer.owasp-juice.shop/challenges":       "[]",
+					"multi-juicer.owasp-juice.shop/challengesSolved": "0",
+				},
+				Labels: map[string]string{
+					"app.kubernetes.io/name":    "juice-shop",
+					"app.kubernetes.io/part-of": "multi-juicer",
+					"team":                      team,
+				},
+			},
+		}
+	}
+
+	t.Run("streaming instances requires admin login", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/balancer/api/admin/instances/stream", nil)
+		req.Header.Set("Cookie", fmt.Sprintf("team=%s", testutil.SignTestTeamname("some team")))
+		rr := httptest.NewRecorder()
+
+		server := http.NewServeMux()
+		bundle := testutil.NewTestBundle()
+		AddRoutes(server, bundle, scoring.NewScoringService(bundle))
+
+		server.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+
+	t.Run("pushes the instance list on connect and whenever a deployment changes", func(t *testing.T) {
+		clientset := fake.NewSimpleClientset(createTeam("foobar"))
+		watchStarted := make(chan struct{})
+		var watchStartedOnce sync.Once
+		clientset.PrependWatchReactor("deployments", func(action testcore.Action) (bool, watch.Interface, error) {
+			watchStartedOnce.Do(func() { close(watchStarted) })
+			// not handled, the default reactor of the fake clientset creates the watch
+			return false, nil, nil
+		})
+		bundle := testutil.NewTestBundleWithCustomFakeClient(clientset)
+		scoringService := scoring.NewScoringService(bundle)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go scoringService.StartingScoringWorker(ctx)
+		<-watchStarted
+
+		router := http.NewServeMux()
+		AddRoutes(router, bundle, scoringService)
+		server := httptest.NewServer(router)
+		defer server.Close()
+
+		req, _ := http.NewRequestWithContext(ctx, "GET", server.URL+"/balancer/api/admin/instances/stream", nil)
+		req.Header.Set("Cookie", fmt.Sprintf("team=%s", testutil.SignTestTeamname("admin")))
+		res, err := http.DefaultClient.Do(req)
+		assert.Nil(t, err)
+		defer res.Body.Close()
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, "text/event-stream", res.Header.Get("Content-Type"))
+
+		reader := bufio.NewReader(res.Body)
+		readEventData := func() string {
+			for {
+				line, err := reader.ReadString('\n')
+				assert.Nil(t, err)
+				if strings.HasPrefix(line, "data: ") {
+					return strings.TrimPrefix(strings.TrimSpace(line), "data: ")
+				}
+			}
+		}
+
+		initial := readEventData()
+		assert.Contains(t, initial, `"team":"foobar"`)
+		assert.NotContains(t, initial, `"team":"barfoo"`)
+
+		_, err = clientset.AppsV1().Deployments("test-namespace").Create(ctx, createTeam("barfoo"), metav1.CreateOptions{})
+		assert.Nil(t, err)
+
+		updated := readEventData()
+		assert.Contains(t, updated, `"team":"foobar"`)
+		assert.Contains(t, updated, `"team":"barfoo"`)
+	})
+}
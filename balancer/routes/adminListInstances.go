@@ -1,13 +1,18 @@
 package routes
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"strconv"
 	"time"
 
 	"github.com/juice-shop/multi-juicer/balancer/pkg/bundle"
+	"github.com/juice-shop/multi-juicer/balancer/pkg/scoring"
 	"github.com/juice-shop/multi-juicer/balancer/pkg/teamcookie"
+	appsv1 "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -20,56 +25,98 @@ type AdminListJuiceShopInstance struct {
 	Ready       bool   `json:"ready"`
 	CreatedAt   int64  `json:"createdAt"`
 	LastConnect int64  `json:"lastConnect"`
+	// ParkedAt is set if the instance got parked (scaled down) by an admin
+	ParkedAt string `json:"parkedAt,omitempty"`
+	// ExcludedFromScoreboard is set for practice / staff teams which don't show up on the public scoreboard
+	ExcludedFromScoreboard bool `json:"excludedFromScoreboard,omitempty"`
+	// CPUMillicores and MemoryBytes are only set if the metrics server is installed in the cluster
+	CPUMillicores *int64 `json:"cpuMillicores,omitempty"`
+	MemoryBytes   *int64 `json:"memoryBytes,omitempty"`
 }
 
 func handleAdminListInstances(bundle *bundle.Bundle) http.Handler {
 	return http.HandlerFunc(
 		func(responseWriter http.ResponseWriter, req *http.Request) {
-			team, err := teamcookie.GetTeamFromRequest(bundle, req)
-			if err != nil || team != "admin" {
-				http.Error(responseWriter, "", http.StatusUnauthorized)
+			team, err := teamcookie.GetTeamFromAdminRequest(bundle, req)
+			if err != nil || !bundle.IsAdminTeam(team) {
+				writeJSONError(responseWriter, http.StatusUnauthorized, "unauthorized", "admin login required")
 				return
 			}
 
-			deployments, err := bundle.ClientSet.AppsV1().Deployments(bundle.RuntimeEnvironment.Namespace).List(req.Context(), metav1.ListOptions{
-				LabelSelector: "app.kubernetes.io/name=juice-shop,app.kubernetes.io/part-of=multi-juicer",
-			})
+			instances, err := listAdminInstances(req.Context(), bundle)
 			if err != nil {
 				bundle.Log.Printf("Failed to list deployments: %s", err)
-				http.Error(responseWriter, "unable to get instances", http.StatusInternalServerError)
+				writeKubernetesListError(responseWriter, bundle, err)
 				return
 			}
 
-			instances := []AdminListJuiceShopInstance{}
-			for _, teamDeployment := range deployments.Items {
-
-				lastConnectAnnotation := teamDeployment.Annotations["multi-juicer.owasp-juice.shop/lastRequest"]
-				lastConnection := time.UnixMilli(0)
-
-				if lastConnectAnnotation != "" {
-					millis, err := strconv.ParseInt(lastConnectAnnotation, 10, 64)
-					if err != nil {
-						millis = 0
-					}
-					lastConnection = time.UnixMilli(millis)
-				}
-
-				instances = append(instances, AdminListJuiceShopInstance{
-					Team:        teamDeployment.Labels["team"],
-					Ready:       teamDeployment.Status.ReadyReplicas == 1,
-					CreatedAt:   teamDeployment.CreationTimestamp.UnixMilli(),
-					LastConnect: lastConnection.UnixMilli(),
-				})
-			}
-
 			response := AdminListInstancesResponse{
 				Instances: instances,
 			}
 
 			responseBody, _ := json.Marshal(response)
 			responseWriter.Header().Set("Content-Type", "application/json")
-			responseWriter.WriteHeader(http.StatusOK)
-			responseWriter.Write(responseBody)
+			writeCompressedResponse(responseWriter, req, responseBody)
 		},
 	)
 }
+
+// listAdminInstances lists the JuiceShop instances of all teams, including their resource usage if the metrics server is installed
+func listAdminInstances(ctx context.Context, bundle *bundle.Bundle) ([]AdminListJuiceShopInstance, error) {
+	deployments, err := bundle.ClientSet.AppsV1().Deployments(bundle.RuntimeEnvironment.Namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: bundle.JuiceShopInstanceLabelSelector(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// metrics are optional, instances get listed without their resource usage if the metrics server isn't installed or doesn't respond in time
+	resourceUsage, _ := bundle.GetJuiceShopResourceUsage(ctx, bundle)
+
+	instances := []AdminListJuiceShopInstance{}
+	for _, teamDeployment := range deployments.Items {
+		instance := toAdminListJuiceShopInstance(bundle, teamDeployment)
+		if usage, ok := resourceUsage[instance.Team]; ok {
+			instance.CPUMillicores = &usage.CPUMillicores
+			instance.MemoryBytes = &usage.MemoryBytes
+		}
+		instances = append(instances, instance)
+	}
+	return instances, nil
+}
+
+// writeKubernetesListError tells permission errors apart from transient ones, as a misconfigured RBAC role is the most common setup issue and won't go away by retrying
+func writeKubernetesListError(responseWriter http.ResponseWriter, bundle *bundle.Bundle, err error) {
+	switch {
+	case apierrors.IsForbidden(err):
+		writeJSONError(responseWriter, http.StatusInternalServerError, "kubernetes_forbidden", fmt.Sprintf("the balancer isn't allowed to list deployments in namespace '%s'. Check that its RBAC role grants the 'list' verb on deployments", bundle.RuntimeEnvironment.Namespace))
+	case apierrors.IsServerTimeout(err), apierrors.IsTimeout(err), apierrors.IsTooManyRequests(err), apierrors.IsServiceUnavailable(err):
+		writeJSONError(responseWriter, http.StatusServiceUnavailable, "kubernetes_unavailable", "the kubernetes api is temporarily unavailable, try again later")
+	default:
+		writeJSONError(responseWriter, http.StatusInternalServerError, "kubernetes_error", "unable to get instances")
+	}
+}
+
+// toAdminListJuiceShopInstance converts the deployment of a team into its admin list entry. LastConnect is 0 if the team never connected to its instance
+func toAdminListJuiceShopInstance(bundle *bundle.Bundle, teamDeployment appsv1.Deployment) AdminListJuiceShopInstance {
+	lastConnectAnnotation := teamDeployment.Annotations["multi-juicer.owasp-juice.shop/lastRequest"]
+	lastConnection := time.UnixMilli(0)
+
+	if lastConnectAnnotation != "" {
+		millis, err := strconv.ParseInt(lastConnectAnnotation, 10, 64)
+		if err != nil {
+			millis = 0
+		}
+		lastConnection = time.UnixMilli(millis)
+	}
+
+	return AdminListJuiceShopInstance{
+		Team:        teamDeployment.Labels["team"],
+		Ready:       bundle.IsInstanceReady(&teamDeployment),
+		CreatedAt:   teamDeployment.CreationTimestamp.UnixMilli(),
+		LastConnect: lastConnection.UnixMilli(),
+		ParkedAt:    teamDeployment.Annotations["multi-juicer.owasp-juice.shop/parkedAt"],
+
+		ExcludedFromScoreboard: teamDeployment.Annotations[scoring.ExcludeFromScoreboardAnnotation] == "true",
+	}
+}
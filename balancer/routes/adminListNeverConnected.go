@@ -0,0 +1,47 @@
+package routes
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/juice-shop/multi-juicer/balancer/pkg/bundle"
+	"github.com/juice-shop/multi-juicer/balancer/pkg/teamcookie"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// handleAdminListNeverConnected lists the instances of teams which got created but never sent a request to their JuiceShop, so that organizers can remind them or reclaim the instances
+func handleAdminListNeverConnected(bundle *bundle.Bundle) http.Handler {
+	return http.HandlerFunc(
+		func(responseWriter http.ResponseWriter, req *http.Request) {
+			team, err := teamcookie.GetTeamFromAdminRequest(bundle, req)
+			if err != nil || !bundle.IsAdminTeam(team) {
+				writeJSONError(responseWriter, http.StatusUnauthorized, "unauthorized", "admin login required")
+				return
+			}
+
+			deployments, err := bundle.ClientSet.AppsV1().Deployments(bundle.RuntimeEnvironment.Namespace).List(req.Context(), metav1.ListOptions{
+				LabelSelector: bundle.JuiceShopInstanceLabelSelector(),
+			})
+			if err != nil {
+				bundle.Log.Printf("Failed to list deployments: %s", err)
+				writeJSONError(responseWriter, http.StatusInternalServerError, "kubernetes_error", "unable to get instances")
+				return
+			}
+
+			instances := []AdminListJuiceShopInstance{}
+			for _, teamDeployment := range deployments.Items {
+				instance := toAdminListJuiceShopInstance(bundle, teamDeployment)
+				if instance.LastConnect == 0 {
+					instances = append(instances, instance)
+				}
+			}
+
+			responseBody, _ := json.Marshal(AdminListInstancesResponse{
+				Instances: instances,
+			})
+			responseWriter.Header().Set("Content-Type", "application/json")
+			responseWriter.WriteHeader(http.StatusOK)
+			responseWriter.Write(responseBody)
+		},
+	)
+}
@@ -0,0 +1,95 @@
+package routes
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/juice-shop/multi-juicer/balancer/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestAdminListNeverConnectedHandler(t *testing.T) {
+	createTeam := func(team string, createdAt time.Time, annotations map[string]string) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      fmt.Sprintf("juiceshop-%s", team),
+				Namespace: "test-namespace",
+				CreationTimestamp: metav1.Time{
+					Time: createdAt,
+				},
+				Annotations: annotations,
+				Labels: map[string]string{
+					"app.kubernetes.io/name":    "juice-shop",
+					"app.kubernetes.io/part-of": "multi-juicer",
+					"team":                      team,
+				},
+			},
+			Status: appsv1.DeploymentStatus{
+				ReadyReplicas: 1,
+			},
+		}
+	}
+
+	t.Run("listing never connected instances requires admin login", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/balancer/api/admin/instances/never-connected", nil)
+		req.Header.Set("Cookie", fmt.Sprintf("team=%s", testutil.SignTestTeamname("foobar")))
+		rr := httptest.NewRecorder()
+
+		server := http.NewServeMux()
+
+		bundle := testutil.NewTestBundle()
+		AddRoutes(server, bundle, nil)
+
+		server.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+
+	t.Run("only lists instances without a last request", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/balancer/api/admin/instances/never-connected", nil)
+		req.Header.Set("Cookie", fmt.Sprintf("team=%s", testutil.SignTestTeamname("admin")))
+		rr := httptest.NewRecorder()
+
+		server := http.NewServeMux()
+
+		clientset := fake.NewSimpleClientset(
+			createTeam("active-team", time.UnixMilli(1_700_000_000_000), map[string]string{
+				"multi-juicer.owasp-juice.shop/lastRequest": "1729259666123",
+			}),
+			createTeam("no-annotation", time.UnixMilli(1_700_000_000_000), map[string]string{}),
+			createTeam("epoch-zero", time.UnixMilli(1_600_000_000_000), map[string]string{
+				"multi-juicer.owasp-juice.shop/lastRequest": "0",
+			}),
+		)
+		bundle := testutil.NewTestBundleWithCustomFakeClient(clientset)
+		AddRoutes(server, bundle, nil)
+
+		server.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusOK, rr.Code)
+
+		var response AdminListInstancesResponse
+		err := json.Unmarshal(rr.Body.Bytes(), &response)
+		assert.Nil(t, err)
+
+		assert.ElementsMatch(t, []AdminListJuiceShopInstance{
+			{
+				Team:        "no-annotation",
+				Ready:       true,
+				CreatedAt:   1_700_000_000_000,
+				LastConnect: 0,
+			},
+			{
+				Team:        "epoch-zero",
+				Ready:       true,
+				CreatedAt:   1_600_000_000_000,
+				LastConnect: 0,
+			},
+		}, response.Instances)
+	})
+}
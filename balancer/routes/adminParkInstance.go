@@ -0,0 +1,101 @@
+package routes
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/juice-shop/multi-juicer/balancer/pkg/bundle"
+	"github.com/juice-shop/multi-juicer/balancer/pkg/teamcookie"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// handleAdminParkInstance scales the JuiceShop of a team down to free its resources, without deleting the team.
+// The progress annotations stay on the deployment, so the team keeps its score and gets its progress restored by the progress-watchdog once unparked
+func handleAdminParkInstance(bundle *bundle.Bundle) http.Handler {
+	return http.HandlerFunc(
+		func(responseWriter http.ResponseWriter, req *http.Request) {
+			team, err := teamcookie.GetTeamFromAdminRequest(bundle, req)
+			if err != nil || !bundle.IsAdminTeam(team) {
+				writeJSONError(responseWriter, http.StatusUnauthorized, "unauthorized", "admin login required")
+				return
+			}
+
+			teamToPark := req.PathValue("team")
+			if !isValidTeamName(teamToPark) {
+				writeJSONError(responseWriter, http.StatusBadRequest, "invalid_team_name", "invalid team name")
+				return
+			}
+
+			err = patchParkedState(req, bundle, teamToPark, 0, time.Now().UTC().Format(time.RFC3339))
+			if errors.IsNotFound(err) {
+				writeJSONError(responseWriter, http.StatusNotFound, "team_not_found", "team not found")
+				return
+			} else if err != nil {
+				bundle.Log.Printf("Failed to park team '%s': %s", teamToPark, err)
+				writeJSONError(responseWriter, http.StatusInternalServerError, "internal_error", "internal server error")
+				return
+			}
+			bundle.Log.Printf("Parked team '%s'", teamToPark)
+
+			responseWriter.WriteHeader(http.StatusOK)
+			responseWriter.Write([]byte{})
+		},
+	)
+}
+
+// handleAdminUnparkInstance scales a parked JuiceShop back up
+func handleAdminUnparkInstance(bundle *bundle.Bundle) http.Handler {
+	return http.HandlerFunc(
+		func(responseWriter http.ResponseWriter, req *http.Request) {
+			team, err := teamcookie.GetTeamFromAdminRequest(bundle, req)
+			if err != nil || !bundle.IsAdminTeam(team) {
+				writeJSONError(responseWriter, http.StatusUnauthorized, "unauthorized", "admin login required")
+				return
+			}
+
+			teamToUnpark := req.PathValue("team")
+			if !isValidTeamName(teamToUnpark) {
+				writeJSONError(responseWriter, http.StatusBadRequest, "invalid_team_name", "invalid team name")
+				return
+			}
+
+			// a null value removes the annotation in a merge patch
+			err = patchParkedState(req, bundle, teamToUnpark, 1, nil)
+			if errors.IsNotFound(err) {
+				writeJSONError(responseWriter, http.StatusNotFound, "team_not_found", "team not found")
+				return
+			} else if err != nil {
+				bundle.Log.Printf("Failed to unpark team '%s': %s", teamToUnpark, err)
+				writeJSONError(responseWriter, http.StatusInternalServerError, "internal_error", "internal server error")
+				return
+			}
+			bundle.Log.Printf("Unparked team '%s'", teamToUnpark)
+
+			responseWriter.WriteHeader(http.StatusOK)
+			responseWriter.Write([]byte{})
+		},
+	)
+}
+
+func patchParkedState(req *http.Request, bundle *bundle.Bundle, team string, replicas int32, parkedAt interface{}) error {
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]interface{}{
+				"multi-juicer.owasp-juice.shop/parkedAt": parkedAt,
+			},
+		},
+		"spec": map[string]interface{}{
+			"replicas": replicas,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode patch: %w", err)
+	}
+
+	_, err = bundle.ClientSet.AppsV1().Deployments(bundle.RuntimeEnvironment.Namespace).Patch(req.Context(), fmt.Sprintf("juiceshop-%s", team), types.MergePatchType, patch, metav1.PatchOptions{})
+	return err
+}
@@ -0,0 +1,98 @@
+package routes
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/juice-shop/multi-juicer/balancer/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestAdminParkInstanceHandler(t *testing.T) {
+	createTeam := func(team string) *appsv1.Deployment {
+		var replicas int32 = 1
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      fmt.Sprintf("juiceshop-%s", team),
+				Namespace: "test-namespace",
+				Annotations: map[string]string{
+					"multi-juicer.owasp-juice.shop/challenges":       `[{"key":"scoreBoardChallenge","solvedAt":"2024-11-01T19:55:48.211Z"}]`,
+					"multi-juicer.owasp-juice.shop/challengesSolved": "1",
+				},
+				Labels: map[string]string{
+					"app.kubernetes.io/name":    "juice-shop",
+					"app.kubernetes.io/part-of": "multi-juicer",
+					"team":                      team,
+				},
+			},
+			Spec: appsv1.DeploymentSpec{
+				Replicas: &replicas,
+			},
+		}
+	}
+
+	t.Run("parking requires admin login", func(t *testing.T) {
+		req, _ := http.NewRequest("POST", "/balancer/api/admin/teams/foobar/park", nil)
+		req.Header.Set("Cookie", fmt.Sprintf("team=%s", testutil.SignTestTeamname("foobar")))
+		rr := httptest.NewRecorder()
+
+		server := http.NewServeMux()
+		clientset := fake.NewSimpleClientset(createTeam("foobar"))
+		bundle := testutil.NewTestBundleWithCustomFakeClient(clientset)
+		AddRoutes(server, bundle, nil)
+
+		server.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+		assert.Len(t, clientset.Actions(), 0)
+	})
+
+	t.Run("returns 404 when parking teams which don't exist", func(t *testing.T) {
+		req, _ := http.NewRequest("POST", "/balancer/api/admin/teams/foobar/park", nil)
+		req.Header.Set("Cookie", fmt.Sprintf("team=%s", testutil.SignTestTeamname("admin")))
+		rr := httptest.NewRecorder()
+
+		server := http.NewServeMux()
+		bundle := testutil.NewTestBundle()
+		AddRoutes(server, bundle, nil)
+
+		server.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("parking scales the team down and keeps its progress, unparking scales it back up", func(t *testing.T) {
+		server := http.NewServeMux()
+		clientset := fake.NewSimpleClientset(createTeam("foobar"))
+		bundle := testutil.NewTestBundleWithCustomFakeClient(clientset)
+		AddRoutes(server, bundle, nil)
+
+		req, _ := http.NewRequest("POST", "/balancer/api/admin/teams/foobar/park", nil)
+		req.Header.Set("Cookie", fmt.Sprintf("team=%s", testutil.SignTestTeamname("admin")))
+		rr := httptest.NewRecorder()
+		server.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusOK, rr.Code)
+
+		deployment, err := clientset.AppsV1().Deployments("test-namespace").Get(context.Background(), "juiceshop-foobar", metav1.GetOptions{})
+		assert.Nil(t, err)
+		assert.Equal(t, int32(0), *deployment.Spec.Replicas)
+		assert.NotEmpty(t, deployment.Annotations["multi-juicer.owasp-juice.shop/parkedAt"])
+		assert.Equal(t, `[{"key":"scoreBoardChallenge","solvedAt":"2024-11-01T19:55:48.211Z"}]`, deployment.Annotations["multi-juicer.owasp-juice.shop/challenges"])
+
+		req, _ = http.NewRequest("POST", "/balancer/api/admin/teams/foobar/unpark", nil)
+		req.Header.Set("Cookie", fmt.Sprintf("team=%s", testutil.SignTestTeamname("admin")))
+		rr = httptest.NewRecorder()
+		server.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusOK, rr.Code)
+
+		deployment, err = clientset.AppsV1().Deployments("test-namespace").Get(context.Background(), "juiceshop-foobar", metav1.GetOptions{})
+		assert.Nil(t, err)
+		assert.Equal(t, int32(1), *deployment.Spec.Replicas)
+		assert.NotContains(t, deployment.Annotations, "multi-juicer.owasp-juice.shop/parkedAt")
+		assert.Equal(t, `[{"key":"scoreBoardChallenge","solvedAt":"2024-11-01T19:55:48.211Z"}]`, deployment.Annotations["multi-juicer.owasp-juice.shop/challenges"])
+	})
+}
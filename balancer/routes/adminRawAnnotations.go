@@ -0,0 +1,71 @@
+package routes
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/juice-shop/multi-juicer/balancer/pkg/bundle"
+	"github.com/juice-shop/multi-juicer/balancer/pkg/teamcookie"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// AdminRawAnnotationsResponse contains the progress annotations of a team exactly as stored on its deployment. Annotations which aren't set are null, to tell them apart from empty ones
+type AdminRawAnnotationsResponse struct {
+	Challenges         *string `json:"challenges"`
+	ContinueCodeFindIt *string `json:"continueCodeFindIt"`
+	ContinueCodeFixIt  *string `json:"continueCodeFixIt"`
+	LastRequest        *string `json:"lastRequest"`
+}
+
+// handleAdminRawAnnotations returns the raw progress annotations of a team, to debug annotations the scoring reports as invalid without access to the cluster
+func handleAdminRawAnnotations(bundle *bundle.Bundle) http.Handler {
+	return http.HandlerFunc(
+		func(responseWriter http.ResponseWriter, req *http.Request) {
+			team, err := teamcookie.GetTeamFromAdminRequest(bundle, req)
+			if err != nil || !bundle.IsAdminTeam(team) {
+				writeJSONError(responseWriter, http.StatusUnauthorized, "unauthorized", "admin login required")
+				return
+			}
+
+			requestedTeam := req.PathValue("team")
+			if !isValidTeamName(requestedTeam) {
+				writeJSONError(responseWriter, http.StatusBadRequest, "invalid_team_name", "invalid team name")
+				return
+			}
+
+			deployment, err := bundle.ClientSet.AppsV1().Deployments(bundle.RuntimeEnvironment.Namespace).Get(req.Context(), fmt.Sprintf("juiceshop-%s", requestedTeam), metav1.GetOptions{})
+			if errors.IsNotFound(err) {
+				writeJSONError(responseWriter, http.StatusNotFound, "team_not_found", "team not found")
+				return
+			} else if err != nil {
+				bundle.Log.Printf("Failed to get deployment of team '%s': %s", requestedTeam, err)
+				writeJSONError(responseWriter, http.StatusInternalServerError, "internal_error", "internal server error")
+				return
+			}
+
+			rawAnnotation := func(key string) *string {
+				value, ok := deployment.Annotations["multi-juicer.owasp-juice.shop/"+key]
+				if !ok {
+					return nil
+				}
+				return &value
+			}
+			responseBytes, err := json.Marshal(AdminRawAnnotationsResponse{
+				Challenges:         rawAnnotation("challenges"),
+				ContinueCodeFindIt: rawAnnotation("continueCodeFindIt"),
+				ContinueCodeFixIt:  rawAnnotation("continueCodeFixIt"),
+				LastRequest:        rawAnnotation("lastRequest"),
+			})
+			if err != nil {
+				bundle.Log.Printf("Failed to marshal raw annotations response: %s", err)
+				writeJSONError(responseWriter, http.StatusInternalServerError, "internal_error", "internal server error")
+				return
+			}
+			responseWriter.Header().Set("Content-Type", "application/json")
+			responseWriter.WriteHeader(http.StatusOK)
+			responseWriter.Write(responseBytes)
+		},
+	)
+}
@@ -0,0 +1,76 @@
+package routes
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/juice-shop/multi-juicer/balancer/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestAdminRawAnnotationsHandler(t *testing.T) {
+	createTeam := func(team string, annotations map[string]string) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        fmt.Sprintf("juiceshop-%s", team),
+				Namespace:   "test-namespace",
+				Annotations: annotations,
+				Labels: map[string]string{
+					"app.kubernetes.io/name":    "juice-shop",
+					"app.kubernetes.io/part-of": "multi-juicer",
+					"team":                      team,
+				},
+			},
+		}
+	}
+
+	t.Run("requires admin login", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/balancer/api/admin/teams/foobar/raw", nil)
+		req.Header.Set("Cookie", fmt.Sprintf("team=%s", testutil.SignTestTeamname("foobar")))
+		rr := httptest.NewRecorder()
+
+		server := http.NewServeMux()
+		bundle := testutil.NewTestBundleWithCustomFakeClient(fake.NewSimpleClientset(createTeam("foobar", map[string]string{})))
+		AddRoutes(server, bundle, nil)
+
+		server.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+
+	t.Run("returns 404 for teams which don't exist", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/balancer/api/admin/teams/foobar/raw", nil)
+		req.Header.Set("Cookie", fmt.Sprintf("team=%s", testutil.SignTestTeamname("admin")))
+		rr := httptest.NewRecorder()
+
+		server := http.NewServeMux()
+		AddRoutes(server, testutil.NewTestBundle(), nil)
+
+		server.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("returns the annotations exactly as stored", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/balancer/api/admin/teams/foobar/raw", nil)
+		req.Header.Set("Cookie", fmt.Sprintf("team=%s", testutil.SignTestTeamname("admin")))
+		rr := httptest.NewRecorder()
+
+		server := http.NewServeMux()
+		bundle := testutil.NewTestBundleWithCustomFakeClient(fake.NewSimpleClientset(
+			createTeam("foobar", map[string]string{
+				"multi-juicer.owasp-juice.shop/challenges":         `[{"key":"scoreBoardChallenge","solvedAt":"2024-11-01T19:55:48.211Z"`,
+				"multi-juicer.owasp-juice.shop/continueCodeFindIt": "",
+				"multi-juicer.owasp-juice.shop/lastRequest":        "1730490948211",
+			}),
+		))
+		AddRoutes(server, bundle, nil)
+
+		server.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.JSONEq(t, `{"challenges":"[{\"key\":\"scoreBoardChallenge\",\"solvedAt\":\"2024-11-01T19:55:48.211Z\"","continueCodeFindIt":"","continueCodeFixIt":null,"lastRequest":"1730490948211"}`, rr.Body.String())
+	})
+}
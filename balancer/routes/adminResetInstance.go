@@ -0,0 +1,80 @@
+package routes
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/juice-shop/multi-juicer/balancer/pkg/bundle"
+	"github.com/juice-shop/multi-juicer/balancer/pkg/teamcookie"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// handleAdminResetInstance wipes the challenge progress of a team.
+// The saved progress on the deployment gets cleared first and then the juice shop pod gets restarted, as juice shop only keeps the progress in memory.
+// Doing it the other way around would let the progress-watchdog re-apply the old progress to the fresh instance.
+func handleAdminResetInstance(bundle *bundle.Bundle) http.Handler {
+	return http.HandlerFunc(
+		func(responseWriter http.ResponseWriter, req *http.Request) {
+			team, err := teamcookie.GetTeamFromAdminRequest(bundle, req)
+			if err != nil || !bundle.IsAdminTeam(team) {
+				writeJSONError(responseWriter, http.StatusUnauthorized, "unauthorized", "admin login required")
+				return
+			}
+
+			teamToReset := req.PathValue("team")
+			if !isValidTeamName(teamToReset) {
+				writeJSONError(responseWriter, http.StatusBadRequest, "invalid_team_name", "invalid team name")
+				return
+			}
+
+			patch, err := json.Marshal(map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"annotations": map[string]interface{}{
+						"multi-juicer.owasp-juice.shop/challenges":       "[]",
+						"multi-juicer.owasp-juice.shop/challengesSolved": "0",
+					},
+				},
+			})
+			if err != nil {
+				bundle.Log.Printf("Failed to convert progress reset patch to json: %v", err)
+				writeJSONError(responseWriter, http.StatusInternalServerError, "internal_error", "internal server error")
+				return
+			}
+
+			_, err = bundle.ClientSet.AppsV1().Deployments(bundle.RuntimeEnvironment.Namespace).Patch(req.Context(), fmt.Sprintf("juiceshop-%s", teamToReset), types.MergePatchType, patch, metav1.PatchOptions{})
+			if errors.IsNotFound(err) {
+				writeJSONError(responseWriter, http.StatusNotFound, "team_not_found", "team not found")
+				return
+			} else if err != nil {
+				bundle.Log.Printf("Failed to reset progress of team '%s': %s", teamToReset, err)
+				writeJSONError(responseWriter, http.StatusInternalServerError, "internal_error", "internal server error")
+				return
+			}
+
+			pods, err := bundle.ClientSet.CoreV1().Pods(bundle.RuntimeEnvironment.Namespace).List(req.Context(), metav1.ListOptions{
+				LabelSelector: bundle.JuiceShopTeamLabelSelector(teamToReset),
+			})
+			if err != nil {
+				bundle.Log.Printf("Failed to list pods for team '%s': %s", teamToReset, err)
+				writeJSONError(responseWriter, http.StatusInternalServerError, "internal_error", "internal server error")
+				return
+			}
+
+			for _, pod := range pods.Items {
+				err = bundle.ClientSet.CoreV1().Pods(bundle.RuntimeEnvironment.Namespace).Delete(req.Context(), pod.Name, metav1.DeleteOptions{})
+				if err != nil && !errors.IsNotFound(err) {
+					bundle.Log.Printf("Failed to restart pod '%s' to reset the progress of team '%s': %s", pod.Name, teamToReset, err)
+					writeJSONError(responseWriter, http.StatusInternalServerError, "internal_error", "internal server error")
+					return
+				}
+			}
+
+			bundle.Log.Printf("Reset the challenge progress of team '%s'", teamToReset)
+			responseWriter.WriteHeader(http.StatusOK)
+			responseWriter.Write([]byte{})
+		},
+	)
+}
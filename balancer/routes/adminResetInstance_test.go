@@ -0,0 +1,120 @@
+package routes
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/juice-shop/multi-juicer/balancer/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestAdminResetInstanceHandler(t *testing.T) {
+	createDeploymentForTeam := func(team string) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      fmt.Sprintf("juiceshop-%s", team),
+				Namespace: "test-namespace",
+				Annotations: map[string]string{
+					"multi-juicer.owasp-juice.shop/challenges":       `[{"key":"scoreBoardChallenge","solvedAt":"2024-11-01T19:55:48.211Z"}]`,
+					"multi-juicer.owasp-juice.shop/challengesSolved": "1",
+					"multi-juicer.owasp-juice.shop/passcode":         "$2a$10$wnxvqClPk/13SbdowdJtu.2thGxrZe4qrsaVdTVUsYIrVVClhPMfS",
+				},
+				Labels: map[string]string{
+					"app.kubernetes.io/name":    "juice-shop",
+					"app.kubernetes.io/part-of": "multi-juicer",
+					"team":                      team,
+				},
+			},
+		}
+	}
+	createPodForTeam := func(team string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      fmt.Sprintf("juiceshop-%s", team),
+				Namespace: "test-namespace",
+				Labels: map[string]string{
+					"app.kubernetes.io/name":    "juice-shop",
+					"app.kubernetes.io/part-of": "multi-juicer",
+					"team":                      team,
+				},
+			},
+		}
+	}
+
+	t.Run("resetting progress requires admin login", func(t *testing.T) {
+		req, _ := http.NewRequest("POST", "/balancer/api/admin/teams/foobar/reset", nil)
+		req.Header.Set("Cookie", fmt.Sprintf("team=%s", testutil.SignTestTeamname("foobar")))
+		rr := httptest.NewRecorder()
+
+		server := http.NewServeMux()
+
+		clientset := fake.NewSimpleClientset(createDeploymentForTeam("foobar"), createPodForTeam("foobar"))
+		bundle := testutil.NewTestBundleWithCustomFakeClient(clientset)
+		AddRoutes(server, bundle, nil)
+
+		server.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+		assert.Len(t, clientset.Actions(), 0)
+	})
+
+	t.Run("returns 404 for teams which don't exist", func(t *testing.T) {
+		req, _ := http.NewRequest("POST", "/balancer/api/admin/teams/foobar/reset", nil)
+		req.Header.Set("Cookie", fmt.Sprintf("team=%s", testutil.SignTestTeamname("admin")))
+		rr := httptest.NewRecorder()
+
+		server := http.NewServeMux()
+
+		clientset := fake.NewSimpleClientset()
+		bundle := testutil.NewTestBundleWithCustomFakeClient(clientset)
+		AddRoutes(server, bundle, nil)
+
+		server.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("clears the saved progress and restarts the instance", func(t *testing.T) {
+		req, _ := http.NewRequest("POST", "/balancer/api/admin/teams/foobar/reset", nil)
+		req.Header.Set("Cookie", fmt.Sprintf("team=%s", testutil.SignTestTeamname("admin")))
+		rr := httptest.NewRecorder()
+
+		server := http.NewServeMux()
+
+		clientset := fake.NewSimpleClientset(
+			createDeploymentForTeam("foobar"),
+			createPodForTeam("foobar"),
+			createDeploymentForTeam("other-team"),
+			createPodForTeam("other-team"),
+		)
+		bundle := testutil.NewTestBundleWithCustomFakeClient(clientset)
+		AddRoutes(server, bundle, nil)
+
+		server.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+
+		deployment, err := clientset.AppsV1().Deployments("test-namespace").Get(context.Background(), "juiceshop-foobar", metav1.GetOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, "[]", deployment.Annotations["multi-juicer.owasp-juice.shop/challenges"])
+		assert.Equal(t, "0", deployment.Annotations["multi-juicer.owasp-juice.shop/challengesSolved"])
+		// unrelated annotations are kept
+		assert.NotEmpty(t, deployment.Annotations["multi-juicer.owasp-juice.shop/passcode"])
+
+		otherDeployment, err := clientset.AppsV1().Deployments("test-namespace").Get(context.Background(), "juiceshop-other-team", metav1.GetOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, "1", otherDeployment.Annotations["multi-juicer.owasp-juice.shop/challengesSolved"])
+
+		pods, err := clientset.CoreV1().Pods("test-namespace").List(context.Background(), metav1.ListOptions{})
+		assert.NoError(t, err)
+		assert.Len(t, pods.Items, 1)
+		assert.Equal(t, "juiceshop-other-team", pods.Items[0].Name)
+	})
+}
@@ -1,7 +1,6 @@
 package routes
 
 import (
-	"fmt"
 	"net/http"
 
 	"github.com/juice-shop/multi-juicer/balancer/pkg/bundle"
@@ -12,32 +11,32 @@ import (
 func handleAdminRestartInstance(bundle *bundle.Bundle) http.Handler {
 	return http.HandlerFunc(
 		func(responseWriter http.ResponseWriter, req *http.Request) {
-			team, err := teamcookie.GetTeamFromRequest(bundle, req)
-			if err != nil || team != "admin" {
-				http.Error(responseWriter, "", http.StatusUnauthorized)
+			team, err := teamcookie.GetTeamFromAdminRequest(bundle, req)
+			if err != nil || !bundle.IsAdminTeam(team) {
+				writeJSONError(responseWriter, http.StatusUnauthorized, "unauthorized", "admin login required")
 				return
 			}
 
 			teamToRestart := req.PathValue("team")
 			if !isValidTeamName(teamToRestart) {
-				http.Error(responseWriter, "invalid team name", http.StatusBadRequest)
+				writeJSONError(responseWriter, http.StatusBadRequest, "invalid_team_name", "invalid team name")
 				return
 			}
 
 			// find pod for service
 
 			pods, err := bundle.ClientSet.CoreV1().Pods(bundle.RuntimeEnvironment.Namespace).List(req.Context(), metav1.ListOptions{
-				LabelSelector: fmt.Sprintf("app.kubernetes.io/name=juice-shop,app.kubernetes.io/part-of=multi-juicer,team=%s", teamToRestart),
+				LabelSelector: bundle.JuiceShopTeamLabelSelector(teamToRestart),
 			})
 
 			if err != nil {
 				bundle.Log.Printf("Failed to list pods for team '%s': %s", teamToRestart, err)
-				http.Error(responseWriter, "", http.StatusInternalServerError)
+				writeJSONError(responseWriter, http.StatusInternalServerError, "internal_error", "internal server error")
 				return
 			}
 
 			if len(pods.Items) != 1 {
-				http.Error(responseWriter, "", http.StatusNotFound)
+				writeJSONError(responseWriter, http.StatusNotFound, "team_not_found", "team not found")
 				return
 			}
 
@@ -48,7 +47,7 @@ func handleAdminRestartInstance(bundle *bundle.Bundle) http.Handler {
 
 			if err != nil {
 				bundle.Log.Printf("Failed to restart pods for team '%s': %s", teamToRestart, err)
-				http.Error(responseWriter, "", http.StatusInternalServerError)
+				writeJSONError(responseWriter, http.StatusInternalServerError, "internal_error", "internal server error")
 				return
 			}
 
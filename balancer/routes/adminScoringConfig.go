@@ -0,0 +1,48 @@
+package routes
+
+import (
+	"encoding/json"
+	"net/http"
+
+	b "github.com/juice-shop/multi-juicer/balancer/pkg/bundle"
+	"github.com/juice-shop/multi-juicer/balancer/pkg/scoring"
+	"github.com/juice-shop/multi-juicer/balancer/pkg/teamcookie"
+)
+
+// maxScoringConfigSize limits the size of uploaded scoring configs, which only consist of a few small maps
+const maxScoringConfigSize = 64 * 1024
+
+// handleAdminUpdateScoringConfig changes the scoring options at runtime, e.g. to nerf a broken challenge without a redeploy, and recalculates all scores with them.
+// Accepts the same options as the scoring config file, except for the solveTimePrecision. Options which aren't set keep their current value
+func handleAdminUpdateScoringConfig(bundle *b.Bundle, scoringService *scoring.ScoringService) http.Handler {
+	return http.HandlerFunc(
+		func(responseWriter http.ResponseWriter, req *http.Request) {
+			team, err := teamcookie.GetTeamFromAdminRequest(bundle, req)
+			if err != nil || !bundle.IsAdminTeam(team) {
+				writeJSONError(responseWriter, http.StatusUnauthorized, "unauthorized", "admin login required")
+				return
+			}
+
+			scoringConfig, err := b.DecodeScoringConfig(http.MaxBytesReader(responseWriter, req.Body, maxScoringConfigSize))
+			if err != nil {
+				writeJSONError(responseWriter, http.StatusBadRequest, "invalid_scoring_config", err.Error())
+				return
+			}
+			if err := bundle.UpdateScoringRules(scoringConfig); err != nil {
+				writeJSONError(responseWriter, http.StatusBadRequest, "invalid_scoring_config", err.Error())
+				return
+			}
+			changes, _ := json.Marshal(scoringConfig)
+			bundle.Log.Printf("Admin '%s' updated the scoring config: %s", team, changes)
+
+			if err := scoringService.CalculateAndCacheScoreBoard(req.Context()); err != nil {
+				bundle.Log.Printf("Failed to recalculate the scores after the scoring config got updated: %s", err)
+				writeJSONError(responseWriter, http.StatusInternalServerError, "kubernetes_error", "scoring config got updated, but the scores couldn't be recalculated")
+				return
+			}
+
+			responseWriter.WriteHeader(http.StatusOK)
+			responseWriter.Write([]byte{})
+		},
+	)
+}
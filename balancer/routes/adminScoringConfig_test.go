@@ -0,0 +1,92 @@
+package routes
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/juice-shop/multi-juicer/balancer/pkg/scoring"
+	"github.com/juice-shop/multi-juicer/balancer/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestAdminUpdateScoringConfigHandler(t *testing.T) {
+	createTeam := func(team string, challenges string) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      fmt.Sprintf("juiceshop-%s", team),
+				Namespace: "test-namespace",
+				Annotations: map[string]string{
+					"multi-juicer.owasp-juice.shop/challenges": challenges,
+				},
+				Labels: map[string]string{
+					"app.kubernetes.io/name":    "juice-shop",
+					"app.kubernetes.io/part-of": "multi-juicer",
+					"team":                      team,
+				},
+			},
+			Status: appsv1.DeploymentStatus{ReadyReplicas: 1},
+		}
+	}
+	setupServer := func() (*http.ServeMux, *scoring.ScoringService) {
+		server := http.NewServeMux()
+		clientset := fake.NewSimpleClientset(
+			createTeam("foobar", `[{"key":"scoreBoardChallenge","solvedAt":"2024-11-01T19:55:48Z"},{"key":"nullByteChallenge","solvedAt":"2024-11-01T20:10:00Z"}]`),
+		)
+		bundle := testutil.NewTestBundleWithCustomFakeClient(clientset)
+		scoringService := scoring.NewScoringService(bundle)
+		require.NoError(t, scoringService.CalculateAndCacheScoreBoard(context.Background()))
+		AddRoutes(server, bundle, scoringService)
+		return server, scoringService
+	}
+	updateScoringConfig := func(server *http.ServeMux, team string, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("PUT", "/balancer/api/admin/scoring-config", strings.NewReader(body))
+		req.Header.Set("Cookie", fmt.Sprintf("team=%s", testutil.SignTestTeamname(team)))
+		rr := httptest.NewRecorder()
+		server.ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("requires admin login", func(t *testing.T) {
+		server, _ := setupServer()
+		rr := updateScoringConfig(server, "foobar", `{"challengePointOverrides":{"nullByteChallenge":0}}`)
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+
+	t.Run("recalculates the scores with the updated points", func(t *testing.T) {
+		server, scoringService := setupServer()
+		score, _ := scoringService.GetScoreForTeam("foobar")
+		assert.Equal(t, 50, score.Score)
+
+		rr := updateScoringConfig(server, "admin", `{"challengePointOverrides":{"nullByteChallenge":0}}`)
+		assert.Equal(t, http.StatusOK, rr.Code)
+
+		score, _ = scoringService.GetScoreForTeam("foobar")
+		assert.Equal(t, 10, score.Score)
+	})
+
+	t.Run("rejects invalid scoring configs", func(t *testing.T) {
+		server, scoringService := setupServer()
+
+		for _, body := range []string{
+			`{"challengePointOverrides":{"nullByteChallenge":-10}}`,
+			`{"challengePointOverides":{"nullByteChallenge":0}}`,
+			`{"solveTimePrecision":"1ms"}`,
+			`not json`,
+		} {
+			rr := updateScoringConfig(server, "admin", body)
+			assert.Equal(t, http.StatusBadRequest, rr.Code, body)
+			assert.Contains(t, rr.Body.String(), `"code":"invalid_scoring_config"`, body)
+		}
+
+		score, _ := scoringService.GetScoreForTeam("foobar")
+		assert.Equal(t, 50, score.Score)
+	})
+}
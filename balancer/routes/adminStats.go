@@ -0,0 +1,77 @@
+package routes
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/juice-shop/multi-juicer/balancer/pkg/bundle"
+	"github.com/juice-shop/multi-juicer/balancer/pkg/scoring"
+	"github.com/juice-shop/multi-juicer/balancer/pkg/teamcookie"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type AdminStatsResponse struct {
+	TotalTeams int `json:"totalTeams"`
+	// MaxInstances is the configured team capacity, -1 if unlimited
+	MaxInstances          int     `json:"maxInstances"`
+	ReadyInstances        int     `json:"readyInstances"`
+	NotReadyInstances     int     `json:"notReadyInstances"`
+	TotalSolvedChallenges int     `json:"totalSolvedChallenges"`
+	AverageScore          float64 `json:"averageScore"`
+}
+
+// handleAdminStats returns aggregated numbers about the event, so that organizers don't have to go through the full instance list
+func handleAdminStats(bundle *bundle.Bundle, scoringService *scoring.ScoringService) http.Handler {
+	return http.HandlerFunc(
+		func(responseWriter http.ResponseWriter, req *http.Request) {
+			team, err := teamcookie.GetTeamFromAdminRequest(bundle, req)
+			if err != nil || !bundle.IsAdminTeam(team) {
+				writeJSONError(responseWriter, http.StatusUnauthorized, "unauthorized", "admin login required")
+				return
+			}
+
+			deployments, err := bundle.ClientSet.AppsV1().Deployments(bundle.RuntimeEnvironment.Namespace).List(req.Context(), metav1.ListOptions{
+				LabelSelector: bundle.JuiceShopInstanceLabelSelector(),
+			})
+			if err != nil {
+				bundle.Log.Printf("Failed to list deployments: %s", err)
+				writeJSONError(responseWriter, http.StatusInternalServerError, "kubernetes_error", "unable to get instances")
+				return
+			}
+
+			stats := AdminStatsResponse{
+				TotalTeams:   len(deployments.Items),
+				MaxInstances: bundle.Config.MaxInstances,
+			}
+			for _, teamDeployment := range deployments.Items {
+				if bundle.IsInstanceReady(&teamDeployment) {
+					stats.ReadyInstances++
+				} else {
+					stats.NotReadyInstances++
+				}
+			}
+
+			if scoringService != nil {
+				scores := scoringService.GetScores()
+				totalScore := 0
+				for _, score := range scores {
+					stats.TotalSolvedChallenges += len(score.Challenges)
+					totalScore += score.Score
+				}
+				if len(scores) > 0 {
+					stats.AverageScore = float64(totalScore) / float64(len(scores))
+				}
+			}
+
+			responseBytes, err := json.Marshal(stats)
+			if err != nil {
+				bundle.Log.Printf("Failed to marshal admin stats: %s", err)
+				writeJSONError(responseWriter, http.StatusInternalServerError, "internal_error", "internal server error")
+				return
+			}
+			responseWriter.Header().Set("Content-Type", "application/json")
+			responseWriter.WriteHeader(http.StatusOK)
+			responseWriter.Write(responseBytes)
+		},
+	)
+}
@@ -0,0 +1,42 @@
+package routes
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/juice-shop/multi-juicer/balancer/pkg/bundle"
+	"github.com/juice-shop/multi-juicer/balancer/pkg/scoring"
+	"github.com/juice-shop/multi-juicer/balancer/pkg/teamcookie"
+)
+
+type AdminUnknownChallengesResponse struct {
+	UnknownChallenges []scoring.UnknownChallenge `json:"unknownChallenges"`
+}
+
+// handleAdminUnknownChallenges lists solved challenges which aren't part of the challenges.json of the balancer. Non empty if the challenges.json doesn't match the JuiceShop version of the instances
+func handleAdminUnknownChallenges(bundle *bundle.Bundle, scoringService *scoring.ScoringService) http.Handler {
+	return http.HandlerFunc(
+		func(responseWriter http.ResponseWriter, req *http.Request) {
+			team, err := teamcookie.GetTeamFromAdminRequest(bundle, req)
+			if err != nil || !bundle.IsAdminTeam(team) {
+				writeJSONError(responseWriter, http.StatusUnauthorized, "unauthorized", "admin login required")
+				return
+			}
+
+			unknownChallenges := []scoring.UnknownChallenge{}
+			if scoringService != nil {
+				unknownChallenges = scoringService.GetUnknownChallenges()
+			}
+
+			responseBytes, err := json.Marshal(AdminUnknownChallengesResponse{UnknownChallenges: unknownChallenges})
+			if err != nil {
+				bundle.Log.Printf("Failed to marshal unknown challenges: %s", err)
+				writeJSONError(responseWriter, http.StatusInternalServerError, "internal_error", "internal server error")
+				return
+			}
+			responseWriter.Header().Set("Content-Type", "application/json")
+			responseWriter.WriteHeader(http.StatusOK)
+			responseWriter.Write(responseBytes)
+		},
+	)
+}
@@ -0,0 +1,74 @@
+package routes
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/juice-shop/multi-juicer/balancer/pkg/scoring"
+	"github.com/juice-shop/multi-juicer/balancer/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestAdminUnknownChallengesHandler(t *testing.T) {
+	createTeam := func(team string, challenges string) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      fmt.Sprintf("juiceshop-%s", team),
+				Namespace: "test-namespace",
+				Annotations: map[string]string{
+					"multi-juicer.owasp-juice.shop/challenges": challenges,
+				},
+				Labels: map[string]string{
+					"app.kubernetes.io/name":    "juice-shop",
+					"app.kubernetes.io/part-of": "multi-juicer",
+					"team":                      team,
+				},
+			},
+		}
+	}
+
+	t.Run("listing unknown challenges requires admin login", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/balancer/api/admin/unknown-challenges", nil)
+		req.Header.Set("Cookie", fmt.Sprintf("team=%s", testutil.SignTestTeamname("foobar")))
+		rr := httptest.NewRecorder()
+
+		server := http.NewServeMux()
+		bundle := testutil.NewTestBundle()
+		AddRoutes(server, bundle, nil)
+
+		server.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+
+	t.Run("lists solved challenges which aren't part of the challenges.json", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/balancer/api/admin/unknown-challenges", nil)
+		req.Header.Set("Cookie", fmt.Sprintf("team=%s", testutil.SignTestTeamname("admin")))
+		rr := httptest.NewRecorder()
+
+		server := http.NewServeMux()
+		clientset := fake.NewSimpleClientset(
+			createTeam("foobar", `[{"key":"scoreBoardChallenge","solvedAt":"2024-11-01T19:55:48.211Z"},{"key":"removedChallenge","solvedAt":"2024-11-01T19:55:48.211Z"}]`),
+			createTeam("barfoo", `[{"key":"removedChallenge","solvedAt":"2024-11-01T19:55:48.211Z"},{"key":"futureChallenge","solvedAt":"2024-11-01T19:55:48.211Z"}]`),
+			createTeam("other", `[{"key":"scoreBoardChallenge","solvedAt":"2024-11-01T19:55:48.211Z"}]`),
+		)
+		bundle := testutil.NewTestBundleWithCustomFakeClient(clientset)
+		scoringService := scoring.NewScoringService(bundle)
+		assert.Nil(t, scoringService.CalculateAndCacheScoreBoard(context.Background()))
+		AddRoutes(server, bundle, scoringService)
+
+		server.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.JSONEq(t, `{
+			"unknownChallenges": [
+				{"key": "futureChallenge", "teams": ["barfoo"]},
+				{"key": "removedChallenge", "teams": ["barfoo", "foobar"]}
+			]
+		}`, rr.Body.String())
+	})
+}
@@ -0,0 +1,72 @@
+package routes
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/juice-shop/multi-juicer/balancer/pkg/bundle"
+	"github.com/juice-shop/multi-juicer/balancer/pkg/teamcookie"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type AdminWebhookLogResponse struct {
+	Entries []AdminWebhookLogEntry `json:"entries"`
+}
+
+// AdminWebhookLogEntry is a solution webhook received by the progress-watchdog for the team
+type AdminWebhookLogEntry struct {
+	Challenge  string `json:"challenge"`
+	IssuedOn   string `json:"issuedOn"`
+	ReceivedAt string `json:"receivedAt"`
+	Source     string `json:"source"`
+}
+
+// handleAdminWebhookLog returns the last solution webhooks of a team, which the progress-watchdog records on the deployment of the team. Helps to investigate disputed solves
+func handleAdminWebhookLog(bundle *bundle.Bundle) http.Handler {
+	return http.HandlerFunc(
+		func(responseWriter http.ResponseWriter, req *http.Request) {
+			team, err := teamcookie.GetTeamFromAdminRequest(bundle, req)
+			if err != nil || !bundle.IsAdminTeam(team) {
+				writeJSONError(responseWriter, http.StatusUnauthorized, "unauthorized", "admin login required")
+				return
+			}
+
+			requestedTeam := req.PathValue("team")
+			if !isValidTeamName(requestedTeam) {
+				writeJSONError(responseWriter, http.StatusBadRequest, "invalid_team_name", "invalid team name")
+				return
+			}
+
+			deployment, err := bundle.ClientSet.AppsV1().Deployments(bundle.RuntimeEnvironment.Namespace).Get(req.Context(), fmt.Sprintf("juiceshop-%s", requestedTeam), metav1.GetOptions{})
+			if errors.IsNotFound(err) {
+				writeJSONError(responseWriter, http.StatusNotFound, "team_not_found", "team not found")
+				return
+			} else if err != nil {
+				bundle.Log.Printf("Failed to get deployment of team '%s': %s", requestedTeam, err)
+				writeJSONError(responseWriter, http.StatusInternalServerError, "internal_error", "internal server error")
+				return
+			}
+
+			entries := []AdminWebhookLogEntry{}
+			if webhookLogJson := deployment.Annotations["multi-juicer.owasp-juice.shop/webhookLog"]; webhookLogJson != "" {
+				if err := json.Unmarshal([]byte(webhookLogJson), &entries); err != nil {
+					bundle.Log.Printf("Team '%s' has an invalid webhook log annotation: %s", requestedTeam, err)
+					writeJSONError(responseWriter, http.StatusInternalServerError, "invalid_webhook_log", "invalid webhook log")
+					return
+				}
+			}
+
+			responseBytes, err := json.Marshal(AdminWebhookLogResponse{Entries: entries})
+			if err != nil {
+				bundle.Log.Printf("Failed to marshal webhook log response: %s", err)
+				writeJSONError(responseWriter, http.StatusInternalServerError, "internal_error", "internal server error")
+				return
+			}
+			responseWriter.Header().Set("Content-Type", "application/json")
+			responseWriter.WriteHeader(http.StatusOK)
+			responseWriter.Write(responseBytes)
+		},
+	)
+}
@@ -0,0 +1,83 @@
+package routes
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/juice-shop/multi-juicer/balancer/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestAdminWebhookLogHandler(t *testing.T) {
+	createTeam := func(team string, annotations map[string]string) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        fmt.Sprintf("juiceshop-%s", team),
+				Namespace:   "test-namespace",
+				Annotations: annotations,
+				Labels: map[string]string{
+					"app.kubernetes.io/name":    "juice-shop",
+					"app.kubernetes.io/part-of": "multi-juicer",
+					"team":                      team,
+				},
+			},
+		}
+	}
+
+	t.Run("webhook log requires admin login", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/balancer/api/admin/teams/foobar/webhook-log", nil)
+		req.Header.Set("Cookie", fmt.Sprintf("team=%s", testutil.SignTestTeamname("foobar")))
+		rr := httptest.NewRecorder()
+
+		server := http.NewServeMux()
+		bundle := testutil.NewTestBundleWithCustomFakeClient(fake.NewSimpleClientset(createTeam("foobar", map[string]string{})))
+		AddRoutes(server, bundle, nil)
+
+		server.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+
+	t.Run("returns 404 for teams which don't exist", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/balancer/api/admin/teams/foobar/webhook-log", nil)
+		req.Header.Set("Cookie", fmt.Sprintf("team=%s", testutil.SignTestTeamname("admin")))
+		rr := httptest.NewRecorder()
+
+		server := http.NewServeMux()
+		bundle := testutil.NewTestBundle()
+		AddRoutes(server, bundle, nil)
+
+		server.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("returns the webhook log of the team", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/balancer/api/admin/teams/foobar/webhook-log", nil)
+		req.Header.Set("Cookie", fmt.Sprintf("team=%s", testutil.SignTestTeamname("admin")))
+		rr := httptest.NewRecorder()
+
+		server := http.NewServeMux()
+		bundle := testutil.NewTestBundleWithCustomFakeClient(fake.NewSimpleClientset(
+			createTeam("foobar", map[string]string{
+				"multi-juicer.owasp-juice.shop/webhookLog": `[{"challenge":"scoreBoardChallenge","issuedOn":"2024-11-01T19:55:48.211Z","receivedAt":"2024-11-01T19:55:49Z","source":"juiceshop-foobar-7d9f8b (10.0.0.12:41234)"}]`,
+			}),
+			createTeam("barfoo", map[string]string{}),
+		))
+		AddRoutes(server, bundle, nil)
+
+		server.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.JSONEq(t, `{"entries":[{"challenge":"scoreBoardChallenge","issuedOn":"2024-11-01T19:55:48.211Z","receivedAt":"2024-11-01T19:55:49Z","source":"juiceshop-foobar-7d9f8b (10.0.0.12:41234)"}]}`, rr.Body.String())
+
+		req, _ = http.NewRequest("GET", "/balancer/api/admin/teams/barfoo/webhook-log", nil)
+		req.Header.Set("Cookie", fmt.Sprintf("team=%s", testutil.SignTestTeamname("admin")))
+		rr = httptest.NewRecorder()
+		server.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.JSONEq(t, `{"entries":[]}`, rr.Body.String())
+	})
+}
@@ -0,0 +1,89 @@
+package routes
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+
+	b "github.com/juice-shop/multi-juicer/balancer/pkg/bundle"
+	"github.com/juice-shop/multi-juicer/balancer/pkg/scoring"
+)
+
+type CatalogChallenge struct {
+	Key        string `json:"key"`
+	Name       string `json:"name"`
+	Category   string `json:"category"`
+	Difficulty int    `json:"difficulty"`
+	Points     int    `json:"points"`
+}
+
+type ChallengeCatalogResponse struct {
+	Challenges []CatalogChallenge `json:"challenges"`
+}
+
+// challengeCatalogCache keeps the serialized catalog for the scoring rules it was built with, as the points change when admins update the scoring rules at runtime
+type challengeCatalogCache struct {
+	mutex         sync.Mutex
+	scoringRules  *b.Config
+	responseBytes []byte
+	etag          string
+}
+
+// get returns the serialized catalog and its etag, rebuilding them if the scoring rules changed since they were last built
+func (c *challengeCatalogCache) get(bundle *b.Bundle) ([]byte, string, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	scoringRules := bundle.ScoringRules()
+	if c.responseBytes != nil && c.scoringRules == scoringRules {
+		return c.responseBytes, c.etag, nil
+	}
+
+	challenges := make([]CatalogChallenge, len(bundle.JuiceShopChallenges))
+	for i, challenge := range bundle.JuiceShopChallenges {
+		challenges[i] = CatalogChallenge{
+			Key:        challenge.Key,
+			Name:       challenge.Name,
+			Category:   challenge.Category,
+			Difficulty: challenge.Difficulty,
+			Points:     scoring.ChallengePoints(bundle, challenge),
+		}
+	}
+	responseBytes, err := json.Marshal(ChallengeCatalogResponse{Challenges: challenges})
+	if err != nil {
+		return nil, "", err
+	}
+	responseHash := sha256.Sum256(responseBytes)
+	c.responseBytes = responseBytes
+	c.etag = fmt.Sprintf(`"%s"`, hex.EncodeToString(responseHash[:16]))
+	c.scoringRules = scoringRules
+	return c.responseBytes, c.etag, nil
+}
+
+// handleChallengeCatalog lists all challenges known to the scoring, so that front-ends can show challenge names instead of their keys
+func handleChallengeCatalog(bundle *b.Bundle) http.Handler {
+	// the catalog only changes with the scoring rules, so it's serialized once per rule change and clients can cache it using its etag
+	cache := &challengeCatalogCache{}
+	return http.HandlerFunc(
+		func(responseWriter http.ResponseWriter, req *http.Request) {
+			responseBytes, etag, err := cache.get(bundle)
+			if err != nil {
+				bundle.Log.Printf("Failed to marshal response: %s", err)
+				http.Error(responseWriter, "", http.StatusInternalServerError)
+				return
+			}
+
+			responseWriter.Header().Set("ETag", etag)
+			if etagMatches(req.Header.Get("If-None-Match"), etag) {
+				responseWriter.WriteHeader(http.StatusNotModified)
+				return
+			}
+
+			responseWriter.Header().Set("Content-Type", "application/json")
+			responseWriter.WriteHeader(http.StatusOK)
+			responseWriter.Write(responseBytes)
+		},
+	)
+}
@@ -0,0 +1,99 @@
+package routes
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/juice-shop/multi-juicer/balancer/pkg/scoring"
+	"github.com/juice-shop/multi-juicer/balancer/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChallengeCatalogHandler(t *testing.T) {
+	t.Run("lists all challenges with their points", func(t *testing.T) {
+		bundle := testutil.NewTestBundle()
+		bundle.JuiceShopChallenges[0].Category = "Miscellaneous"
+		bundle.JuiceShopChallenges[1].Category = "Improper Input Validation"
+		bundle.Config.ChallengePointOverrides = map[string]int{"nullByteChallenge": 100}
+
+		server := http.NewServeMux()
+		AddRoutes(server, bundle, nil)
+
+		req, _ := http.NewRequest("GET", "/balancer/api/score-board/challenges", nil)
+		rr := httptest.NewRecorder()
+		server.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+		assert.JSONEq(t, `{"challenges":[
+			{"key":"scoreBoardChallenge","name":"Score Board","category":"Miscellaneous","difficulty":1,"points":10},
+			{"key":"nullByteChallenge","name":"Poison Null Byte","category":"Improper Input Validation","difficulty":4,"points":100}
+		]}`, rr.Body.String())
+	})
+
+	t.Run("returns 304 if the client already has the current catalog", func(t *testing.T) {
+		bundle := testutil.NewTestBundle()
+		server := http.NewServeMux()
+		AddRoutes(server, bundle, nil)
+
+		req, _ := http.NewRequest("GET", "/balancer/api/score-board/challenges", nil)
+		rr := httptest.NewRecorder()
+		server.ServeHTTP(rr, req)
+		etag := rr.Header().Get("ETag")
+		assert.NotEmpty(t, etag)
+
+		req, _ = http.NewRequest("GET", "/balancer/api/score-board/challenges", nil)
+		req.Header.Set("If-None-Match", etag)
+		rr = httptest.NewRecorder()
+		server.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusNotModified, rr.Code)
+		assert.Empty(t, rr.Body.String())
+	})
+
+	t.Run("etag changes with the points of the challenges", func(t *testing.T) {
+		getEtag := func(pointOverrides map[string]int) string {
+			bundle := testutil.NewTestBundle()
+			bundle.Config.ChallengePointOverrides = pointOverrides
+			server := http.NewServeMux()
+			AddRoutes(server, bundle, nil)
+
+			req, _ := http.NewRequest("GET", "/balancer/api/score-board/challenges", nil)
+			rr := httptest.NewRecorder()
+			server.ServeHTTP(rr, req)
+			return rr.Header().Get("ETag")
+		}
+
+		assert.NotEqual(t, getEtag(nil), getEtag(map[string]int{"nullByteChallenge": 100}))
+	})
+
+	t.Run("reflects scoring rule changes made at runtime", func(t *testing.T) {
+		bundle := testutil.NewTestBundle()
+		server := http.NewServeMux()
+		AddRoutes(server, bundle, scoring.NewScoringService(bundle))
+
+		req, _ := http.NewRequest("GET", "/balancer/api/score-board/challenges", nil)
+		rr := httptest.NewRecorder()
+		server.ServeHTTP(rr, req)
+		etag := rr.Header().Get("ETag")
+		assert.Contains(t, rr.Body.String(), `"points":40`)
+
+		req, _ = http.NewRequest("PUT", "/balancer/api/admin/scoring-config", strings.NewReader(`{"challengePointOverrides":{"nullByteChallenge":100}}`))
+		req.Header.Set("Cookie", fmt.Sprintf("team=%s", testutil.SignTestTeamname("admin")))
+		rr = httptest.NewRecorder()
+		server.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusOK, rr.Code)
+
+		req, _ = http.NewRequest("GET", "/balancer/api/score-board/challenges", nil)
+		req.Header.Set("If-None-Match", etag)
+		rr = httptest.NewRecorder()
+		server.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.NotEqual(t, etag, rr.Header().Get("ETag"))
+		assert.Contains(t, rr.Body.String(), `"points":100`)
+	})
+}
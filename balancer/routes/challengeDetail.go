@@ -53,13 +53,13 @@ func handleChallengeDetail(bundle *b.Bundle, scoringService *scoring.ScoringServ
 
 		// 2. Iterate through all teams and their solved challenges to find who solved this one.
 		solves := make(ChallengeSolves, 0)
-		allTeamScores := scoringService.GetScores()
+		allTeamScores, _, _ := scoringService.GetPublicTopScoresWithLastUpdate()
 
-		for teamName, teamScore := range allTeamScores {
+		for _, teamScore := range allTeamScores {
 			for _, solvedChallenge := range teamScore.Challenges {
 				if solvedChallenge.Key == challengeKey {
 					solves = append(solves, ChallengeSolve{
-						Team:     teamName,
+						Team:     bundle.PublicTeamName(teamScore.Name),
 						SolvedAt: solvedChallenge.SolvedAt,
 					})
 					break // Move to the next team
@@ -102,4 +102,22 @@ func TestChallengeDetailHandler(t *testing.T) {
 
 		assert.Equal(t, http.StatusNotFound, rr.Code)
 	})
+
+	t.Run("should not list solves made after the scoreboard got frozen", func(t *testing.T) {
+		clientset := fake.NewSimpleClientset(
+			createTeamWithSolvedChallenges("team-alpha", `[]`),
+		)
+		bundle, scoringService := newFrozenScoringService(t, clientset, "team-alpha", `[{"key":"nullByteChallenge","solvedAt":"2024-11-01T20:00:00Z"}]`)
+		server := http.NewServeMux()
+		AddRoutes(server, bundle, scoringService)
+
+		req, _ := http.NewRequest("GET", "/balancer/api/v2/challenges/nullByteChallenge", nil)
+		rr := httptest.NewRecorder()
+		server.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		var response ChallengeDetailResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		assert.Empty(t, response.Solves)
+	})
 }
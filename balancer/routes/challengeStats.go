@@ -0,0 +1,34 @@
+package routes
+
+import (
+	"encoding/json"
+	"net/http"
+
+	b "github.com/juice-shop/multi-juicer/balancer/pkg/bundle"
+	"github.com/juice-shop/multi-juicer/balancer/pkg/scoring"
+)
+
+type ChallengeStatsResponse struct {
+	Challenges []scoring.ChallengeStats `json:"challenges"`
+}
+
+func handleChallengeStats(bundle *b.Bundle, scoringService *scoring.ScoringService) http.Handler {
+	return http.HandlerFunc(
+		func(responseWriter http.ResponseWriter, req *http.Request) {
+			response := ChallengeStatsResponse{
+				Challenges: scoringService.GetChallengeStats(),
+			}
+
+			responseBytes, err := json.Marshal(response)
+			if err != nil {
+				bundle.Log.Printf("Failed to marshal response: %s", err)
+				http.Error(responseWriter, "", http.StatusInternalServerError)
+				return
+			}
+
+			responseWriter.Header().Set("Content-Type", "application/json")
+			responseWriter.WriteHeader(http.StatusOK)
+			responseWriter.Write(responseBytes)
+		},
+	)
+}
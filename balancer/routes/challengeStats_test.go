@@ -0,0 +1,72 @@
+package routes
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/juice-shop/multi-juicer/balancer/pkg/scoring"
+	"github.com/juice-shop/multi-juicer/balancer/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestChallengeStatsHandler(t *testing.T) {
+	t.Run("counts solves and finds the first solve per challenge", func(t *testing.T) {
+		clientset := fake.NewSimpleClientset(
+			createTeamWithSolvedChallenges("team-alpha", `[{"key":"scoreBoardChallenge","solvedAt":"2024-11-01T20:00:00Z"}]`),
+			createTeamWithSolvedChallenges("team-bravo", `[{"key":"scoreBoardChallenge","solvedAt":"2024-11-01T19:00:00Z"}]`),
+			createTeamWithSolvedChallenges("team-charlie", `[]`),
+		)
+		bundle := testutil.NewTestBundleWithCustomFakeClient(clientset)
+		scoringService := scoring.NewScoringService(bundle)
+		err := scoringService.CalculateAndCacheScoreBoard(context.Background())
+		require.NoError(t, err)
+
+		server := http.NewServeMux()
+		AddRoutes(server, bundle, scoringService)
+
+		req, _ := http.NewRequest("GET", "/balancer/api/score-board/challenge-stats", nil)
+		rr := httptest.NewRecorder()
+		server.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+
+		var response ChallengeStatsResponse
+		err = json.Unmarshal(rr.Body.Bytes(), &response)
+		require.NoError(t, err)
+
+		firstSolve := time.Date(2024, 11, 1, 19, 0, 0, 0, time.UTC)
+		assert.Equal(t, []scoring.ChallengeStats{
+			{Key: "scoreBoardChallenge", SolveCount: 2, FirstSolvedAt: &firstSolve},
+			{Key: "nullByteChallenge", SolveCount: 0, FirstSolvedAt: nil},
+		}, response.Challenges)
+	})
+
+	t.Run("doesn't count solves made after the scoreboard got frozen", func(t *testing.T) {
+		clientset := fake.NewSimpleClientset(
+			createTeamWithSolvedChallenges("team-alpha", `[{"key":"scoreBoardChallenge","solvedAt":"2024-11-01T19:00:00Z"}]`),
+		)
+		bundle, scoringService := newFrozenScoringService(t, clientset, "team-alpha", `[{"key":"scoreBoardChallenge","solvedAt":"2024-11-01T19:00:00Z"},{"key":"nullByteChallenge","solvedAt":"2024-11-01T20:00:00Z"}]`)
+		server := http.NewServeMux()
+		AddRoutes(server, bundle, scoringService)
+
+		req, _ := http.NewRequest("GET", "/balancer/api/score-board/challenge-stats", nil)
+		rr := httptest.NewRecorder()
+		server.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		var response ChallengeStatsResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+
+		firstSolve := time.Date(2024, 11, 1, 19, 0, 0, 0, time.UTC)
+		assert.Equal(t, []scoring.ChallengeStats{
+			{Key: "scoreBoardChallenge", SolveCount: 1, FirstSolvedAt: &firstSolve},
+			{Key: "nullByteChallenge", SolveCount: 0, FirstSolvedAt: nil},
+		}, response.Challenges)
+	})
+}
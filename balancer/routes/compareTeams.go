@@ -0,0 +1,137 @@
+package routes
+
+import (
+	"net/http"
+	"time"
+
+	b "github.com/juice-shop/multi-juicer/balancer/pkg/bundle"
+	"github.com/juice-shop/multi-juicer/balancer/pkg/scoring"
+)
+
+type CompareTeamsResponse struct {
+	A *TeamScore `json:"a"`
+	B *TeamScore `json:"b"`
+	// OnlySolvedByA and OnlySolvedByB are the keys of the challenges solved by one of the teams but not by the other
+	OnlySolvedByA []string `json:"onlySolvedByA"`
+	OnlySolvedByB []string `json:"onlySolvedByB"`
+	// Shared are the challenges solved by both teams
+	Shared []SharedChallenge `json:"shared"`
+}
+
+type SharedChallenge struct {
+	Key       string `json:"key"`
+	SolvedAtA string `json:"solvedAtA"`
+	SolvedAtB string `json:"solvedAtB"`
+	// FirstSolvedBy is the name of the team which solved the challenge first, empty if both solved it at the same time
+	FirstSolvedBy string `json:"firstSolvedBy"`
+}
+
+// handleCompareTeams compares the scores and solved challenges of two teams head-to-head. Teams are identified by the name shown on the public scoreboard, which is their alias if the scoreboard is anonymized
+func handleCompareTeams(bundle *b.Bundle, scoringService *scoring.ScoringService) http.Handler {
+	return http.HandlerFunc(
+		func(responseWriter http.ResponseWriter, req *http.Request) {
+			naming, err := responseFieldNaming(bundle, req)
+			if err != nil {
+				http.Error(responseWriter, err.Error(), http.StatusNotAcceptable)
+				return
+			}
+			nameA := req.URL.Query().Get("a")
+			nameB := req.URL.Query().Get("b")
+			if !isValidTeamName(nameA) || !isValidTeamName(nameB) {
+				http.Error(responseWriter, "query params 'a' and 'b' must be valid team names", http.StatusBadRequest)
+				return
+			}
+			if nameA == nameB {
+				http.Error(responseWriter, "query params 'a' and 'b' must be different teams", http.StatusBadRequest)
+				return
+			}
+			if !canCompareTeams(bundle, req) {
+				http.Error(responseWriter, "comparing teams is restricted to admins", http.StatusForbidden)
+				return
+			}
+
+			scores, _, _ := scoringService.GetPublicTopScoresWithLastUpdate()
+			teamA := findTeamByPublicName(bundle, scores, nameA)
+			teamB := findTeamByPublicName(bundle, scores, nameB)
+			if teamA == nil || teamB == nil {
+				http.Error(responseWriter, "team not found", http.StatusNotFound)
+				return
+			}
+
+			response := compareTeams(bundle, teamA, teamB)
+
+			responseBytes, err := marshalWithFieldNaming(response, naming)
+			if err != nil {
+				bundle.Log.Printf("Failed to marshal response: %s", err)
+				http.Error(responseWriter, "", http.StatusInternalServerError)
+				return
+			}
+
+			responseWriter.Header().Set("Content-Type", "application/json")
+			responseWriter.WriteHeader(http.StatusOK)
+			responseWriter.Write(responseBytes)
+		},
+	)
+}
+
+func findTeamByPublicName(bundle *b.Bundle, scores []*scoring.TeamScore, name string) *scoring.TeamScore {
+	for _, score := range scores {
+		if bundle.PublicTeamName(score.Name) == name {
+			return score
+		}
+	}
+	return nil
+}
+
+func toPublicTeamScore(bundle *b.Bundle, score *scoring.TeamScore) *TeamScore {
+	return &TeamScore{
+		Name:                 bundle.PublicTeamName(score.Name),
+		Score:                score.Score,
+		Position:             score.Position,
+		SolvedChallengeCount: len(score.Challenges),
+		Capped:               score.Capped,
+	}
+}
+
+// compareTeams splits the solved challenges of both teams into the ones only one of them solved and the shared ones
+func compareTeams(bundle *b.Bundle, teamA *scoring.TeamScore, teamB *scoring.TeamScore) CompareTeamsResponse {
+	solvedAtB := make(map[string]time.Time, len(teamB.Challenges))
+	for _, challenge := range teamB.Challenges {
+		solvedAtB[challenge.Key] = challenge.SolvedAt
+	}
+
+	response := CompareTeamsResponse{
+		A:             toPublicTeamScore(bundle, teamA),
+		B:             toPublicTeamScore(bundle, teamB),
+		OnlySolvedByA: []string{},
+		OnlySolvedByB: []string{},
+		Shared:        []SharedChallenge{},
+	}
+	solvedByA := make(map[string]bool, len(teamA.Challenges))
+	for _, challenge := range teamA.Challenges {
+		solvedByA[challenge.Key] = true
+		solvedAt, ok := solvedAtB[challenge.Key]
+		if !ok {
+			response.OnlySolvedByA = append(response.OnlySolvedByA, challenge.Key)
+			continue
+		}
+		firstSolvedBy := ""
+		if challenge.SolvedAt.Before(solvedAt) {
+			firstSolvedBy = response.A.Name
+		} else if solvedAt.Before(challenge.SolvedAt) {
+			firstSolvedBy = response.B.Name
+		}
+		response.Shared = append(response.Shared, SharedChallenge{
+			Key:           challenge.Key,
+			SolvedAtA:     challenge.SolvedAt.Format(time.RFC3339),
+			SolvedAtB:     solvedAt.Format(time.RFC3339),
+			FirstSolvedBy: firstSolvedBy,
+		})
+	}
+	for _, challenge := range teamB.Challenges {
+		if !solvedByA[challenge.Key] {
+			response.OnlySolvedByB = append(response.OnlySolvedByB, challenge.Key)
+		}
+	}
+	return response
+}
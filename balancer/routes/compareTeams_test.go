@@ -0,0 +1,140 @@
+package routes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/juice-shop/multi-juicer/balancer/pkg/scoring"
+	"github.com/juice-shop/multi-juicer/balancer/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestCompareTeamsHandler(t *testing.T) {
+	createTeam := func(team string, challenges string, solvedChallenges string) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      fmt.Sprintf("juiceshop-%s", team),
+				Namespace: "test-namespace",
+				Annotations: map[string]string{
+					"multi-juicer.owasp-juice.shop/challenges":       challenges,
+					"multi-juicer.owasp-juice.shop/challengesSolved": solvedChallenges,
+				},
+				Labels: map[string]string{
+					"app.kubernetes.io/name":    "juice-shop",
+					"app.kubernetes.io/part-of": "multi-juicer",
+					"team":                      team,
+				},
+			},
+			Status: appsv1.DeploymentStatus{
+				ReadyReplicas: 1,
+			},
+		}
+	}
+	clientset := fake.NewSimpleClientset(
+		createTeam("foobar", `[{"key":"scoreBoardChallenge","solvedAt":"2024-11-01T19:55:48.211Z"},{"key":"nullByteChallenge","solvedAt":"2024-11-01T20:10:00.000Z"}]`, "2"),
+		createTeam("barfoo", `[{"key":"nullByteChallenge","solvedAt":"2024-11-01T20:00:00.000Z"}]`, "1"),
+		createTeam("test-team", `[]`, "0"),
+	)
+
+	compare := func(t *testing.T, anonymize bool, query string) *httptest.ResponseRecorder {
+		bundle := testutil.NewTestBundleWithCustomFakeClient(clientset)
+		bundle.Config.AnonymizePublicScoreboard = anonymize
+		scoringService := scoring.NewScoringService(bundle)
+		scoringService.CalculateAndCacheScoreBoard(context.Background())
+		server := http.NewServeMux()
+		AddRoutes(server, bundle, scoringService)
+
+		req, _ := http.NewRequest("GET", "/balancer/api/score-board/compare?"+query, nil)
+		rr := httptest.NewRecorder()
+		server.ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("compares the solved challenges of both teams", func(t *testing.T) {
+		rr := compare(t, false, "a=foobar&b=barfoo")
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		var response CompareTeamsResponse
+		assert.Nil(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		assert.Equal(t, &TeamScore{Name: "foobar", Score: 50, Position: 1, SolvedChallengeCount: 2}, response.A)
+		assert.Equal(t, &TeamScore{Name: "barfoo", Score: 40, Position: 2, SolvedChallengeCount: 1}, response.B)
+		assert.Equal(t, []string{"scoreBoardChallenge"}, response.OnlySolvedByA)
+		assert.Equal(t, []string{}, response.OnlySolvedByB)
+		assert.Equal(t, []SharedChallenge{
+			{
+				Key:           "nullByteChallenge",
+				SolvedAtA:     "2024-11-01T20:10:00Z",
+				SolvedAtB:     "2024-11-01T20:00:00Z",
+				FirstSolvedBy: "barfoo",
+			},
+		}, response.Shared)
+	})
+
+	t.Run("lists challenges only solved by the second team", func(t *testing.T) {
+		rr := compare(t, false, "a=test-team&b=barfoo")
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		var response CompareTeamsResponse
+		assert.Nil(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		assert.Equal(t, []string{}, response.OnlySolvedByA)
+		assert.Equal(t, []string{"nullByteChallenge"}, response.OnlySolvedByB)
+		assert.Equal(t, []SharedChallenge{}, response.Shared)
+	})
+
+	t.Run("identifies teams by their alias if the scoreboard is anonymized", func(t *testing.T) {
+		bundle := testutil.NewTestBundle()
+		bundle.Config.AnonymizePublicScoreboard = true
+		aliasA := bundle.PublicTeamName("foobar")
+		aliasB := bundle.PublicTeamName("barfoo")
+
+		rr := compare(t, true, "a=foobar&b=barfoo")
+		assert.Equal(t, http.StatusNotFound, rr.Code, "Should not find teams by their real name")
+
+		rr = compare(t, true, fmt.Sprintf("a=%s&b=%s", aliasA, aliasB))
+		assert.Equal(t, http.StatusOK, rr.Code)
+		var response CompareTeamsResponse
+		assert.Nil(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		assert.Equal(t, aliasA, response.A.Name)
+		assert.Equal(t, aliasB, response.B.Name)
+		assert.Equal(t, aliasB, response.Shared[0].FirstSolvedBy)
+		assert.NotContains(t, rr.Body.String(), "foobar")
+	})
+
+	t.Run("is restricted to admins if team details are private", func(t *testing.T) {
+		bundle := testutil.NewTestBundleWithCustomFakeClient(clientset)
+		bundle.Config.PrivateTeamDetails = true
+		scoringService := scoring.NewScoringService(bundle)
+		scoringService.CalculateAndCacheScoreBoard(context.Background())
+		server := http.NewServeMux()
+		AddRoutes(server, bundle, scoringService)
+
+		compareAs := func(team string) int {
+			req, _ := http.NewRequest("GET", "/balancer/api/score-board/compare?a=foobar&b=barfoo", nil)
+			req.AddCookie(&http.Cookie{Name: "team", Value: testutil.SignTestTeamname(team)})
+			rr := httptest.NewRecorder()
+			server.ServeHTTP(rr, req)
+			return rr.Code
+		}
+
+		assert.Equal(t, http.StatusForbidden, compareAs("foobar"))
+		assert.Equal(t, http.StatusOK, compareAs("admin"))
+	})
+
+	t.Run("returns 404 for unknown teams", func(t *testing.T) {
+		rr := compare(t, false, "a=foobar&b=unknown")
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("requires two different valid team names", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, compare(t, false, "a=foobar").Code)
+		assert.Equal(t, http.StatusBadRequest, compare(t, false, "a=foobar&b=foobar").Code)
+		assert.Equal(t, http.StatusBadRequest, compare(t, false, "a=foobar&b=Not%20Valid").Code)
+	})
+}
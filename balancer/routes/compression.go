@@ -0,0 +1,91 @@
+package routes
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// compressionThreshold is the minimum response size in bytes for which compressing the response is worth the overhead
+const compressionThreshold = 1024
+
+// writeCompressedResponse writes the body with a 200 status, compressed with gzip or deflate if the client accepts it and the body is large enough
+func writeCompressedResponse(responseWriter http.ResponseWriter, req *http.Request, body []byte) {
+	if !varies(responseWriter.Header(), "Accept-Encoding") {
+		responseWriter.Header().Add("Vary", "Accept-Encoding")
+	}
+
+	encoding := negotiateContentEncoding(req.Header.Get("Accept-Encoding"))
+	if encoding == "" || len(body) < compressionThreshold {
+		responseWriter.WriteHeader(http.StatusOK)
+		responseWriter.Write(body)
+		return
+	}
+
+	var buffer bytes.Buffer
+	var compressor io.WriteCloser
+	switch encoding {
+	case "gzip":
+		compressor = gzip.NewWriter(&buffer)
+	case "deflate":
+		// the http "deflate" content-coding is the zlib format, see RFC 9110 section 8.4.1.2
+		compressor = zlib.NewWriter(&buffer)
+	}
+	if _, err := compressor.Write(body); err != nil || compressor.Close() != nil {
+		responseWriter.WriteHeader(http.StatusOK)
+		responseWriter.Write(body)
+		return
+	}
+
+	responseWriter.Header().Set("Content-Encoding", encoding)
+	responseWriter.WriteHeader(http.StatusOK)
+	responseWriter.Write(buffer.Bytes())
+}
+
+// negotiateContentEncoding picks the content encoding to use based on the Accept-Encoding header. gzip is preferred over deflate, returns an empty string if neither is accepted
+func negotiateContentEncoding(acceptEncoding string) string {
+	accepted := map[string]bool{}
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		coding = strings.ToLower(strings.TrimSpace(coding))
+		if coding == "" {
+			continue
+		}
+		// a quality of zero means the client explicitly does not accept the encoding
+		accepted[coding] = true
+		if qValue, ok := strings.CutPrefix(strings.ReplaceAll(params, " ", ""), "q="); ok {
+			if quality, err := strconv.ParseFloat(qValue, 64); err == nil && quality == 0 {
+				accepted[coding] = false
+			}
+		}
+	}
+
+	for _, encoding := range []string{"gzip", "deflate"} {
+		if isAccepted, ok := accepted[encoding]; ok {
+			if isAccepted {
+				return encoding
+			}
+			continue
+		}
+		if accepted["*"] {
+			return encoding
+		}
+	}
+	return ""
+}
+
+// varies checks if the Vary header of the response already lists the request header
+func varies(header http.Header, requestHeader string) bool {
+	for _, value := range header.Values("Vary") {
+		for _, field := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(field), requestHeader) {
+				return true
+			}
+		}
+	}
+	return false
+}
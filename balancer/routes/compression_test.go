@@ -0,0 +1,84 @@
+package routes
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNegotiateContentEncoding(t *testing.T) {
+	assert.Equal(t, "", negotiateContentEncoding(""))
+	assert.Equal(t, "gzip", negotiateContentEncoding("gzip"))
+	assert.Equal(t, "gzip", negotiateContentEncoding("deflate, gzip;q=1.0, br"))
+	assert.Equal(t, "deflate", negotiateContentEncoding("deflate"))
+	assert.Equal(t, "deflate", negotiateContentEncoding("gzip;q=0, deflate"))
+	assert.Equal(t, "gzip", negotiateContentEncoding("*"))
+	assert.Equal(t, "deflate", negotiateContentEncoding("*, gzip;q=0"))
+	assert.Equal(t, "", negotiateContentEncoding("br, identity"))
+}
+
+func TestWriteCompressedResponse(t *testing.T) {
+	largeBody := []byte(strings.Repeat(`{"name":"team","score":42}`, 100))
+
+	t.Run("compresses large responses with gzip", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/balancer/api/score-board/top", nil)
+		req.Header.Set("Accept-Encoding", "gzip, deflate")
+		rr := httptest.NewRecorder()
+
+		writeCompressedResponse(rr, req, largeBody)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "gzip", rr.Header().Get("Content-Encoding"))
+		assert.Equal(t, "Accept-Encoding", rr.Header().Get("Vary"))
+		assert.Less(t, rr.Body.Len(), len(largeBody))
+
+		reader, err := gzip.NewReader(rr.Body)
+		assert.Nil(t, err)
+		decompressed, err := io.ReadAll(reader)
+		assert.Nil(t, err)
+		assert.Equal(t, largeBody, decompressed)
+	})
+
+	t.Run("compresses large responses with deflate", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/balancer/api/score-board/top", nil)
+		req.Header.Set("Accept-Encoding", "deflate")
+		rr := httptest.NewRecorder()
+
+		writeCompressedResponse(rr, req, largeBody)
+
+		assert.Equal(t, "deflate", rr.Header().Get("Content-Encoding"))
+		reader, err := zlib.NewReader(bytes.NewReader(rr.Body.Bytes()))
+		assert.Nil(t, err)
+		decompressed, err := io.ReadAll(reader)
+		assert.Nil(t, err)
+		assert.Equal(t, largeBody, decompressed)
+	})
+
+	t.Run("does not compress small responses", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/balancer/api/score-board/top", nil)
+		req.Header.Set("Accept-Encoding", "gzip")
+		rr := httptest.NewRecorder()
+
+		writeCompressedResponse(rr, req, []byte(`{"teams":[]}`))
+
+		assert.Equal(t, "", rr.Header().Get("Content-Encoding"))
+		assert.Equal(t, `{"teams":[]}`, rr.Body.String())
+	})
+
+	t.Run("does not compress when the client does not accept it", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/balancer/api/score-board/top", nil)
+		rr := httptest.NewRecorder()
+
+		writeCompressedResponse(rr, req, largeBody)
+
+		assert.Equal(t, "", rr.Header().Get("Content-Encoding"))
+		assert.Equal(t, largeBody, rr.Body.Bytes())
+	})
+}
@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"net/http"
+
+	"github.com/juice-shop/multi-juicer/balancer/pkg/scoring"
+)
+
+// handleHealth is used as liveness probe. Fails if the scoring watcher died, so that kubernetes restarts the balancer instead of leaving the scoreboard frozen
+func handleHealth(scoringService *scoring.ScoringService) http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if scoringService != nil && !scoringService.IsWatcherAlive() {
+				http.Error(w, "scoring watcher stopped receiving updates", http.StatusServiceUnavailable)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte("OK"))
+		},
+	)
+}
@@ -0,0 +1,37 @@
+package routes
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/juice-shop/multi-juicer/balancer/pkg/scoring"
+	"github.com/juice-shop/multi-juicer/balancer/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHealthHandler(t *testing.T) {
+	t.Run("is healthy while the scoring watcher is alive", func(t *testing.T) {
+		server := http.NewServeMux()
+		bu := testutil.NewTestBundle()
+		AddRoutes(server, bu, scoring.NewScoringService(bu))
+
+		req, _ := http.NewRequest("GET", "/balancer/api/health", nil)
+		rr := httptest.NewRecorder()
+		server.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "OK", rr.Body.String())
+	})
+
+	t.Run("is healthy without a scoring service", func(t *testing.T) {
+		server := http.NewServeMux()
+		AddRoutes(server, testutil.NewTestBundle(), nil)
+
+		req, _ := http.NewRequest("GET", "/balancer/api/health", nil)
+		rr := httptest.NewRecorder()
+		server.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+}
@@ -1,7 +1,6 @@
 package routes
 
 import (
-	"encoding/json"
 	"net/http"
 	"time"
 
@@ -22,6 +21,8 @@ type IndividualScore struct {
 	SolvedChallenges []SolvedChallenge `json:"solvedChallenges"`
 	Position         int               `json:"position"`
 	TotalTeams       int               `json:"totalTeams"`
+	// NewlySolved are the keys of the challenges solved with the update. Only set on responses to wait-for-update-after requests
+	NewlySolved []string `json:"newlySolved,omitempty"`
 }
 
 func handleIndividualScore(bundle *b.Bundle, scoringService *scoring.ScoringService) http.Handler {
@@ -33,6 +34,11 @@ func handleIndividualScore(bundle *b.Bundle, scoringService *scoring.ScoringServ
 
 	return http.HandlerFunc(
 		func(responseWriter http.ResponseWriter, req *http.Request) {
+			naming, err := responseFieldNaming(bundle, req)
+			if err != nil {
+				http.Error(responseWriter, err.Error(), http.StatusNotAcceptable)
+				return
+			}
 			team := req.PathValue("team")
 
 			if !isValidTeamName(team) {
@@ -40,9 +46,19 @@ func handleIndividualScore(bundle *b.Bundle, scoringService *scoring.ScoringServ
 				return
 			}
 
+			// Teams are identified by the name shown on the public scoreboard, which is their alias if the scoreboard is anonymized
+			// Both the lookup and the wait read the public scoreboard, so that the route doesn't reveal scores after a freeze
+			scores, _, _ := scoringService.GetPublicTopScoresWithLastUpdate()
+			teamScore := findTeamByPublicName(bundle, scores, team)
+			if teamScore == nil {
+				http.Error(responseWriter, "team not found", http.StatusNotFound)
+				return
+			}
+			teamCount := len(scores)
+
 			// Polling Logic
 			// If the request has a wait-for-update-after query parameter, we will wait for updates
-			var teamScore *scoring.TeamScore
+			var newlySolved []string
 			waitForUpdate := req.URL.Query().Get("wait-for-update-after")
 
 			if waitForUpdate != "" {
@@ -51,27 +67,25 @@ func handleIndividualScore(bundle *b.Bundle, scoringService *scoring.ScoringServ
 					http.Error(responseWriter, "Invalid time format for wait-for-update-after", http.StatusBadRequest)
 					return
 				}
-				// Use the WaitForTeamUpdatesNewerThan function from the scoring service
-				teamScore = scoringService.WaitForTeamUpdatesNewerThan(req.Context(), team, lastSeenUpdate)
+				teamScore, teamCount = scoringService.WaitForPublicTeamUpdatesNewerThan(req.Context(), teamScore.Name, lastSeenUpdate)
 				if teamScore == nil {
 					// This means the request timed out or was canceled, with no new updates.
 					// A 204 No Content response is appropriate here.
 					responseWriter.WriteHeader(http.StatusNoContent)
 					return
 				}
-			} else {
-				var ok bool
-				teamScore, ok = scoringService.GetScoreForTeam(team)
-				if !ok {
-					http.Error(responseWriter, "team not found", http.StatusNotFound)
-					return
-				}
+				newlySolved = teamScore.NewlySolved
 			}
 
-			teamCount := len(scoringService.GetScores())
+			challenges := teamScore.Challenges
+			if !canSeeTeamDetails(bundle, req, teamScore.Name) {
+				// other teams only get to see the name, score and position
+				challenges = []scoring.ChallengeProgress{}
+				newlySolved = nil
+			}
 
-			solvedChallenges := make([]SolvedChallenge, len(teamScore.Challenges))
-			for i, challenge := range teamScore.Challenges {
+			solvedChallenges := make([]SolvedChallenge, len(challenges))
+			for i, challenge := range challenges {
 				solvedChallenges[i] = SolvedChallenge{
 					Key:        challenge.Key,
 					Name:       challengesByKeys[challenge.Key].Name,
@@ -81,14 +95,15 @@ func handleIndividualScore(bundle *b.Bundle, scoringService *scoring.ScoringServ
 			}
 
 			response := IndividualScore{
-				Name:             team,
+				Name:             bundle.PublicTeamName(teamScore.Name),
 				Score:            teamScore.Score,
 				Position:         teamScore.Position,
 				TotalTeams:       teamCount,
 				SolvedChallenges: solvedChallenges,
+				NewlySolved:      newlySolved,
 			}
 
-			responseBytes, err := json.Marshal(response)
+			responseBytes, err := marshalWithFieldNaming(response, naming)
 			if err != nil {
 				bundle.Log.Printf("Failed to marshal response: %s", err)
 				http.Error(responseWriter, "", http.StatusInternalServerError)
@@ -57,6 +57,75 @@ func TestIndividualScoreHandler(t *testing.T) {
 		assert.JSONEq(t, `{"name":"foobar","score":10,"position":1,"solvedChallenges":[{"key":"scoreBoardChallenge","name":"Score Board","difficulty":1,"solvedAt":"2024-11-01T19:55:48Z"}],"totalTeams":1}`, rr.Body.String())
 	})
 
+	t.Run("only returns the solved challenges to the team itself if team details are private", func(t *testing.T) {
+		server := http.NewServeMux()
+		clientset := fake.NewSimpleClientset(
+			createTeam(team, `[{"key":"scoreBoardChallenge","solvedAt":"2024-11-01T19:55:48.211Z"}]`, "1"),
+		)
+		bundle := testutil.NewTestBundleWithCustomFakeClient(clientset)
+		bundle.Config.PrivateTeamDetails = true
+		scoringService := scoring.NewScoringService(bundle)
+		scoringService.CalculateAndCacheScoreBoard(context.Background())
+		AddRoutes(server, bundle, scoringService)
+
+		getScoreAs := func(requestingTeam string) string {
+			req, _ := http.NewRequest("GET", fmt.Sprintf("/balancer/api/score-board/teams/%s/score", team), nil)
+			if requestingTeam != "" {
+				req.AddCookie(&http.Cookie{Name: "team", Value: testutil.SignTestTeamname(requestingTeam)})
+			}
+			rr := httptest.NewRecorder()
+			server.ServeHTTP(rr, req)
+			assert.Equal(t, http.StatusOK, rr.Code)
+			return rr.Body.String()
+		}
+
+		assert.JSONEq(t, `{"name":"foobar","score":10,"position":1,"solvedChallenges":[],"totalTeams":1}`, getScoreAs(""))
+		assert.JSONEq(t, `{"name":"foobar","score":10,"position":1,"solvedChallenges":[],"totalTeams":1}`, getScoreAs("barfoo"))
+		assert.JSONEq(t, `{"name":"foobar","score":10,"position":1,"solvedChallenges":[{"key":"scoreBoardChallenge","name":"Score Board","difficulty":1,"solvedAt":"2024-11-01T19:55:48Z"}],"totalTeams":1}`, getScoreAs("foobar"))
+		assert.JSONEq(t, `{"name":"foobar","score":10,"position":1,"solvedChallenges":[{"key":"scoreBoardChallenge","name":"Score Board","difficulty":1,"solvedAt":"2024-11-01T19:55:48Z"}],"totalTeams":1}`, getScoreAs("admin"))
+	})
+
+	t.Run("returns the score as of the freeze once the scoreboard is frozen", func(t *testing.T) {
+		clientset := fake.NewSimpleClientset(
+			createTeamWithSolvedChallenges(team, `[{"key":"scoreBoardChallenge","solvedAt":"2024-11-01T19:55:48.211Z"}]`),
+		)
+		bundle, scoringService := newFrozenScoringService(t, clientset, team, `[{"key":"scoreBoardChallenge","solvedAt":"2024-11-01T19:55:48.211Z"},{"key":"nullByteChallenge","solvedAt":"2024-11-01T20:00:00Z"}]`)
+		server := http.NewServeMux()
+		AddRoutes(server, bundle, scoringService)
+
+		req, _ := http.NewRequest("GET", fmt.Sprintf("/balancer/api/score-board/teams/%s/score", team), nil)
+		rr := httptest.NewRecorder()
+		server.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.JSONEq(t, `{"name":"foobar","score":10,"position":1,"solvedChallenges":[{"key":"scoreBoardChallenge","name":"Score Board","difficulty":1,"solvedAt":"2024-11-01T19:55:48Z"}],"totalTeams":1}`, rr.Body.String())
+	})
+
+	t.Run("identifies teams by their alias if the scoreboard is anonymized", func(t *testing.T) {
+		server := http.NewServeMux()
+		clientset := fake.NewSimpleClientset(
+			createTeam(team, `[{"key":"scoreBoardChallenge","solvedAt":"2024-11-01T19:55:48.211Z"}]`, "1"),
+		)
+		bundle := testutil.NewTestBundleWithCustomFakeClient(clientset)
+		bundle.Config.AnonymizePublicScoreboard = true
+		scoringService := scoring.NewScoringService(bundle)
+		scoringService.CalculateAndCacheScoreBoard(context.Background())
+		AddRoutes(server, bundle, scoringService)
+		alias := bundle.PublicTeamName(team)
+
+		req, _ := http.NewRequest("GET", fmt.Sprintf("/balancer/api/score-board/teams/%s/score", team), nil)
+		rr := httptest.NewRecorder()
+		server.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusNotFound, rr.Code, "Should not find teams by their real name")
+
+		req, _ = http.NewRequest("GET", fmt.Sprintf("/balancer/api/score-board/teams/%s/score", alias), nil)
+		rr = httptest.NewRecorder()
+		server.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.JSONEq(t, fmt.Sprintf(`{"name":"%s","score":10,"position":1,"solvedChallenges":[{"key":"scoreBoardChallenge","name":"Score Board","difficulty":1,"solvedAt":"2024-11-01T19:55:48Z"}],"totalTeams":1}`, alias), rr.Body.String())
+		assert.NotContains(t, rr.Body.String(), team)
+	})
+
 	t.Run("returns a 404 if the scores haven't been calculated yet", func(t *testing.T) {
 		req, _ := http.NewRequest("GET", fmt.Sprintf("/balancer/api/score-board/teams/%s/score", team), nil)
 		rr := httptest.NewRecorder()
@@ -6,7 +6,6 @@ import (
 	"fmt"
 	"io"
 	"net/http"
-	"regexp"
 	"time"
 
 	"golang.org/x/crypto/bcrypt"
@@ -43,16 +42,37 @@ func init() {
 }
 
 func handleTeamJoin(bundle *bundle.Bundle) http.Handler {
+	creationLimiter := newTeamCreationLimiter(bundle.Config.TeamCreationRateLimit)
+
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		team := r.PathValue("team")
 
-		if team == "admin" {
+		if bundle.IsAdminTeam(team) {
 			handleAdminLogin(bundle, w, r)
 			return
 		}
 
 		deployment, err := getDeployment(r.Context(), bundle, team)
 		if err != nil && errors.IsNotFound(err) {
+			// admins and existing teams can still log in, only the creation of new teams is blocked
+			if bundle.IsReadOnly() {
+				writeJSONError(w, http.StatusLocked, "read_only", "balancer is in read-only mode")
+				return
+			}
+			clientIP := getClientIP(bundle, r)
+			if allowed, retryAfter := creationLimiter.allowClient(clientIP, time.Now()); !allowed {
+				bundle.Log.Printf("Rate limited team creation of team '%s' for client '%s'", team, clientIP)
+				writeTooManyRequestsResponse(w, retryAfter)
+				return
+			}
+			acquired, releaseCreationSlot := creationLimiter.acquireCreationSlot()
+			if !acquired {
+				bundle.Log.Printf("Too many teams are being created at the same time. Rejecting creation of team '%s'", team)
+				writeTooManyRequestsResponse(w, concurrentCreationRetryAfter)
+				return
+			}
+			defer releaseCreationSlot()
+
 			isMaxLimitReached, err := isMaxInstanceLimitReached(r.Context(), bundle)
 			if err != nil {
 				http.Error(w, "failed to check max instance limit", http.StatusInternalServerError)
@@ -94,7 +114,7 @@ func handleAdminLogin(bundle *bundle.Bundle, w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	err = setSignedTeamCookie(bundle, "admin", w)
+	err = setSignedTeamCookie(bundle, bundle.AdminTeamName(), w)
 	if err != nil {
 		http.Error(w, "failed to sign team cookie", http.StatusInternalServerError)
 		return
@@ -114,17 +134,15 @@ func getDeployment(context context.Context, bundle *bundle.Bundle, team string)
 	)
 }
 
-var teamNamePatternString = "[a-z0-9]([-a-z0-9])+[a-z0-9]"
-var validTeamnamePattern = regexp.MustCompile("^" + teamNamePatternString + "$")
+var teamNamePatternString = bundle.TeamNamePattern
 
 func isValidTeamName(s string) bool {
-	matched := validTeamnamePattern.MatchString(s)
-	return matched && len(s) <= 16
+	return bundle.IsValidTeamName(s)
 }
 
 func isMaxInstanceLimitReached(context context.Context, bundle *bundle.Bundle) (bool, error) {
 	deployments, err := bundle.ClientSet.AppsV1().Deployments(bundle.RuntimeEnvironment.Namespace).List(context, metav1.ListOptions{
-		LabelSelector: "app.kubernetes.io/name=juice-shop,app.kubernetes.io/part-of=multi-juicer",
+		LabelSelector: bundle.JuiceShopInstanceLabelSelector(),
 	})
 	if err != nil {
 		return false, fmt.Errorf("failed to list deployments: %w", err)
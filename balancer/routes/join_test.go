@@ -241,6 +241,61 @@ func TestJoinHandler(t *testing.T) {
 		assert.Equal(t, "", rr.Header().Get("Set-Cookie"))
 	})
 
+	t.Run("refuses to create new teams while the balancer is read-only", func(t *testing.T) {
+		req, _ := http.NewRequest("POST", fmt.Sprintf("/balancer/api/teams/%s/join", team), nil)
+		rr := httptest.NewRecorder()
+
+		server := http.NewServeMux()
+
+		clientset := fake.NewSimpleClientset(balancerDeployment)
+		bundle := testutil.NewTestBundleWithCustomFakeClient(clientset)
+		bundle.SetReadOnly(true)
+		AddRoutes(server, bundle, nil)
+
+		server.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusLocked, rr.Code)
+		assert.Equal(t, "", rr.Header().Get("Set-Cookie"))
+		for _, action := range clientset.Actions() {
+			assert.NotEqual(t, "create", action.GetVerb())
+		}
+	})
+
+	t.Run("existing teams can still log in while the balancer is read-only", func(t *testing.T) {
+		jsonPayload, _ := json.Marshal(map[string]string{"passcode": "02101791"})
+		req, _ := http.NewRequest("POST", fmt.Sprintf("/balancer/api/teams/%s/join", team), bytes.NewReader(jsonPayload))
+		rr := httptest.NewRecorder()
+
+		server := http.NewServeMux()
+
+		clientset := fake.NewSimpleClientset(balancerDeployment, createTeam(team))
+		bundle := testutil.NewTestBundleWithCustomFakeClient(clientset)
+		bundle.SetReadOnly(true)
+		AddRoutes(server, bundle, nil)
+
+		server.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Regexp(t, regexp.MustCompile(`team=foobar\..*; Path=/; HttpOnly; SameSite=Strict`), rr.Header().Get("Set-Cookie"))
+	})
+
+	t.Run("admins can still log in while the balancer is read-only", func(t *testing.T) {
+		jsonPayload, _ := json.Marshal(map[string]string{"passcode": "mock-admin-password"})
+		req, _ := http.NewRequest("POST", "/balancer/api/teams/admin/join", bytes.NewReader(jsonPayload))
+		rr := httptest.NewRecorder()
+
+		server := http.NewServeMux()
+
+		bundle := testutil.NewTestBundle()
+		bundle.SetReadOnly(true)
+		AddRoutes(server, bundle, nil)
+
+		server.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Regexp(t, regexp.MustCompile(`team=admin\..*; Path=/; HttpOnly; SameSite=Strict`), rr.Header().Get("Set-Cookie"))
+	})
+
 	t.Run("allows admins login with the correct passcode", func(t *testing.T) {
 		jsonPayload, _ := json.Marshal(map[string]string{"passcode": "mock-admin-password"})
 		req, _ := http.NewRequest("POST", "/balancer/api/teams/admin/join", bytes.NewReader(jsonPayload))
@@ -257,6 +312,37 @@ func TestJoinHandler(t *testing.T) {
 		assert.Regexp(t, regexp.MustCompile(`team=admin\..*; Path=/; HttpOnly; SameSite=Strict`), rr.Header().Get("Set-Cookie"))
 	})
 
+	t.Run("uses the configured admin team name for the admin login", func(t *testing.T) {
+		jsonPayload, _ := json.Marshal(map[string]string{"passcode": "mock-admin-password"})
+		req, _ := http.NewRequest("POST", "/balancer/api/teams/organizers/join", bytes.NewReader(jsonPayload))
+		rr := httptest.NewRecorder()
+
+		server := http.NewServeMux()
+
+		clientset := fake.NewSimpleClientset(balancerDeployment)
+		bundle := testutil.NewTestBundleWithCustomFakeClient(clientset)
+		bundle.Config.AdminConfig.TeamName = "organizers"
+		AddRoutes(server, bundle, nil)
+
+		server.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Regexp(t, regexp.MustCompile(`team=organizers\..*; Path=/; HttpOnly; SameSite=Strict`), rr.Header().Get("Set-Cookie"))
+		assert.Len(t, clientset.Actions(), 0)
+
+		req, _ = http.NewRequest("GET", "/balancer/api/admin/all", nil)
+		req.Header.Set("Cookie", fmt.Sprintf("team=%s", testutil.SignTestTeamname("admin")))
+		rr = httptest.NewRecorder()
+		server.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusUnauthorized, rr.Code, "The default admin team name shouldn't be an admin anymore")
+
+		req, _ = http.NewRequest("GET", "/balancer/api/admin/all", nil)
+		req.Header.Set("Cookie", fmt.Sprintf("team=%s", testutil.SignTestTeamname("organizers")))
+		rr = httptest.NewRecorder()
+		server.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+
 	t.Run("admin login returns usual 'requires auth' response when it get's no request body passed", func(t *testing.T) {
 		req, _ := http.NewRequest("POST", "/balancer/api/teams/admin/join", nil)
 		rr := httptest.NewRecorder()
@@ -304,4 +390,33 @@ func TestJoinHandler(t *testing.T) {
 		assert.Equal(t, http.StatusOK, rr.Code)
 		assert.Len(t, clientset.Actions(), 0)
 	})
+
+	t.Run("rate limits team creation per client", func(t *testing.T) {
+		server := http.NewServeMux()
+
+		clientset := fake.NewSimpleClientset(balancerDeployment)
+		bundle := testutil.NewTestBundleWithCustomFakeClient(clientset)
+		bundle.Config.TeamCreationRateLimit.PerClientPerMinute = 1
+		AddRoutes(server, bundle, nil)
+
+		createTeamFrom := func(team string, remoteAddr string) *httptest.ResponseRecorder {
+			req, _ := http.NewRequest("POST", fmt.Sprintf("/balancer/api/teams/%s/join", team), nil)
+			req.RemoteAddr = remoteAddr
+			rr := httptest.NewRecorder()
+			server.ServeHTTP(rr, req)
+			return rr
+		}
+
+		assert.Equal(t, http.StatusOK, createTeamFrom("team-one", "10.0.0.1:41234").Code)
+
+		rateLimited := createTeamFrom("team-two", "10.0.0.1:41235")
+		assert.Equal(t, http.StatusTooManyRequests, rateLimited.Code)
+		assert.NotEmpty(t, rateLimited.Header().Get("Retry-After"))
+
+		// other clients are not affected
+		assert.Equal(t, http.StatusOK, createTeamFrom("team-three", "10.0.0.2:41234").Code)
+
+		_, err := clientset.AppsV1().Deployments("test-namespace").Get(context.Background(), "juiceshop-team-two", metav1.GetOptions{})
+		assert.Error(t, err)
+	})
 }
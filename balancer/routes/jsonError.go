@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// ErrorResponse is the body of failed admin requests, so that tooling built against the admin api can tell errors apart by their code instead of parsing messages
+type ErrorResponse struct {
+	Error string `json:"error"`
+	Code  string `json:"code"`
+}
+
+// writeJSONError is the json counterpart of http.Error, used by the admin routes
+func writeJSONError(responseWriter http.ResponseWriter, status int, code string, message string) {
+	responseBody, _ := json.Marshal(ErrorResponse{Error: message, Code: code})
+	responseWriter.Header().Set("Content-Type", "application/json")
+	responseWriter.Header().Set("X-Content-Type-Options", "nosniff")
+	responseWriter.WriteHeader(status)
+	responseWriter.Write(responseBody)
+}
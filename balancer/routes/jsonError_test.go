@@ -0,0 +1,19 @@
+package routes
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteJSONError(t *testing.T) {
+	rr := httptest.NewRecorder()
+	writeJSONError(rr, http.StatusBadRequest, "invalid_team_name", "invalid team name")
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+	assert.Equal(t, "nosniff", rr.Header().Get("X-Content-Type-Options"))
+	assert.JSONEq(t, `{"error":"invalid team name","code":"invalid_team_name"}`, rr.Body.String())
+}
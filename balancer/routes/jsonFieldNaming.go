@@ -0,0 +1,90 @@
+package routes
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+	"unicode"
+
+	b "github.com/juice-shop/multi-juicer/balancer/pkg/bundle"
+)
+
+// responseFieldNaming returns the field naming requested via the naming parameter of the Accept header, e.g. "application/json; naming=snake_case".
+// Falls back to the configured jsonFieldNaming if the parameter isn't set
+func responseFieldNaming(bundle *b.Bundle, req *http.Request) (string, error) {
+	for _, accepted := range strings.Split(req.Header.Get("Accept"), ",") {
+		_, params, err := mime.ParseMediaType(accepted)
+		if err != nil {
+			continue
+		}
+		naming, ok := params["naming"]
+		if !ok {
+			continue
+		}
+		if naming != b.JSONFieldNamingCamelCase && naming != b.JSONFieldNamingSnakeCase {
+			return "", fmt.Errorf("naming must be either '%s' or '%s'", b.JSONFieldNamingCamelCase, b.JSONFieldNamingSnakeCase)
+		}
+		return naming, nil
+	}
+	if bundle.Config.JSONFieldNaming == "" {
+		return b.JSONFieldNamingCamelCase, nil
+	}
+	return bundle.Config.JSONFieldNaming, nil
+}
+
+// marshalWithFieldNaming marshals the response and renames all object keys to the requested naming. Only meant for responses which don't use data (e.g. team names) as object keys
+func marshalWithFieldNaming(response any, naming string) ([]byte, error) {
+	responseBytes, err := json.Marshal(response)
+	if err != nil || naming != b.JSONFieldNamingSnakeCase {
+		return responseBytes, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(responseBytes))
+	// keeps numbers as they are instead of converting them to floats
+	decoder.UseNumber()
+	var decoded any
+	if err := decoder.Decode(&decoded); err != nil {
+		return nil, err
+	}
+	return json.Marshal(renameKeysToSnakeCase(decoded))
+}
+
+func renameKeysToSnakeCase(value any) any {
+	switch typed := value.(type) {
+	case map[string]any:
+		renamed := make(map[string]any, len(typed))
+		for key, nested := range typed {
+			renamed[toSnakeCase(key)] = renameKeysToSnakeCase(nested)
+		}
+		return renamed
+	case []any:
+		for i := range typed {
+			typed[i] = renameKeysToSnakeCase(typed[i])
+		}
+		return typed
+	default:
+		return value
+	}
+}
+
+// toSnakeCase converts camelCase and PascalCase names, e.g. "solvedChallengeCount" to "solved_challenge_count" and "IsFirstSolve" to "is_first_solve"
+func toSnakeCase(name string) string {
+	runes := []rune(name)
+	var builder strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			previousIsLower := i > 0 && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1]))
+			endsAcronym := i > 0 && i+1 < len(runes) && unicode.IsUpper(runes[i-1]) && unicode.IsLower(runes[i+1])
+			if previousIsLower || endsAcronym {
+				builder.WriteRune('_')
+			}
+			builder.WriteRune(unicode.ToLower(r))
+			continue
+		}
+		builder.WriteRune(r)
+	}
+	return builder.String()
+}
@@ -0,0 +1,63 @@
+package routes
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/juice-shop/multi-juicer/balancer/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToSnakeCase(t *testing.T) {
+	assert.Equal(t, "name", toSnakeCase("name"))
+	assert.Equal(t, "solved_challenge_count", toSnakeCase("solvedChallengeCount"))
+	assert.Equal(t, "is_first_solve", toSnakeCase("IsFirstSolve"))
+	assert.Equal(t, "solved_at_a", toSnakeCase("solvedAtA"))
+	assert.Equal(t, "api_token", toSnakeCase("APIToken"))
+	assert.Equal(t, "already_snake_case", toSnakeCase("already_snake_case"))
+}
+
+func TestResponseFieldNaming(t *testing.T) {
+	requestAccepting := func(accept string) *http.Request {
+		req, _ := http.NewRequest("GET", "/balancer/api/score-board/top", nil)
+		req.Header.Set("Accept", accept)
+		return req
+	}
+
+	bundle := testutil.NewTestBundle()
+	naming, err := responseFieldNaming(bundle, requestAccepting(""))
+	assert.NoError(t, err)
+	assert.Equal(t, "camelCase", naming, "Should default to the current field names")
+
+	naming, err = responseFieldNaming(bundle, requestAccepting("text/html, application/json; naming=snake_case"))
+	assert.NoError(t, err)
+	assert.Equal(t, "snake_case", naming)
+
+	_, err = responseFieldNaming(bundle, requestAccepting("application/json; naming=kebab-case"))
+	assert.Error(t, err)
+
+	bundle.Config.JSONFieldNaming = "snake_case"
+	naming, err = responseFieldNaming(bundle, requestAccepting("application/json"))
+	assert.NoError(t, err)
+	assert.Equal(t, "snake_case", naming, "Should fall back to the configured naming")
+
+	naming, err = responseFieldNaming(bundle, requestAccepting("application/json; naming=camelCase"))
+	assert.NoError(t, err)
+	assert.Equal(t, "camelCase", naming, "Clients can override the configured naming")
+}
+
+func TestMarshalWithFieldNaming(t *testing.T) {
+	response := CompareTeamsResponse{
+		A:             &TeamScore{Name: "foobar", Score: 50, Position: 1, SolvedChallengeCount: 2},
+		OnlySolvedByA: []string{"scoreBoardChallenge"},
+		Shared:        []SharedChallenge{{Key: "nullByteChallenge", SolvedAtA: "2024-11-01T20:10:00Z", FirstSolvedBy: "barfoo"}},
+	}
+
+	responseBytes, err := marshalWithFieldNaming(response, "camelCase")
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"a":{"name":"foobar","score":50,"position":1,"solvedChallengeCount":2},"b":null,"onlySolvedByA":["scoreBoardChallenge"],"onlySolvedByB":null,"shared":[{"key":"nullByteChallenge","solvedAtA":"2024-11-01T20:10:00Z","solvedAtB":"","firstSolvedBy":"barfoo"}]}`, string(responseBytes))
+
+	responseBytes, err = marshalWithFieldNaming(response, "snake_case")
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"a":{"name":"foobar","score":50,"position":1,"solved_challenge_count":2},"b":null,"only_solved_by_a":["scoreBoardChallenge"],"only_solved_by_b":null,"shared":[{"key":"nullByteChallenge","solved_at_a":"2024-11-01T20:10:00Z","solved_at_b":"","first_solved_by":"barfoo"}]}`, string(responseBytes), "Should only rename the keys, not the values")
+}
@@ -20,11 +20,19 @@ import (
 
 var (
 	instanceUpCache = map[string]int64{}
-	cacheMutex      = &sync.Mutex{}
+	// unix millis of the last lastRequest annotation update per team, used to throttle the updates
+	lastRequestUpdateCache = map[string]int64{}
+	cacheMutex             = &sync.Mutex{}
 )
 
+// the lastRequest annotation only needs to be precise enough for the idle detection of the cleaner, which uses a grace period of hours
+const lastRequestUpdateInterval = 1 * time.Minute
+
 func clearInstanceUpCache() {
+	cacheMutex.Lock()
+	defer cacheMutex.Unlock()
 	instanceUpCache = map[string]int64{}
+	lastRequestUpdateCache = map[string]int64{}
 }
 
 // newReverseProxy creates a reverse proxy for a given target URL.
@@ -64,6 +72,14 @@ func handleProxy(bundle *bundle.Bundle) http.Handler {
 				}
 			}
 
+			if shouldUpdateLastRequestTimestamp(team) {
+				err = updateLastRequestTimestamp(req.Context(), bundle, team)
+				if err != nil {
+					// we will continue here, as a working proxy is more important than a up to date timestamp.
+					bundle.Log.Printf("failed to update last request time stamp on deployment. last request timestamps shown on the admin page might be out of sync.")
+				}
+			}
+
 			target := bundle.GetJuiceShopUrlForTeam(team, bundle)
 			bundle.Log.Printf("Proxy for team (%s): %s %s", team, req.Method, req.URL)
 			// Rewrite the request to the target server
@@ -74,6 +90,8 @@ func handleProxy(bundle *bundle.Bundle) http.Handler {
 
 // checks if the instance uptime status was checked in the last ten seconds by looking into the instanceUpCache
 func wasInstanceUptimeStatusCheckedRecently(team string) bool {
+	cacheMutex.Lock()
+	defer cacheMutex.Unlock()
 	lastConnect, ok := instanceUpCache[team]
 	return ok && lastConnect > time.Now().Add(-10*time.Second).UnixMilli()
 }
@@ -95,16 +113,24 @@ func isInstanceUp(context context.Context, bundle *bundle.Bundle, team string) i
 		bundle.Log.Printf("Failed to lookup if a instance is up in the kubernetes api. Assuming it's missing: %s", err)
 		return instanceMissing
 	} else if deployment.Status.ReadyReplicas > 0 {
-		err = updateLastRequestTimestamp(context, bundle, team)
-		if err != nil {
-			// we will continue here, as a working proxy is more important than a up to date timestamp.
-			bundle.Log.Printf("failed to update last request time stamp on deployment. last request timestamps shown on the admin page might be out of sync.")
-		}
 		return instanceUp
 	}
 	return instanceDown
 }
 
+// shouldUpdateLastRequestTimestamp checks if the lastRequest annotation of the team is due for an update and reserves the update if so.
+// Throttled to once per lastRequestUpdateInterval, as every proxied request would otherwise cause a write to the kubernetes api
+func shouldUpdateLastRequestTimestamp(team string) bool {
+	cacheMutex.Lock()
+	defer cacheMutex.Unlock()
+	now := time.Now().UnixMilli()
+	if lastUpdate, ok := lastRequestUpdateCache[team]; ok && lastUpdate > now-lastRequestUpdateInterval.Milliseconds() {
+		return false
+	}
+	lastRequestUpdateCache[team] = now
+	return true
+}
+
 type UpdateProgressDeploymentDiff struct {
 	Metadata UpdateProgressDeploymentMetadata `json:"metadata"`
 }
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"sync"
 	"testing"
 
 	"github.com/juice-shop/multi-juicer/balancer/pkg/bundle"
@@ -13,6 +14,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	appsv1 "k8s.io/api/apps/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes/fake"
 )
 
@@ -147,6 +149,41 @@ func TestProxyHandler(t *testing.T) {
 		)
 	})
 
+	t.Run("updates the lastRequest annotation at most once per minute", func(t *testing.T) {
+		defer clearInstanceUpCache()
+
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer ts.Close()
+
+		server := http.NewServeMux()
+
+		clientset := fake.NewSimpleClientset(readyDeployment)
+		bu := testutil.NewTestBundleWithCustomFakeClient(clientset)
+
+		bu.GetJuiceShopUrlForTeam = func(team string, _bundle *bundle.Bundle) string {
+			return fmt.Sprintf("%s/%s/", ts.URL, team)
+		}
+		AddRoutes(server, bu, nil)
+
+		for i := 0; i < 3; i++ {
+			req, _ := http.NewRequest("GET", "/hello-world", nil)
+			req.Header.Set("Cookie", fmt.Sprintf("team=%s", testutil.SignTestTeamname(teamFoo)))
+			rr := httptest.NewRecorder()
+			server.ServeHTTP(rr, req)
+			assert.Equal(t, http.StatusOK, rr.Code)
+		}
+
+		patches := 0
+		for _, action := range clientset.Actions() {
+			if action.GetVerb() == "patch" {
+				patches++
+			}
+		}
+		assert.Equal(t, 1, patches)
+	})
+
 	t.Run("redirects to /balancer?msg=instance-restarting when the instance isn't ready", func(t *testing.T) {
 		defer clearInstanceUpCache()
 		req, _ := http.NewRequest("POST", "/hello-world", nil)
@@ -203,4 +240,41 @@ func TestProxyHandler(t *testing.T) {
 		assert.Equal(t, fmt.Sprintf("/balancer/?msg=instance-not-found&team=%s", teamFoo), rr.Header().Get("Location"))
 		assert.Empty(t, rr.Body.String())
 	})
+
+	t.Run("handles concurrent requests of multiple teams", func(t *testing.T) {
+		defer clearInstanceUpCache()
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer ts.Close()
+
+		server := http.NewServeMux()
+		teams := []string{teamFoo, "barfoo", "foofoo"}
+		deployments := []runtime.Object{}
+		for _, team := range teams {
+			deployment := readyDeployment.DeepCopy()
+			deployment.Name = fmt.Sprintf("juiceshop-%s", team)
+			deployments = append(deployments, deployment)
+		}
+		bu := testutil.NewTestBundleWithCustomFakeClient(fake.NewSimpleClientset(deployments...))
+		bu.GetJuiceShopUrlForTeam = func(team string, _bundle *bundle.Bundle) string {
+			return fmt.Sprintf("%s/%s/", ts.URL, team)
+		}
+		AddRoutes(server, bu, nil)
+
+		// run with -race to detect unsynchronized access to the instance caches
+		var wg sync.WaitGroup
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func(team string) {
+				defer wg.Done()
+				req, _ := http.NewRequest("GET", "/hello-world", nil)
+				req.Header.Set("Cookie", fmt.Sprintf("team=%s", testutil.SignTestTeamname(team)))
+				rr := httptest.NewRecorder()
+				server.ServeHTTP(rr, req)
+				assert.Equal(t, http.StatusOK, rr.Code)
+			}(teams[i%len(teams)])
+		}
+		wg.Wait()
+	})
 }
@@ -0,0 +1,114 @@
+package routes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/juice-shop/multi-juicer/balancer/pkg/bundle"
+	"github.com/juice-shop/multi-juicer/balancer/pkg/scoring"
+	"github.com/juice-shop/multi-juicer/balancer/pkg/teamcookie"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type readOnlyStatus struct {
+	ReadOnly bool `json:"readOnly"`
+}
+
+// blockWhenReadOnly rejects requests to mutating routes while the balancer is in read-only mode, e.g. to lock in the final standings at the end of an event
+func blockWhenReadOnly(bundle *bundle.Bundle, next http.Handler) http.Handler {
+	return http.HandlerFunc(
+		func(responseWriter http.ResponseWriter, req *http.Request) {
+			if bundle.IsReadOnly() {
+				writeJSONError(responseWriter, http.StatusLocked, "read_only", "balancer is in read-only mode")
+				return
+			}
+			next.ServeHTTP(responseWriter, req)
+		},
+	)
+}
+
+func handleAdminGetReadOnly(bundle *bundle.Bundle) http.Handler {
+	return http.HandlerFunc(
+		func(responseWriter http.ResponseWriter, req *http.Request) {
+			team, err := teamcookie.GetTeamFromAdminRequest(bundle, req)
+			if err != nil || !bundle.IsAdminTeam(team) {
+				writeJSONError(responseWriter, http.StatusUnauthorized, "unauthorized", "admin login required")
+				return
+			}
+			writeReadOnlyStatus(bundle, responseWriter)
+		},
+	)
+}
+
+// handleAdminSetReadOnly toggles the read-only mode. The scoreboard doesn't change while the balancer is read-only, so it gets recalculated once the mode is disabled again.
+// The mode is only kept in memory of the replica handling the request, so toggling it is rejected if the balancer runs with multiple replicas. These have to use config.readOnly instead
+func handleAdminSetReadOnly(bundle *bundle.Bundle, scoringService *scoring.ScoringService) http.Handler {
+	return http.HandlerFunc(
+		func(responseWriter http.ResponseWriter, req *http.Request) {
+			team, err := teamcookie.GetTeamFromAdminRequest(bundle, req)
+			if err != nil || !bundle.IsAdminTeam(team) {
+				writeJSONError(responseWriter, http.StatusUnauthorized, "unauthorized", "admin login required")
+				return
+			}
+
+			var status readOnlyStatus
+			if err := json.NewDecoder(req.Body).Decode(&status); err != nil {
+				writeJSONError(responseWriter, http.StatusBadRequest, "invalid_json", "invalid json")
+				return
+			}
+
+			wasReadOnly := bundle.IsReadOnly()
+			if wasReadOnly != status.ReadOnly {
+				replicas, err := getBalancerReplicas(req.Context(), bundle)
+				if err != nil {
+					bundle.Log.Printf("Failed to check the balancer replicas before toggling the read-only mode: %s", err)
+					writeJSONError(responseWriter, http.StatusInternalServerError, "internal_error", "internal server error")
+					return
+				}
+				if replicas > 1 {
+					writeJSONError(responseWriter, http.StatusConflict, "multiple_replicas", "the read-only mode can only be toggled at runtime with a single balancer replica, use config.readOnly instead")
+					return
+				}
+			}
+			bundle.SetReadOnly(status.ReadOnly)
+			if wasReadOnly != status.ReadOnly {
+				bundle.Log.Printf("Admin set the read-only mode of the balancer to %t", status.ReadOnly)
+			}
+
+			if wasReadOnly && !status.ReadOnly && scoringService != nil {
+				if err := scoringService.CalculateAndCacheScoreBoard(req.Context()); err != nil {
+					bundle.Log.Printf("Failed to recalculate the scoreboard after disabling the read-only mode: %s", err)
+				}
+			}
+
+			writeReadOnlyStatus(bundle, responseWriter)
+		},
+	)
+}
+
+// getBalancerReplicas returns the desired number of replicas of the balancer deployment
+func getBalancerReplicas(ctx context.Context, bundle *bundle.Bundle) (int32, error) {
+	balancerDeployment, err := bundle.ClientSet.AppsV1().Deployments(bundle.RuntimeEnvironment.Namespace).Get(ctx, "balancer", metav1.GetOptions{})
+	if err != nil {
+		return 0, fmt.Errorf("failed to get balancer deployment: %w", err)
+	}
+	if balancerDeployment.Spec.Replicas == nil {
+		// kubernetes defaults to a single replica
+		return 1, nil
+	}
+	return *balancerDeployment.Spec.Replicas, nil
+}
+
+func writeReadOnlyStatus(bundle *bundle.Bundle, responseWriter http.ResponseWriter) {
+	responseBytes, err := json.Marshal(readOnlyStatus{ReadOnly: bundle.IsReadOnly()})
+	if err != nil {
+		bundle.Log.Printf("Failed to marshal read-only status: %s", err)
+		writeJSONError(responseWriter, http.StatusInternalServerError, "internal_error", "internal server error")
+		return
+	}
+	responseWriter.Header().Set("Content-Type", "application/json")
+	responseWriter.WriteHeader(http.StatusOK)
+	responseWriter.Write(responseBytes)
+}
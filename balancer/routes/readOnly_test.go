@@ -0,0 +1,177 @@
+package routes
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/juice-shop/multi-juicer/balancer/pkg/scoring"
+	"github.com/juice-shop/multi-juicer/balancer/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestReadOnlyMode(t *testing.T) {
+	createTeam := func(team string, challenges string) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      fmt.Sprintf("juiceshop-%s", team),
+				Namespace: "test-namespace",
+				Annotations: map[string]string{
+					"multi-juicer.owasp-juice.shop/challenges": challenges,
+				},
+				Labels: map[string]string{
+					"app.kubernetes.io/name":    "juice-shop",
+					"app.kubernetes.io/part-of": "multi-juicer",
+					"team":                      team,
+				},
+			},
+		}
+	}
+
+	createBalancer := func(replicas int32) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "balancer",
+				Namespace: "test-namespace",
+			},
+			Spec: appsv1.DeploymentSpec{
+				Replicas: &replicas,
+			},
+		}
+	}
+
+	t.Run("mutating routes return 423 while the balancer is read-only", func(t *testing.T) {
+		clientset := fake.NewSimpleClientset()
+		bundle := testutil.NewTestBundleWithCustomFakeClient(clientset)
+		bundle.SetReadOnly(true)
+
+		server := http.NewServeMux()
+		AddRoutes(server, bundle, nil)
+
+		for _, route := range []struct {
+			method string
+			path   string
+		}{
+			{"POST", "/balancer/api/teams/reset-passcode"},
+			{"DELETE", "/balancer/api/admin/teams/foobar/delete"},
+			{"POST", "/balancer/api/admin/teams/foobar/restart"},
+			{"POST", "/balancer/api/admin/teams/foobar/reset"},
+			{"POST", "/balancer/api/admin/teams/foobar/park"},
+			{"POST", "/balancer/api/admin/teams/foobar/unpark"},
+			{"POST", "/balancer/api/admin/teams/foobar/adjust"},
+			{"POST", "/balancer/api/admin/restore"},
+		} {
+			req, _ := http.NewRequest(route.method, route.path, nil)
+			req.Header.Set("Cookie", fmt.Sprintf("team=%s", testutil.SignTestTeamname("admin")))
+			rr := httptest.NewRecorder()
+
+			server.ServeHTTP(rr, req)
+
+			assert.Equal(t, http.StatusLocked, rr.Code, route.path)
+		}
+		assert.Len(t, clientset.Actions(), 0)
+	})
+
+	t.Run("read routes stay available while the balancer is read-only", func(t *testing.T) {
+		clientset := fake.NewSimpleClientset(createTeam("foobar", "[]"))
+		bundle := testutil.NewTestBundleWithCustomFakeClient(clientset)
+		bundle.SetReadOnly(true)
+		scoringService := scoring.NewScoringService(bundle)
+		assert.NoError(t, scoringService.CalculateAndCacheScoreBoard(context.Background()))
+
+		server := http.NewServeMux()
+		AddRoutes(server, bundle, scoringService)
+
+		req, _ := http.NewRequest("GET", "/balancer/api/score-board/top", nil)
+		rr := httptest.NewRecorder()
+		server.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("toggling the read-only mode requires admin login", func(t *testing.T) {
+		bundle := testutil.NewTestBundle()
+
+		server := http.NewServeMux()
+		AddRoutes(server, bundle, nil)
+
+		req, _ := http.NewRequest("PUT", "/balancer/api/admin/read-only", bytes.NewBufferString(`{"readOnly":true}`))
+		req.Header.Set("Cookie", fmt.Sprintf("team=%s", testutil.SignTestTeamname("foobar")))
+		rr := httptest.NewRecorder()
+		server.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+		assert.False(t, bundle.IsReadOnly())
+	})
+
+	t.Run("admins can toggle the read-only mode", func(t *testing.T) {
+		bundle := testutil.NewTestBundleWithCustomFakeClient(fake.NewSimpleClientset(createBalancer(1)))
+
+		server := http.NewServeMux()
+		AddRoutes(server, bundle, nil)
+
+		req, _ := http.NewRequest("PUT", "/balancer/api/admin/read-only", bytes.NewBufferString(`{"readOnly":true}`))
+		req.Header.Set("Cookie", fmt.Sprintf("team=%s", testutil.SignTestTeamname("admin")))
+		rr := httptest.NewRecorder()
+		server.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.JSONEq(t, `{"readOnly":true}`, rr.Body.String())
+		assert.True(t, bundle.IsReadOnly())
+
+		req, _ = http.NewRequest("GET", "/balancer/api/admin/read-only", nil)
+		req.Header.Set("Cookie", fmt.Sprintf("team=%s", testutil.SignTestTeamname("admin")))
+		rr = httptest.NewRecorder()
+		server.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.JSONEq(t, `{"readOnly":true}`, rr.Body.String())
+	})
+
+	t.Run("disabling the read-only mode catches up on score changes made in the meantime", func(t *testing.T) {
+		clientset := fake.NewSimpleClientset(createTeam("foobar", "[]"), createBalancer(1))
+		bundle := testutil.NewTestBundleWithCustomFakeClient(clientset)
+		scoringService := scoring.NewScoringService(bundle)
+		assert.NoError(t, scoringService.CalculateAndCacheScoreBoard(context.Background()))
+		bundle.SetReadOnly(true)
+
+		_, err := clientset.AppsV1().Deployments("test-namespace").Update(context.Background(), createTeam("foobar", `[{"key":"scoreBoardChallenge","solvedAt":"2024-11-01T19:55:48.211Z"}]`), metav1.UpdateOptions{})
+		assert.NoError(t, err)
+
+		server := http.NewServeMux()
+		AddRoutes(server, bundle, scoringService)
+
+		req, _ := http.NewRequest("PUT", "/balancer/api/admin/read-only", bytes.NewBufferString(`{"readOnly":false}`))
+		req.Header.Set("Cookie", fmt.Sprintf("team=%s", testutil.SignTestTeamname("admin")))
+		rr := httptest.NewRecorder()
+		server.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.False(t, bundle.IsReadOnly())
+		score, ok := scoringService.GetScoreForTeam("foobar")
+		assert.True(t, ok)
+		assert.Equal(t, 10, score.Score)
+	})
+
+	t.Run("toggling the read-only mode is rejected with multiple balancer replicas", func(t *testing.T) {
+		bundle := testutil.NewTestBundleWithCustomFakeClient(fake.NewSimpleClientset(createBalancer(3)))
+
+		server := http.NewServeMux()
+		AddRoutes(server, bundle, nil)
+
+		req, _ := http.NewRequest("PUT", "/balancer/api/admin/read-only", bytes.NewBufferString(`{"readOnly":true}`))
+		req.Header.Set("Cookie", fmt.Sprintf("team=%s", testutil.SignTestTeamname("admin")))
+		rr := httptest.NewRecorder()
+		server.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusConflict, rr.Code)
+		assert.Contains(t, rr.Body.String(), "multiple_replicas")
+		assert.False(t, bundle.IsReadOnly())
+	})
+}
@@ -0,0 +1,58 @@
+package routes
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/juice-shop/multi-juicer/balancer/pkg/bundle"
+	"github.com/juice-shop/multi-juicer/balancer/pkg/scoring"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// kubernetesApiCheckInterval limits how often the readiness probe actually queries the kubernetes api, probes in between reuse the last result
+const kubernetesApiCheckInterval = 10 * time.Second
+
+// kubernetesApiCheck caches the result of the last kubernetes api connectivity check
+type kubernetesApiCheck struct {
+	mutex     sync.Mutex
+	checkedAt time.Time
+	err       error
+}
+
+// check lists a single deployment, which fails if the kubernetes api is unreachable or the RBAC role of the balancer doesn't allow listing deployments
+func (c *kubernetesApiCheck) check(ctx context.Context, bundle *bundle.Bundle) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if !c.checkedAt.IsZero() && time.Since(c.checkedAt) < kubernetesApiCheckInterval {
+		return c.err
+	}
+	_, c.err = bundle.ClientSet.AppsV1().Deployments(bundle.RuntimeEnvironment.Namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: bundle.JuiceShopInstanceLabelSelector(),
+		Limit:         1,
+	})
+	c.checkedAt = time.Now()
+	return c.err
+}
+
+// handleReadiness reports the balancer as not ready until the challenges are loaded, as the scoreboard would otherwise silently show a score of 0 for everyone.
+// Also requires the kubernetes api to be usable, so that pods don't take traffic before connectivity and RBAC permissions are confirmed
+func handleReadiness(bundle *bundle.Bundle, scoringService *scoring.ScoringService) http.Handler {
+	apiCheck := &kubernetesApiCheck{}
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if scoringService != nil && !scoringService.HasChallenges() {
+				http.Error(w, "no challenges loaded", http.StatusServiceUnavailable)
+				return
+			}
+			if err := apiCheck.check(r.Context(), bundle); err != nil {
+				bundle.Log.Printf("Readiness check failed to list deployments: %s", err)
+				http.Error(w, "kubernetes api not usable", http.StatusServiceUnavailable)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte("OK"))
+		},
+	)
+}
@@ -0,0 +1,66 @@
+package routes
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/juice-shop/multi-juicer/balancer/pkg/bundle"
+	"github.com/juice-shop/multi-juicer/balancer/pkg/scoring"
+	"github.com/juice-shop/multi-juicer/balancer/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	testcore "k8s.io/client-go/testing"
+)
+
+func TestReadinessHandler(t *testing.T) {
+	t.Run("is ready once challenges are loaded", func(t *testing.T) {
+		server := http.NewServeMux()
+		bu := testutil.NewTestBundle()
+		AddRoutes(server, bu, scoring.NewScoringService(bu))
+
+		req, _ := http.NewRequest("GET", "/balancer/api/readiness", nil)
+		rr := httptest.NewRecorder()
+		server.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "OK", rr.Body.String())
+	})
+
+	t.Run("isn't ready if no challenges are loaded", func(t *testing.T) {
+		server := http.NewServeMux()
+		bu := testutil.NewTestBundle()
+		bu.JuiceShopChallenges = []bundle.JuiceShopChallenge{}
+		AddRoutes(server, bu, scoring.NewScoringService(bu))
+
+		req, _ := http.NewRequest("GET", "/balancer/api/readiness", nil)
+		rr := httptest.NewRecorder()
+		server.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+	})
+	t.Run("isn't ready if the kubernetes api can't be used", func(t *testing.T) {
+		server := http.NewServeMux()
+		clientset := fake.NewSimpleClientset()
+		listCalls := 0
+		clientset.PrependReactor("list", "deployments", func(action testcore.Action) (bool, runtime.Object, error) {
+			listCalls++
+			return true, nil, apierrors.NewForbidden(schema.GroupResource{Group: "apps", Resource: "deployments"}, "", errors.New("rbac denied"))
+		})
+		bu := testutil.NewTestBundleWithCustomFakeClient(clientset)
+		AddRoutes(server, bu, scoring.NewScoringService(bu))
+
+		for i := 0; i < 3; i++ {
+			req, _ := http.NewRequest("GET", "/balancer/api/readiness", nil)
+			rr := httptest.NewRecorder()
+			server.ServeHTTP(rr, req)
+
+			assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+		}
+		assert.Equal(t, 1, listCalls, "Should reuse the result of the last check instead of querying the api on every probe")
+	})
+}
@@ -31,25 +31,42 @@ func AddRoutes(
 	router.Handle("GET /balancer/", handleStaticFiles(bundle))
 	router.Handle("POST /balancer/api/teams/{team}/join", handleTeamJoin(bundle))
 	router.Handle("POST /balancer/api/teams/logout", handleLogout(bundle))
-	router.Handle("POST /balancer/api/teams/reset-passcode", handleResetPasscode(bundle))
+	router.Handle("POST /balancer/api/teams/reset-passcode", blockWhenReadOnly(bundle, handleResetPasscode(bundle)))
 	router.Handle("GET /balancer/api/score-board/top", handleScoreBoard(bundle, scoringService))
 	router.Handle("GET /balancer/api/score-board/teams/{team}/score", handleIndividualScore(bundle, scoringService))
+	router.Handle("GET /balancer/api/score-board/compare", handleCompareTeams(bundle, scoringService))
+	router.Handle("GET /balancer/api/score-board/challenge-stats", handleChallengeStats(bundle, scoringService))
+	router.Handle("GET /balancer/api/score-board/challenges", handleChallengeCatalog(bundle))
+	router.Handle("GET /balancer/api/score-board/activity", handleScoreBoardActivity(bundle, scoringService))
 	router.Handle("GET /balancer/api/v2/challenges/{challengeKey}", handleChallengeDetail(bundle, scoringService))
 	router.Handle("GET /balancer/api/teams/status", handleTeamStatus(bundle, scoringService))
+	router.Handle("GET /balancer/api/teams/{team}/timeline", handleTeamTimeline(bundle, scoringService))
 	router.Handle("GET /balancer/api/v2/activity-feed", handleActivityFeed(bundle, scoringService))
 
 	router.Handle("GET /balancer/api/admin/all", handleAdminListInstances(bundle))
-	router.Handle("DELETE /balancer/api/admin/teams/{team}/delete", handleAdminDeleteInstance(bundle))
-	router.Handle("POST /balancer/api/admin/teams/{team}/restart", handleAdminRestartInstance(bundle))
-
-	router.HandleFunc("GET /balancer/api/health", func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusOK)
-		w.Write([]byte("OK"))
-	})
-	router.HandleFunc("GET /balancer/api/readiness", func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusOK)
-		w.Write([]byte("OK"))
-	})
+	router.Handle("GET /balancer/api/admin/instances/stream", handleAdminInstancesStream(bundle, scoringService))
+	router.Handle("GET /balancer/api/admin/instances/never-connected", handleAdminListNeverConnected(bundle))
+	router.Handle("GET /balancer/api/admin/stats", handleAdminStats(bundle, scoringService))
+	router.Handle("GET /balancer/api/admin/unknown-challenges", handleAdminUnknownChallenges(bundle, scoringService))
+	router.Handle("POST /balancer/api/admin/teams/bulk", blockWhenReadOnly(bundle, handleAdminBulkCreateTeams(bundle)))
+	router.Handle("DELETE /balancer/api/admin/teams/{team}/delete", blockWhenReadOnly(bundle, handleAdminDeleteInstance(bundle)))
+	router.Handle("POST /balancer/api/admin/teams/{team}/restart", blockWhenReadOnly(bundle, handleAdminRestartInstance(bundle)))
+	router.Handle("POST /balancer/api/admin/teams/{team}/reset", blockWhenReadOnly(bundle, handleAdminResetInstance(bundle)))
+	router.Handle("POST /balancer/api/admin/teams/{team}/park", blockWhenReadOnly(bundle, handleAdminParkInstance(bundle)))
+	router.Handle("POST /balancer/api/admin/teams/{team}/unpark", blockWhenReadOnly(bundle, handleAdminUnparkInstance(bundle)))
+	router.Handle("PUT /balancer/api/admin/teams/{team}/exclude-from-scoreboard", blockWhenReadOnly(bundle, handleAdminExcludeFromScoreboard(bundle)))
+	router.Handle("POST /balancer/api/admin/teams/{team}/adjust", blockWhenReadOnly(bundle, handleAdminAdjustScore(bundle)))
+	router.Handle("GET /balancer/api/admin/teams/{team}/webhook-log", handleAdminWebhookLog(bundle))
+	router.Handle("GET /balancer/api/admin/teams/{team}/raw", handleAdminRawAnnotations(bundle))
+	router.Handle("GET /balancer/api/admin/backup", handleAdminBackup(bundle))
+	router.Handle("POST /balancer/api/admin/restore", blockWhenReadOnly(bundle, handleAdminRestore(bundle)))
+	router.Handle("PUT /balancer/api/admin/scoring-config", blockWhenReadOnly(bundle, handleAdminUpdateScoringConfig(bundle, scoringService)))
+	router.Handle("GET /balancer/api/admin/read-only", handleAdminGetReadOnly(bundle))
+	router.Handle("PUT /balancer/api/admin/read-only", handleAdminSetReadOnly(bundle, scoringService))
+
+	router.Handle("GET /balancer/api/health", handleHealth(scoringService))
+	router.Handle("GET /balancer/api/readiness", handleReadiness(bundle, scoringService))
+	router.Handle("GET /balancer/api/version", handleVersion(bundle))
 }
 
 func trackRequestMetrics(next http.Handler) http.Handler {
@@ -1,8 +1,9 @@
 package routes
 
 import (
-	"encoding/json"
+	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	b "github.com/juice-shop/multi-juicer/balancer/pkg/bundle"
@@ -12,6 +13,21 @@ import (
 type ScoreBoardResponse struct {
 	TotalTeams int          `json:"totalTeams"`
 	TopTeams   []*TeamScore `json:"teams"`
+	// MatchingTeams is only set when searching for teams using the q param, it's the number of matches before the result got capped
+	MatchingTeams *int `json:"matchingTeams,omitempty"`
+	// FrozenAt is set once the scoreboard is frozen, the scores then no longer change until the end of the event
+	FrozenAt *time.Time `json:"frozenAt,omitempty"`
+	// EventStart is the time solve times are relative to in the team timelines
+	EventStart time.Time `json:"eventStart"`
+	// Event is only set if the scoreboardEventMetadata config is enabled
+	Event *ScoreBoardEvent `json:"event,omitempty"`
+}
+
+// ScoreBoardEvent describes the event, so that the UI can show it even before the first team joined
+type ScoreBoardEvent struct {
+	Name            string    `json:"name"`
+	Start           time.Time `json:"start"`
+	TotalChallenges int       `json:"totalChallenges"`
 }
 
 type TeamScore struct {
@@ -19,12 +35,22 @@ type TeamScore struct {
 	Score                int    `json:"score"`
 	Position             int    `json:"position"`
 	SolvedChallengeCount int    `json:"solvedChallengeCount"`
+	// Capped is set if the score of the team got clamped to the configured maxTeamScore
+	Capped bool `json:"capped,omitempty"`
 }
 
 func handleScoreBoard(bundle *b.Bundle, scoringService *scoring.ScoringService) http.Handler {
 	return http.HandlerFunc(
 		func(responseWriter http.ResponseWriter, req *http.Request) {
+			naming, err := responseFieldNaming(bundle, req)
+			if err != nil {
+				http.Error(responseWriter, err.Error(), http.StatusNotAcceptable)
+				return
+			}
+			// the representation depends on the requested field naming and content encoding, caches have to key on both headers
+			responseWriter.Header().Set("Vary", "Accept, Accept-Encoding")
 			var totalTeams []*scoring.TeamScore
+			var frozen bool
 
 			if req.URL.Query().Get("wait-for-update-after") != "" {
 				lastSeenUpdate, err := time.Parse(time.RFC3339, req.URL.Query().Get("wait-for-update-after"))
@@ -32,39 +58,75 @@ func handleScoreBoard(bundle *b.Bundle, scoringService *scoring.ScoringService)
 					http.Error(responseWriter, "Invalid time format", http.StatusBadRequest)
 					return
 				}
-				totalTeams = scoringService.WaitForUpdatesNewerThan(req.Context(), lastSeenUpdate)
+				totalTeams, frozen = scoringService.WaitForPublicUpdatesNewerThan(req.Context(), lastSeenUpdate)
 				if totalTeams == nil {
 					responseWriter.WriteHeader(http.StatusNoContent)
 					responseWriter.Write([]byte{})
 					return
 				}
 			} else {
-				totalTeams = scoringService.GetTopScores()
+				var lastUpdate time.Time
+				totalTeams, lastUpdate, frozen = scoringService.GetPublicTopScoresWithLastUpdate()
+
+				// the scoreboard only changes when the scores get updated, so clients polling it can skip downloading unchanged scoreboards
+				encoding := negotiateContentEncoding(req.Header.Get("Accept-Encoding"))
+				if encoding == "" {
+					encoding = "identity"
+				}
+				etag := fmt.Sprintf(`"%d-%s-%s"`, lastUpdate.UnixNano(), naming, encoding)
+				responseWriter.Header().Set("ETag", etag)
+				if etagMatches(req.Header.Get("If-None-Match"), etag) {
+					responseWriter.WriteHeader(http.StatusNotModified)
+					return
+				}
 			}
+			listedTeams := totalTeams
+			var matchingTeams *int
+			if query := req.URL.Query().Get("q"); query != "" {
+				listedTeams = filterTeamsByNamePrefix(bundle, totalTeams, query)
+				matchCount := len(listedTeams)
+				matchingTeams = &matchCount
+			}
+
 			var topTeams []*scoring.TeamScore
 			// limit score-board to calculate score for the top 24 teams only
-			if len(totalTeams) > 24 {
-				topTeams = totalTeams[:24]
+			if len(listedTeams) > 24 {
+				topTeams = listedTeams[:24]
 			} else {
-				topTeams = totalTeams
+				topTeams = listedTeams
 			}
 
 			convertedTopScores := make([]*TeamScore, len(topTeams))
 			for i, topTeam := range topTeams {
 				convertedTopScores[i] = &TeamScore{
-					Name:                 topTeam.Name,
+					Name:                 bundle.PublicTeamName(topTeam.Name),
 					Score:                topTeam.Score,
 					Position:             topTeam.Position,
 					SolvedChallengeCount: len(topTeam.Challenges),
+					Capped:               topTeam.Capped,
 				}
 			}
 
+			var frozenAt *time.Time
+			if frozen {
+				frozenAt = bundle.Config.FreezeScoreboardAt
+			}
 			response := ScoreBoardResponse{
-				TotalTeams: len(totalTeams),
-				TopTeams:   convertedTopScores,
+				TotalTeams:    len(totalTeams),
+				TopTeams:      convertedTopScores,
+				MatchingTeams: matchingTeams,
+				FrozenAt:      frozenAt,
+				EventStart:    scoringService.EventStart(),
+			}
+			if bundle.Config.ScoreboardEventMetadata {
+				response.Event = &ScoreBoardEvent{
+					Name:            bundle.Config.EventName,
+					Start:           scoringService.EventStart(),
+					TotalChallenges: len(bundle.JuiceShopChallenges),
+				}
 			}
 
-			responseBytes, err := json.Marshal(response)
+			responseBytes, err := marshalWithFieldNaming(response, naming)
 			if err != nil {
 				bundle.Log.Printf("Failed to marshal response: %s", err)
 				http.Error(responseWriter, "", http.StatusInternalServerError)
@@ -72,8 +134,34 @@ func handleScoreBoard(bundle *b.Bundle, scoringService *scoring.ScoringService)
 			}
 
 			responseWriter.Header().Set("Content-Type", "application/json")
-			responseWriter.WriteHeader(http.StatusOK)
-			responseWriter.Write(responseBytes)
+			writeCompressedResponse(responseWriter, req, responseBytes)
 		},
 	)
 }
+
+// filterTeamsByNamePrefix keeps the teams whose name starts with the prefix, ignoring case. The global position of the teams is kept as is.
+// Matches against the public team name, so that searching doesn't reveal the real names on an anonymized scoreboard
+func filterTeamsByNamePrefix(bundle *b.Bundle, teams []*scoring.TeamScore, prefix string) []*scoring.TeamScore {
+	prefix = strings.ToLower(prefix)
+	matches := []*scoring.TeamScore{}
+	for _, team := range teams {
+		if strings.HasPrefix(strings.ToLower(bundle.PublicTeamName(team.Name)), prefix) {
+			matches = append(matches, team)
+		}
+	}
+	return matches
+}
+
+// etagMatches checks if the If-None-Match header contains the etag. Weak comparison is used, as recommended for If-None-Match
+func etagMatches(ifNoneMatch string, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/juice-shop/multi-juicer/balancer/pkg/scoring"
 	"github.com/juice-shop/multi-juicer/balancer/pkg/testutil"
@@ -76,6 +77,176 @@ func TestScoreBoardHandler(t *testing.T) {
 		}, response.TopTeams)
 	})
 
+	t.Run("leaves out teams excluded from the scoreboard", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/balancer/api/score-board/top", nil)
+		rr := httptest.NewRecorder()
+
+		server := http.NewServeMux()
+		staffTeam := createTeam("staff", `[{"key":"scoreBoardChallenge","solvedAt":"2024-11-01T19:55:48.211Z"},{"key":"nullByteChallenge","solvedAt":"2024-11-01T19:55:48.211Z"}]`, "2")
+		staffTeam.Annotations[scoring.ExcludeFromScoreboardAnnotation] = "true"
+		clientset := fake.NewSimpleClientset(
+			staffTeam,
+			createTeam("barfoo", `[{"key":"scoreBoardChallenge","solvedAt":"2024-11-01T19:55:48.211Z"}]`, "1"),
+		)
+		bundle := testutil.NewTestBundleWithCustomFakeClient(clientset)
+		scoringService := scoring.NewScoringService(bundle)
+		scoringService.CalculateAndCacheScoreBoard(context.Background())
+		AddRoutes(server, bundle, scoringService)
+
+		server.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		var response ScoreBoardResponse
+		assert.Nil(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		assert.Equal(t, 1, response.TotalTeams)
+		assert.Equal(t, []*TeamScore{
+			{Name: "barfoo", Score: 10, Position: 1, SolvedChallengeCount: 1},
+		}, response.TopTeams)
+
+		staffScore, ok := scoringService.GetScoreForTeam("staff")
+		assert.True(t, ok, "Excluded teams should still be scored for the admins")
+		assert.Equal(t, 50, staffScore.Score)
+	})
+
+	t.Run("shows aliases instead of the team names if the scoreboard is anonymized", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/balancer/api/score-board/top", nil)
+		rr := httptest.NewRecorder()
+
+		server := http.NewServeMux()
+		clientset := fake.NewSimpleClientset(
+			createTeam("foobar", `[{"key":"scoreBoardChallenge","solvedAt":"2024-11-01T19:55:48.211Z"}]`, "1"),
+		)
+		bundle := testutil.NewTestBundleWithCustomFakeClient(clientset)
+		bundle.Config.AnonymizePublicScoreboard = true
+		scoringService := scoring.NewScoringService(bundle)
+		scoringService.CalculateAndCacheScoreBoard(context.Background())
+		AddRoutes(server, bundle, scoringService)
+
+		server.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.NotContains(t, rr.Body.String(), "foobar")
+
+		var response ScoreBoardResponse
+		err := json.Unmarshal(rr.Body.Bytes(), &response)
+		assert.Nil(t, err)
+		assert.Len(t, response.TopTeams, 1)
+		assert.Equal(t, bundle.PublicTeamName("foobar"), response.TopTeams[0].Name)
+		assert.Equal(t, "foobar", scoringService.GetTopScores()[0].Name, "the scoring internals keep the real names")
+	})
+
+	t.Run("returns 304 if the scoreboard didn't change since the client last fetched it", func(t *testing.T) {
+		server := http.NewServeMux()
+		clientset := fake.NewSimpleClientset(
+			createTeam("foobar", `[{"key":"scoreBoardChallenge","solvedAt":"2024-11-01T19:55:48.211Z"}]`, "1"),
+		)
+		bundle := testutil.NewTestBundleWithCustomFakeClient(clientset)
+		scoringService := scoring.NewScoringService(bundle)
+		scoringService.CalculateAndCacheScoreBoard(context.Background())
+		AddRoutes(server, bundle, scoringService)
+
+		req, _ := http.NewRequest("GET", "/balancer/api/score-board/top", nil)
+		rr := httptest.NewRecorder()
+		server.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusOK, rr.Code)
+		etag := rr.Header().Get("ETag")
+		assert.NotEmpty(t, etag)
+
+		req, _ = http.NewRequest("GET", "/balancer/api/score-board/top", nil)
+		req.Header.Set("If-None-Match", etag)
+		rr = httptest.NewRecorder()
+		server.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusNotModified, rr.Code)
+		assert.Empty(t, rr.Body.Bytes())
+
+		// recalculating the scores invalidates the etag
+		scoringService.CalculateAndCacheScoreBoard(context.Background())
+		req, _ = http.NewRequest("GET", "/balancer/api/score-board/top", nil)
+		req.Header.Set("If-None-Match", etag)
+		rr = httptest.NewRecorder()
+		server.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.NotEqual(t, etag, rr.Header().Get("ETag"))
+	})
+
+	t.Run("etag depends on the field naming and content encoding of the response", func(t *testing.T) {
+		server := http.NewServeMux()
+		clientset := fake.NewSimpleClientset(
+			createTeam("foobar", `[{"key":"scoreBoardChallenge","solvedAt":"2024-11-01T19:55:48.211Z"}]`, "1"),
+		)
+		bundle := testutil.NewTestBundleWithCustomFakeClient(clientset)
+		scoringService := scoring.NewScoringService(bundle)
+		scoringService.CalculateAndCacheScoreBoard(context.Background())
+		AddRoutes(server, bundle, scoringService)
+
+		fetch := func(accept string, acceptEncoding string, ifNoneMatch string) *httptest.ResponseRecorder {
+			req, _ := http.NewRequest("GET", "/balancer/api/score-board/top", nil)
+			req.Header.Set("Accept", accept)
+			req.Header.Set("Accept-Encoding", acceptEncoding)
+			req.Header.Set("If-None-Match", ifNoneMatch)
+			rr := httptest.NewRecorder()
+			server.ServeHTTP(rr, req)
+			return rr
+		}
+
+		rr := fetch("application/json", "", "")
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, []string{"Accept, Accept-Encoding"}, rr.Header().Values("Vary"))
+		etag := rr.Header().Get("ETag")
+
+		rr = fetch("application/json; naming=snake_case", "", etag)
+		assert.Equal(t, http.StatusOK, rr.Code, "A snake_case client must not reuse the camelCase representation")
+		assert.NotEqual(t, etag, rr.Header().Get("ETag"))
+
+		rr = fetch("application/json", "gzip", etag)
+		assert.Equal(t, http.StatusOK, rr.Code, "A gzip client must not reuse the uncompressed representation")
+		assert.NotEqual(t, etag, rr.Header().Get("ETag"))
+
+		rr = fetch("application/json", "", etag)
+		assert.Equal(t, http.StatusNotModified, rr.Code)
+		assert.Equal(t, []string{"Accept, Accept-Encoding"}, rr.Header().Values("Vary"))
+	})
+
+	t.Run("keeps serving the scores as of the freeze once the scoreboard is frozen", func(t *testing.T) {
+		server := http.NewServeMux()
+		clientset := fake.NewSimpleClientset(
+			createTeam("foobar", `[{"key":"scoreBoardChallenge","solvedAt":"2024-11-01T19:55:48.211Z"}]`, "1"),
+		)
+		bundle := testutil.NewTestBundleWithCustomFakeClient(clientset)
+		freezeAt := time.Now().Add(-time.Minute)
+		bundle.Config.FreezeScoreboardAt = &freezeAt
+		scoringService := scoring.NewScoringService(bundle)
+		scoringService.CalculateAndCacheScoreBoard(context.Background())
+
+		_, err := clientset.AppsV1().Deployments("test-namespace").Update(context.Background(), createTeam("foobar", `[{"key":"scoreBoardChallenge","solvedAt":"2024-11-01T19:55:48.211Z"},{"key":"nullByteChallenge","solvedAt":"2024-11-01T19:59:48.211Z"}]`, "2"), metav1.UpdateOptions{})
+		assert.Nil(t, err)
+		scoringService.CalculateAndCacheScoreBoard(context.Background())
+		AddRoutes(server, bundle, scoringService)
+
+		req, _ := http.NewRequest("GET", "/balancer/api/score-board/top", nil)
+		rr := httptest.NewRecorder()
+		server.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		var response ScoreBoardResponse
+		err = json.Unmarshal(rr.Body.Bytes(), &response)
+		assert.Nil(t, err)
+		assert.Equal(t, []*TeamScore{
+			{
+				Name:                 "foobar",
+				Score:                10,
+				Position:             1,
+				SolvedChallengeCount: 1,
+			},
+		}, response.TopTeams)
+		assert.NotNil(t, response.FrozenAt)
+		assert.True(t, freezeAt.Equal(*response.FrozenAt))
+
+		liveScore, ok := scoringService.GetScoreForTeam("foobar")
+		assert.True(t, ok)
+		assert.Equal(t, 50, liveScore.Score, "Scoring should continue behind the frozen scoreboard")
+	})
+
 	t.Run("should only include the top 24 teams", func(t *testing.T) {
 		req, _ := http.NewRequest("GET", "/balancer/api/score-board/top", nil)
 		rr := httptest.NewRecorder()
@@ -115,4 +286,120 @@ func TestScoreBoardHandler(t *testing.T) {
 		// team-24 should still be in the 2 "positions" because it has the same score as the other duplicated teams before it
 		assert.Equal(t, 2, response.TopTeams[23].Position)
 	})
+	t.Run("filters teams by name prefix while keeping their global position", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/balancer/api/score-board/top?q=BAR", nil)
+		rr := httptest.NewRecorder()
+
+		server := http.NewServeMux()
+		clientset := fake.NewSimpleClientset(
+			createTeam("foobar", `[{"key":"scoreBoardChallenge","solvedAt":"2024-11-01T19:55:48.211Z"}]`, "1"),
+			createTeam("barfoo", `[]`, "0"),
+			createTeam("baz", `[]`, "0"),
+		)
+		bundle := testutil.NewTestBundleWithCustomFakeClient(clientset)
+		scoringService := scoring.NewScoringService(bundle)
+		scoringService.CalculateAndCacheScoreBoard(context.Background())
+		AddRoutes(server, bundle, scoringService)
+
+		server.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+
+		var response ScoreBoardResponse
+		err := json.Unmarshal(rr.Body.Bytes(), &response)
+		assert.Nil(t, err)
+
+		assert.Equal(t, 3, response.TotalTeams)
+		assert.Equal(t, 1, *response.MatchingTeams)
+		assert.Equal(t, []*TeamScore{
+			{Name: "barfoo", Score: 0, Position: 2, SolvedChallengeCount: 0},
+		}, response.TopTeams)
+	})
+
+	t.Run("returns a match count of zero if no team matches the prefix", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/balancer/api/score-board/top?q=nope", nil)
+		rr := httptest.NewRecorder()
+
+		server := http.NewServeMux()
+		clientset := fake.NewSimpleClientset(createTeam("foobar", `[]`, "0"))
+		bundle := testutil.NewTestBundleWithCustomFakeClient(clientset)
+		eventStart := time.Date(2024, 11, 1, 19, 0, 0, 0, time.UTC)
+		bundle.Config.EventStart = &eventStart
+		scoringService := scoring.NewScoringService(bundle)
+		scoringService.CalculateAndCacheScoreBoard(context.Background())
+		AddRoutes(server, bundle, scoringService)
+
+		server.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.JSONEq(t, `{"totalTeams":1,"teams":[],"matchingTeams":0,"eventStart":"2024-11-01T19:00:00Z"}`, rr.Body.String())
+	})
+
+	t.Run("defaults the event start to the start of the scoring service", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/balancer/api/score-board/top", nil)
+		rr := httptest.NewRecorder()
+
+		server := http.NewServeMux()
+		bundle := testutil.NewTestBundle()
+		before := time.Now()
+		scoringService := scoring.NewScoringService(bundle)
+		AddRoutes(server, bundle, scoringService)
+
+		server.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		var response ScoreBoardResponse
+		assert.Nil(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		assert.False(t, response.EventStart.Before(before.Truncate(time.Second)))
+		assert.False(t, response.EventStart.After(time.Now()))
+	})
+	t.Run("includes the event metadata if configured, even without teams", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/balancer/api/score-board/top", nil)
+		rr := httptest.NewRecorder()
+
+		server := http.NewServeMux()
+		bundle := testutil.NewTestBundle()
+		eventStart := time.Date(2024, 11, 1, 19, 0, 0, 0, time.UTC)
+		bundle.Config.EventStart = &eventStart
+		bundle.Config.EventName = "OWASP Juice Shop CTF"
+		bundle.Config.ScoreboardEventMetadata = true
+		scoringService := scoring.NewScoringService(bundle)
+		AddRoutes(server, bundle, scoringService)
+
+		server.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.JSONEq(t, `{"totalTeams":0,"teams":[],"eventStart":"2024-11-01T19:00:00Z","event":{"name":"OWASP Juice Shop CTF","start":"2024-11-01T19:00:00Z","totalChallenges":2}}`, rr.Body.String())
+	})
+	t.Run("renames the fields to snake_case if requested", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/balancer/api/score-board/top", nil)
+		req.Header.Set("Accept", "application/json; naming=snake_case")
+		rr := httptest.NewRecorder()
+
+		server := http.NewServeMux()
+		bundle := testutil.NewTestBundle()
+		eventStart := time.Date(2024, 11, 1, 19, 0, 0, 0, time.UTC)
+		bundle.Config.EventStart = &eventStart
+		scoringService := scoring.NewScoringService(bundle)
+		AddRoutes(server, bundle, scoringService)
+
+		server.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.JSONEq(t, `{"total_teams":0,"teams":[],"event_start":"2024-11-01T19:00:00Z"}`, rr.Body.String())
+	})
+
+	t.Run("rejects unknown field namings", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/balancer/api/score-board/top", nil)
+		req.Header.Set("Accept", "application/json; naming=kebab-case")
+		rr := httptest.NewRecorder()
+
+		server := http.NewServeMux()
+		bundle := testutil.NewTestBundle()
+		AddRoutes(server, bundle, scoring.NewScoringService(bundle))
+
+		server.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusNotAcceptable, rr.Code)
+	})
 }
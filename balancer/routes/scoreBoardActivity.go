@@ -0,0 +1,111 @@
+package routes
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	b "github.com/juice-shop/multi-juicer/balancer/pkg/bundle"
+	"github.com/juice-shop/multi-juicer/balancer/pkg/scoring"
+)
+
+// ScoreBoardActivityEvent is a single solve or a newly joined team in the chronological activity feed of all teams
+type ScoreBoardActivityEvent struct {
+	// Id can be passed as the after query parameter to only fetch newer events
+	Id int64 `json:"id"`
+	// Type is either "solve" or "team-joined". The challenge fields are empty for team-joined events
+	Type          string `json:"type"`
+	Team          string `json:"team"`
+	Challenge     string `json:"challenge"`
+	ChallengeName string `json:"challengeName"`
+	Category      string `json:"category"`
+	// SolvedAt is the time the instance of the team got created for team-joined events
+	SolvedAt time.Time `json:"solvedAt"`
+	Points   int       `json:"points"`
+}
+
+const defaultActivityLimit = 50
+const maxActivityLimit = 200
+
+// handleScoreBoardActivity returns the solves and joined teams detected since the balancer started, oldest first. Without the after parameter the latest events are returned.
+// UIs can leave out the team-joined events by passing teamJoins=false
+func handleScoreBoardActivity(bundle *b.Bundle, scoringService *scoring.ScoringService) http.Handler {
+	challengesByKey := make(map[string]b.JuiceShopChallenge)
+	for _, challenge := range bundle.JuiceShopChallenges {
+		challengesByKey[challenge.Key] = challenge
+	}
+
+	return http.HandlerFunc(
+		func(responseWriter http.ResponseWriter, req *http.Request) {
+			var afterId int64
+			if after := req.URL.Query().Get("after"); after != "" {
+				var err error
+				afterId, err = strconv.ParseInt(after, 10, 64)
+				if err != nil || afterId < 0 {
+					http.Error(responseWriter, "after must be the id of an activity event", http.StatusBadRequest)
+					return
+				}
+			}
+			limit := defaultActivityLimit
+			if limitParam := req.URL.Query().Get("limit"); limitParam != "" {
+				var err error
+				limit, err = strconv.Atoi(limitParam)
+				if err != nil || limit <= 0 || limit > maxActivityLimit {
+					http.Error(responseWriter, "limit must be between 1 and 200", http.StatusBadRequest)
+					return
+				}
+			}
+
+			includeTeamJoins := true
+			if teamJoins := req.URL.Query().Get("teamJoins"); teamJoins != "" {
+				var err error
+				includeTeamJoins, err = strconv.ParseBool(teamJoins)
+				if err != nil {
+					http.Error(responseWriter, "teamJoins must be either true or false", http.StatusBadRequest)
+					return
+				}
+			}
+
+			solveEvents := scoringService.GetSolveEvents(afterId, limit, includeTeamJoins)
+			events := make([]ScoreBoardActivityEvent, 0, len(solveEvents))
+			for _, solveEvent := range solveEvents {
+				if solveEvent.Type == scoring.TeamJoinedEventType {
+					events = append(events, ScoreBoardActivityEvent{
+						Id:       solveEvent.Id,
+						Type:     solveEvent.Type,
+						Team:     bundle.PublicTeamName(solveEvent.Team),
+						SolvedAt: solveEvent.SolvedAt,
+					})
+					continue
+				}
+				challenge, ok := challengesByKey[solveEvent.Challenge]
+				if !ok {
+					// challenges unknown to the balancer don't count towards the score, so they aren't shown either
+					continue
+				}
+				events = append(events, ScoreBoardActivityEvent{
+					Id:            solveEvent.Id,
+					Type:          solveEvent.Type,
+					Team:          bundle.PublicTeamName(solveEvent.Team),
+					Challenge:     challenge.Key,
+					ChallengeName: challenge.Name,
+					Category:      challenge.Category,
+					SolvedAt:      solveEvent.SolvedAt,
+					Points:        scoring.ChallengePoints(bundle, challenge),
+				})
+			}
+
+			responseBytes, err := json.Marshal(events)
+			if err != nil {
+				bundle.Log.Printf("Failed to marshal response: %s", err)
+				http.Error(responseWriter, "", http.StatusInternalServerError)
+				return
+			}
+
+			responseWriter.Header().Set("Content-Type", "application/json")
+			responseWriter.WriteHeader(http.StatusOK)
+			responseWriter.Write(responseBytes)
+		},
+	)
+}
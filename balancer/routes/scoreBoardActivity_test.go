@@ -0,0 +1,142 @@
+package routes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/juice-shop/multi-juicer/balancer/pkg/scoring"
+	"github.com/juice-shop/multi-juicer/balancer/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes/fake"
+	testcore "k8s.io/client-go/testing"
+)
+
+func TestScoreBoardActivityHandler(t *testing.T) {
+	createTeam := func(team string, challenges string) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      fmt.Sprintf("juiceshop-%s", team),
+				Namespace: "test-namespace",
+				Annotations: map[string]string{
+					"multi-juicer.owasp-juice.shop/challenges": challenges,
+				},
+				Labels: map[string]string{
+					"app.kubernetes.io/name":    "juice-shop",
+					"app.kubernetes.io/part-of": "multi-juicer",
+					"team":                      team,
+				},
+			},
+			Status: appsv1.DeploymentStatus{
+				ReadyReplicas: 1,
+			},
+		}
+	}
+
+	getActivity := func(server *http.ServeMux, query string) ([]ScoreBoardActivityEvent, int) {
+		req, _ := http.NewRequest("GET", "/balancer/api/score-board/activity"+query, nil)
+		rr := httptest.NewRecorder()
+		server.ServeHTTP(rr, req)
+		var events []ScoreBoardActivityEvent
+		json.Unmarshal(rr.Body.Bytes(), &events)
+		return events, rr.Code
+	}
+
+	t.Run("lists solves detected by the watcher in chronological order", func(t *testing.T) {
+		server := http.NewServeMux()
+		clientset := fake.NewSimpleClientset(
+			createTeam("foobar", `[{"key":"scoreBoardChallenge","solvedAt":"2024-11-01T19:55:48Z"}]`),
+			createTeam("barfoo", `[]`),
+		)
+		watcher := watch.NewFake()
+		clientset.PrependWatchReactor("deployments", testcore.DefaultWatchReactor(watcher, nil))
+		bundle := testutil.NewTestBundleWithCustomFakeClient(clientset)
+		scoringService := scoring.NewScoringService(bundle)
+		ctx, cancel := context.WithCancel(context.Background())
+		t.Cleanup(cancel)
+		scoringService.CalculateAndCacheScoreBoard(ctx)
+		go scoringService.StartingScoringWorker(ctx)
+		AddRoutes(server, bundle, scoringService)
+
+		events, code := getActivity(server, "")
+		assert.Equal(t, http.StatusOK, code)
+		assert.Empty(t, events, "solves from before the balancer started aren't part of the feed")
+
+		watcher.Modify(createTeam("barfoo", `[{"key":"scoreBoardChallenge","solvedAt":"2024-11-01T20:00:00Z"}]`))
+		watcher.Modify(createTeam("foobar", `[{"key":"scoreBoardChallenge","solvedAt":"2024-11-01T19:55:48Z"},{"key":"nullByteChallenge","solvedAt":"2024-11-01T20:10:00Z"}]`))
+
+		assert.Eventually(t, func() bool {
+			events, _ := getActivity(server, "")
+			return len(events) == 2
+		}, 1*time.Second, 10*time.Millisecond)
+
+		events, _ = getActivity(server, "")
+		assert.Equal(t, []ScoreBoardActivityEvent{
+			{Id: 1, Type: "solve", Team: "barfoo", Challenge: "scoreBoardChallenge", ChallengeName: "Score Board", SolvedAt: time.Date(2024, 11, 1, 20, 0, 0, 0, time.UTC), Points: 10},
+			{Id: 2, Type: "solve", Team: "foobar", Challenge: "nullByteChallenge", ChallengeName: "Poison Null Byte", SolvedAt: time.Date(2024, 11, 1, 20, 10, 0, 0, time.UTC), Points: 40},
+		}, events)
+
+		events, _ = getActivity(server, "?after=1")
+		assert.Len(t, events, 1)
+		assert.Equal(t, int64(2), events[0].Id)
+
+		events, _ = getActivity(server, "?limit=1")
+		assert.Len(t, events, 1)
+		assert.Equal(t, int64(2), events[0].Id, "should return the latest events without an after parameter")
+	})
+
+	t.Run("lists teams joining after the balancer started, unless opted out", func(t *testing.T) {
+		server := http.NewServeMux()
+		clientset := fake.NewSimpleClientset(createTeam("foobar", `[]`))
+		watcher := watch.NewFake()
+		clientset.PrependWatchReactor("deployments", testcore.DefaultWatchReactor(watcher, nil))
+		bundle := testutil.NewTestBundleWithCustomFakeClient(clientset)
+		scoringService := scoring.NewScoringService(bundle)
+		ctx, cancel := context.WithCancel(context.Background())
+		t.Cleanup(cancel)
+		scoringService.CalculateAndCacheScoreBoard(ctx)
+		go scoringService.StartingScoringWorker(ctx)
+		AddRoutes(server, bundle, scoringService)
+
+		createdAt := time.Date(2024, 11, 1, 19, 0, 0, 0, time.UTC)
+		newTeam := createTeam("barfoo", `[]`)
+		newTeam.CreationTimestamp = metav1.NewTime(createdAt)
+		watcher.Add(newTeam)
+
+		assert.Eventually(t, func() bool {
+			events, _ := getActivity(server, "")
+			return len(events) == 1
+		}, 1*time.Second, 10*time.Millisecond)
+
+		events, _ := getActivity(server, "")
+		assert.Equal(t, []ScoreBoardActivityEvent{
+			{Id: 1, Type: "team-joined", Team: "barfoo", SolvedAt: createdAt},
+		}, events)
+
+		events, code := getActivity(server, "?teamJoins=false")
+		assert.Equal(t, http.StatusOK, code)
+		assert.Empty(t, events)
+	})
+
+	t.Run("rejects invalid paging parameters", func(t *testing.T) {
+		server := http.NewServeMux()
+		bundle := testutil.NewTestBundle()
+		AddRoutes(server, bundle, scoring.NewScoringService(bundle))
+
+		_, code := getActivity(server, "?after=foo")
+		assert.Equal(t, http.StatusBadRequest, code)
+		_, code = getActivity(server, "?limit=0")
+		assert.Equal(t, http.StatusBadRequest, code)
+		_, code = getActivity(server, "?limit=1000")
+		assert.Equal(t, http.StatusBadRequest, code)
+		_, code = getActivity(server, "?teamJoins=maybe")
+		assert.Equal(t, http.StatusBadRequest, code)
+	})
+}
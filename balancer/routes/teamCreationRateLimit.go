@@ -0,0 +1,138 @@
+package routes
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/juice-shop/multi-juicer/balancer/pkg/bundle"
+	"golang.org/x/time/rate"
+)
+
+// per client limiters which haven't been used for this long get dropped to keep the map from growing forever
+const clientLimiterIdleTimeout = 10 * time.Minute
+
+// how long clients are asked to wait if all concurrent team creation slots are taken
+const concurrentCreationRetryAfter = 5 * time.Second
+
+type clientLimiter struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// teamCreationLimiter restricts how fast a single client can create new teams and how many teams can be created at the same time
+type teamCreationLimiter struct {
+	config bundle.TeamCreationRateLimitConfig
+
+	clients     map[string]*clientLimiter
+	lastCleanup time.Time
+	mutex       *sync.Mutex
+
+	// semaphore for concurrent creations, nil if there is no limit
+	concurrentCreations chan struct{}
+}
+
+func newTeamCreationLimiter(config bundle.TeamCreationRateLimitConfig) *teamCreationLimiter {
+	limiter := &teamCreationLimiter{
+		config:      config,
+		clients:     map[string]*clientLimiter{},
+		lastCleanup: time.Now(),
+		mutex:       &sync.Mutex{},
+	}
+	if config.MaxConcurrentCreations > 0 {
+		limiter.concurrentCreations = make(chan struct{}, config.MaxConcurrentCreations)
+	}
+	return limiter
+}
+
+// allowClient checks if the client is allowed to create another team. If not it returns how long the client should wait before retrying
+func (l *teamCreationLimiter) allowClient(client string, now time.Time) (bool, time.Duration) {
+	if l.config.PerClientPerMinute <= 0 {
+		return true, 0
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if now.Sub(l.lastCleanup) > clientLimiterIdleTimeout {
+		for key, entry := range l.clients {
+			if now.Sub(entry.lastSeen) > clientLimiterIdleTimeout {
+				delete(l.clients, key)
+			}
+		}
+		l.lastCleanup = now
+	}
+
+	entry, ok := l.clients[client]
+	if !ok {
+		burst := l.config.Burst
+		if burst <= 0 {
+			burst = 1
+		}
+		entry = &clientLimiter{
+			limiter: rate.NewLimiter(rate.Limit(l.config.PerClientPerMinute/60), burst),
+		}
+		l.clients[client] = entry
+	}
+	entry.lastSeen = now
+
+	reservation := entry.limiter.ReserveN(now, 1)
+	if !reservation.OK() {
+		return false, time.Minute
+	}
+	delay := reservation.DelayFrom(now)
+	if delay > 0 {
+		// don't consume the token, the request isn't going to be served
+		reservation.CancelAt(now)
+		return false, delay
+	}
+	return true, 0
+}
+
+// acquireCreationSlot tries to take one of the concurrent creation slots. The returned release func must be called once the creation is done
+func (l *teamCreationLimiter) acquireCreationSlot() (bool, func()) {
+	if l.concurrentCreations == nil {
+		return true, func() {}
+	}
+	select {
+	case l.concurrentCreations <- struct{}{}:
+		return true, func() { <-l.concurrentCreations }
+	default:
+		return false, nil
+	}
+}
+
+// getClientIP returns the address of the client. With trustForwardedFor the right-most X-Forwarded-For entry is used, as that's the one appended by the proxy in front of the balancer.
+// All entries left of it are sent by the client and can be spoofed to dodge the rate limit
+func getClientIP(bundle *bundle.Bundle, req *http.Request) string {
+	if bundle.Config.TeamCreationRateLimit.TrustForwardedFor {
+		if forwardedFor := strings.Join(req.Header.Values("X-Forwarded-For"), ","); forwardedFor != "" {
+			entries := strings.Split(forwardedFor, ",")
+			if clientIP := strings.TrimSpace(entries[len(entries)-1]); clientIP != "" {
+				return clientIP
+			}
+		}
+	}
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		return req.RemoteAddr
+	}
+	return host
+}
+
+func writeTooManyRequestsResponse(w http.ResponseWriter, retryAfter time.Duration) {
+	w.Header().Set("Retry-After", formatRetryAfter(retryAfter))
+	http.Error(w, `{"message":"Too many teams created","description":"Wait a bit before creating another team."}`, http.StatusTooManyRequests)
+}
+
+func formatRetryAfter(retryAfter time.Duration) string {
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	return strconv.Itoa(seconds)
+}
@@ -0,0 +1,84 @@
+package routes
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/juice-shop/multi-juicer/balancer/pkg/bundle"
+	"github.com/juice-shop/multi-juicer/balancer/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTeamCreationLimiter(t *testing.T) {
+	t.Run("allows everything when not configured", func(t *testing.T) {
+		limiter := newTeamCreationLimiter(bundle.TeamCreationRateLimitConfig{})
+		now := time.Now()
+		for i := 0; i < 100; i++ {
+			allowed, _ := limiter.allowClient("10.0.0.1", now)
+			assert.True(t, allowed)
+		}
+		acquired, release := limiter.acquireCreationSlot()
+		assert.True(t, acquired)
+		release()
+	})
+
+	t.Run("clients can create teams again after waiting", func(t *testing.T) {
+		limiter := newTeamCreationLimiter(bundle.TeamCreationRateLimitConfig{PerClientPerMinute: 2, Burst: 2})
+		now := time.Now()
+
+		allowed, _ := limiter.allowClient("10.0.0.1", now)
+		assert.True(t, allowed)
+		allowed, _ = limiter.allowClient("10.0.0.1", now)
+		assert.True(t, allowed)
+		allowed, retryAfter := limiter.allowClient("10.0.0.1", now)
+		assert.False(t, allowed)
+		assert.InDelta(t, 30*time.Second, retryAfter, float64(time.Second))
+
+		allowed, _ = limiter.allowClient("10.0.0.1", now.Add(31*time.Second))
+		assert.True(t, allowed)
+	})
+
+	t.Run("limits concurrent creations", func(t *testing.T) {
+		limiter := newTeamCreationLimiter(bundle.TeamCreationRateLimitConfig{MaxConcurrentCreations: 1})
+
+		acquired, release := limiter.acquireCreationSlot()
+		assert.True(t, acquired)
+		acquired, _ = limiter.acquireCreationSlot()
+		assert.False(t, acquired)
+
+		release()
+		acquired, _ = limiter.acquireCreationSlot()
+		assert.True(t, acquired)
+	})
+
+	t.Run("formats retry after as whole seconds", func(t *testing.T) {
+		assert.Equal(t, "1", formatRetryAfter(10*time.Millisecond))
+		assert.Equal(t, "31", formatRetryAfter(30*time.Second+time.Millisecond))
+	})
+
+	t.Run("uses the address appended by the proxy when trusting X-Forwarded-For", func(t *testing.T) {
+		b := testutil.NewTestBundle()
+		b.Config.TeamCreationRateLimit.TrustForwardedFor = true
+
+		req, _ := http.NewRequest("POST", "/balancer/api/teams/foobar/join", nil)
+		req.RemoteAddr = "10.0.0.2:34567"
+		// the client spoofs the first entry, the ingress appends the real client address
+		req.Header.Set("X-Forwarded-For", "1.2.3.4, 203.0.113.7")
+		assert.Equal(t, "203.0.113.7", getClientIP(b, req))
+
+		req.Header.Set("X-Forwarded-For", "1.2.3.4")
+		req.Header.Add("X-Forwarded-For", "203.0.113.7")
+		assert.Equal(t, "203.0.113.7", getClientIP(b, req))
+
+		req.Header.Del("X-Forwarded-For")
+		assert.Equal(t, "10.0.0.2", getClientIP(b, req))
+	})
+
+	t.Run("ignores X-Forwarded-For unless it's trusted", func(t *testing.T) {
+		req, _ := http.NewRequest("POST", "/balancer/api/teams/foobar/join", nil)
+		req.RemoteAddr = "10.0.0.2:34567"
+		req.Header.Set("X-Forwarded-For", "1.2.3.4")
+		assert.Equal(t, "10.0.0.2", getClientIP(testutil.NewTestBundle(), req))
+	})
+}
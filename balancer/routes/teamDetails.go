@@ -0,0 +1,29 @@
+package routes
+
+import (
+	"net/http"
+
+	b "github.com/juice-shop/multi-juicer/balancer/pkg/bundle"
+	"github.com/juice-shop/multi-juicer/balancer/pkg/teamcookie"
+)
+
+// canSeeTeamDetails checks if the caller may see the solved challenges of the team. Without the privateTeamDetails config everybody can
+func canSeeTeamDetails(bundle *b.Bundle, req *http.Request, team string) bool {
+	if !bundle.Config.PrivateTeamDetails {
+		return true
+	}
+	requestingTeam, err := teamcookie.GetTeamFromAdminRequest(bundle, req)
+	if err != nil {
+		return false
+	}
+	return requestingTeam == team || bundle.IsAdminTeam(requestingTeam)
+}
+
+// canCompareTeams checks if the caller may compare the solved challenges of two teams, which is restricted to admins with the privateTeamDetails config
+func canCompareTeams(bundle *b.Bundle, req *http.Request) bool {
+	if !bundle.Config.PrivateTeamDetails {
+		return true
+	}
+	requestingTeam, err := teamcookie.GetTeamFromAdminRequest(bundle, req)
+	return err == nil && bundle.IsAdminTeam(requestingTeam)
+}
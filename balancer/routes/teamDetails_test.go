@@ -0,0 +1,38 @@
+package routes
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/juice-shop/multi-juicer/balancer/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCanSeeTeamDetails(t *testing.T) {
+	requestAs := func(team string) *http.Request {
+		req, _ := http.NewRequest("GET", "/balancer/api/score-board/teams/foobar/score", nil)
+		if team != "" {
+			req.AddCookie(&http.Cookie{Name: "team", Value: testutil.SignTestTeamname(team)})
+		}
+		return req
+	}
+
+	t.Run("allows everybody without the privateTeamDetails config", func(t *testing.T) {
+		bundle := testutil.NewTestBundle()
+		assert.True(t, canSeeTeamDetails(bundle, requestAs(""), "foobar"))
+		assert.True(t, canCompareTeams(bundle, requestAs("")))
+	})
+
+	t.Run("only allows the team itself and admins with the privateTeamDetails config", func(t *testing.T) {
+		bundle := testutil.NewTestBundle()
+		bundle.Config.PrivateTeamDetails = true
+
+		assert.True(t, canSeeTeamDetails(bundle, requestAs("foobar"), "foobar"))
+		assert.True(t, canSeeTeamDetails(bundle, requestAs("admin"), "foobar"))
+		assert.False(t, canSeeTeamDetails(bundle, requestAs("barfoo"), "foobar"))
+		assert.False(t, canSeeTeamDetails(bundle, requestAs(""), "foobar"))
+
+		assert.True(t, canCompareTeams(bundle, requestAs("admin")))
+		assert.False(t, canCompareTeams(bundle, requestAs("foobar")))
+	})
+}
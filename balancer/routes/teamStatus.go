@@ -17,6 +17,12 @@ type TeamStatus struct {
 	Position         int    `json:"position"`
 	TotalTeams       int    `json:"totalTeams"`
 	Readiness        bool   `json:"readiness"`
+	// ReadyChangedAt is set once the readiness of the instance changed, allowing the ui to show a notification once the instance is ready
+	ReadyChangedAt *time.Time `json:"readyChangedAt,omitempty"`
+	// NewlySolved are the keys of the challenges solved with the update. Only set on responses to wait-for-update-after requests
+	NewlySolved []string `json:"newlySolved,omitempty"`
+	// LastRestoredAt is set once the progress of the team got restored to its instance, allowing the ui to show that the solves survived an instance restart
+	LastRestoredAt *time.Time `json:"lastRestoredAt,omitempty"`
 }
 
 type AdminTeamStatus struct {
@@ -26,13 +32,19 @@ type AdminTeamStatus struct {
 func handleTeamStatus(bundle *bundle.Bundle, scoringService *scoring.ScoringService) http.Handler {
 	return http.HandlerFunc(
 		func(responseWriter http.ResponseWriter, req *http.Request) {
+			naming, err := responseFieldNaming(bundle, req)
+			if err != nil {
+				http.Error(responseWriter, err.Error(), http.StatusNotAcceptable)
+				return
+			}
 			team, err := teamcookie.GetTeamFromRequest(bundle, req)
 			if err != nil {
 				http.Error(responseWriter, "", http.StatusUnauthorized)
 				return
 			}
 
-			if team == "admin" {
+			if bundle.IsAdminTeam(team) {
+				// always reported as "admin" independent of the configured admin team name, the ui uses it to detect the admin session
 				responseBytes, err := json.Marshal(AdminTeamStatus{Name: "admin"})
 				if err != nil {
 					bundle.Log.Printf("Failed to marshal response: %s", err)
@@ -47,6 +59,7 @@ func handleTeamStatus(bundle *bundle.Bundle, scoringService *scoring.ScoringServ
 			}
 
 			var teamScore *scoring.TeamScore
+			var newlySolved []string
 
 			if req.URL.Query().Get("wait-for-update-after") != "" {
 				lastSeenUpdate, err := time.Parse(time.RFC3339, req.URL.Query().Get("wait-for-update-after"))
@@ -60,6 +73,7 @@ func handleTeamStatus(bundle *bundle.Bundle, scoringService *scoring.ScoringServ
 					responseWriter.Write([]byte{})
 					return
 				}
+				newlySolved = teamScore.NewlySolved
 			} else {
 				var ok bool
 				teamScore, ok = scoringService.GetScoreForTeam(team)
@@ -74,16 +88,31 @@ func handleTeamStatus(bundle *bundle.Bundle, scoringService *scoring.ScoringServ
 				}
 			}
 
+			position, totalTeams := teamScore.Position, len(scoringService.GetTopScores())
+			// the team still sees its own score, but the ranking stays the one of the frozen scoreboard, it would otherwise reveal the live standings
+			if publicScores, _, frozen := scoringService.GetPublicTopScoresWithLastUpdate(); frozen {
+				position, totalTeams = -1, len(publicScores)
+				for _, publicScore := range publicScores {
+					if publicScore.Name == team {
+						position = publicScore.Position
+						break
+					}
+				}
+			}
+
 			response := TeamStatus{
 				Name:             team,
 				Score:            teamScore.Score,
-				Position:         teamScore.Position,
-				TotalTeams:       len(scoringService.GetScores()),
+				Position:         position,
+				TotalTeams:       totalTeams,
 				SolvedChallenges: len(teamScore.Challenges),
 				Readiness:        teamScore.InstanceReadiness,
+				ReadyChangedAt:   teamScore.ReadyChangedAt,
+				NewlySolved:      newlySolved,
+				LastRestoredAt:   teamScore.LastRestoredAt,
 			}
 
-			responseBytes, err := json.Marshal(response)
+			responseBytes, err := marshalWithFieldNaming(response, naming)
 			if err != nil {
 				bundle.Log.Printf("Failed to marshal response: %s", err)
 				http.Error(responseWriter, "", http.StatusInternalServerError)
@@ -2,6 +2,7 @@ package routes
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -66,6 +67,49 @@ func TestTeamStatusHandler(t *testing.T) {
 		assert.JSONEq(t, `{"name":"foobar","score":10,"position":1,"solvedChallenges":1,"totalTeams":2,"readiness":true}`, rr.Body.String())
 	})
 
+	t.Run("includes when the progress of the team got restored", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/balancer/api/teams/status", nil)
+		req.Header.Set("Cookie", fmt.Sprintf("team=%s", testutil.SignTestTeamname(team)))
+		rr := httptest.NewRecorder()
+		server := http.NewServeMux()
+		restoredTeam := createTeam("foobar", `[{"key":"scoreBoardChallenge","solvedAt":"2024-11-01T19:55:48.211Z"}]`, "1")
+		restoredTeam.Annotations[scoring.LastRestoredAtAnnotation] = "2024-11-01T20:00:00Z"
+		bundle := testutil.NewTestBundleWithCustomFakeClient(fake.NewSimpleClientset(restoredTeam))
+		scoringService := scoring.NewScoringService(bundle)
+		scoringService.CalculateAndCacheScoreBoard(context.Background())
+		AddRoutes(server, bundle, scoringService)
+
+		server.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.JSONEq(t, `{"name":"foobar","score":10,"position":1,"solvedChallenges":1,"totalTeams":1,"readiness":true,"lastRestoredAt":"2024-11-01T20:00:00Z"}`, rr.Body.String())
+	})
+
+	t.Run("takes position and total teams from the frozen scoreboard once it's frozen", func(t *testing.T) {
+		clientset := fake.NewSimpleClientset(
+			createTeamWithSolvedChallenges("foobar", `[]`),
+			createTeamWithSolvedChallenges("barfoo", `[{"key":"scoreBoardChallenge","solvedAt":"2024-11-01T19:55:48Z"}]`),
+		)
+		bundle, scoringService := newFrozenScoringService(t, clientset, "foobar", `[{"key":"nullByteChallenge","solvedAt":"2024-11-01T20:10:00Z"}]`)
+		_, err := clientset.AppsV1().Deployments("test-namespace").Create(context.Background(), createTeamWithSolvedChallenges("latecomer", `[]`), metav1.CreateOptions{})
+		assert.NoError(t, err)
+		assert.NoError(t, scoringService.CalculateAndCacheScoreBoard(context.Background()))
+		server := http.NewServeMux()
+		AddRoutes(server, bundle, scoringService)
+
+		req, _ := http.NewRequest("GET", "/balancer/api/teams/status", nil)
+		req.Header.Set("Cookie", fmt.Sprintf("team=%s", testutil.SignTestTeamname("foobar")))
+		rr := httptest.NewRecorder()
+		server.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		var status TeamStatus
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &status))
+		assert.Equal(t, 40, status.Score, "The team should still see its own progress")
+		assert.Equal(t, 2, status.Position, "Should not reveal the live position of the team")
+		assert.Equal(t, 2, status.TotalTeams, "Should not reveal teams joining after the freeze")
+	})
+
 	t.Run("returns -1 for position and score if it hasn't been calculated yet", func(t *testing.T) {
 		req, _ := http.NewRequest("GET", "/balancer/api/teams/status", nil)
 		req.Header.Set("Cookie", fmt.Sprintf("team=%s", testutil.SignTestTeamname(team)))
@@ -115,7 +159,13 @@ func TestTeamStatusHandler(t *testing.T) {
 			req.Header.Set("Cookie", fmt.Sprintf("team=%s", testutil.SignTestTeamname(team)))
 			server.ServeHTTP(rr, req)
 			assert.Equal(t, http.StatusOK, rr.Code)
-			return assert.JSONEq(t, `{"name":"foobar","score":10,"position":1,"solvedChallenges":1,"totalTeams":1,"readiness":true}`, rr.Body.String())
+
+			var status TeamStatus
+			if err := json.Unmarshal(rr.Body.Bytes(), &status); err != nil {
+				return false
+			}
+			// the readiness change is marked, so that the ui can tell it apart from score changes
+			return status.Readiness && status.ReadyChangedAt != nil && status.Score == 10
 		}, 1*time.Second, 10*time.Millisecond)
 	})
 
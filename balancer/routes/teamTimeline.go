@@ -0,0 +1,89 @@
+package routes
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"sort"
+	"time"
+
+	b "github.com/juice-shop/multi-juicer/balancer/pkg/bundle"
+	"github.com/juice-shop/multi-juicer/balancer/pkg/scoring"
+	"github.com/juice-shop/multi-juicer/balancer/pkg/teamcookie"
+)
+
+type TimelinePoint struct {
+	Time            time.Time `json:"time"`
+	CumulativeScore int       `json:"cumulativeScore"`
+	// MinutesSinceStart is the solve time relative to the event start, negative for solves before the event started
+	MinutesSinceStart int `json:"minutesSinceStart"`
+}
+
+type TeamTimelineResponse struct {
+	EventStart time.Time       `json:"eventStart"`
+	Timeline   []TimelinePoint `json:"timeline"`
+}
+
+// handleTeamTimeline returns the score of a team over time, derived from when the teams challenges were solved. Teams can only see their own timeline, admins can see the timeline of every team
+func handleTeamTimeline(bundle *b.Bundle, scoringService *scoring.ScoringService) http.Handler {
+	challengesByKeys := make(map[string]b.JuiceShopChallenge)
+	for _, challenge := range bundle.JuiceShopChallenges {
+		challengesByKeys[challenge.Key] = challenge
+	}
+
+	return http.HandlerFunc(
+		func(responseWriter http.ResponseWriter, req *http.Request) {
+			team := req.PathValue("team")
+			if !isValidTeamName(team) {
+				http.Error(responseWriter, "invalid team name", http.StatusBadRequest)
+				return
+			}
+
+			requestingTeam, err := teamcookie.GetTeamFromAdminRequest(bundle, req)
+			if err != nil {
+				http.Error(responseWriter, "", http.StatusUnauthorized)
+				return
+			}
+			if requestingTeam != team && !bundle.IsAdminTeam(requestingTeam) {
+				http.Error(responseWriter, "", http.StatusForbidden)
+				return
+			}
+
+			teamScore, ok := scoringService.GetScoreForTeam(team)
+			if !ok {
+				http.Error(responseWriter, "team not found", http.StatusNotFound)
+				return
+			}
+
+			solves := make([]scoring.ChallengeProgress, len(teamScore.Challenges))
+			copy(solves, teamScore.Challenges)
+			sort.SliceStable(solves, func(i, j int) bool {
+				return solves[i].SolvedAt.Before(solves[j].SolvedAt)
+			})
+
+			eventStart := scoringService.EventStart()
+			timeline := make([]TimelinePoint, 0, len(solves))
+			cumulativeScore := 0
+			for _, solve := range solves {
+				cumulativeScore += scoring.ChallengePoints(bundle, challengesByKeys[solve.Key])
+				timeline = append(timeline, TimelinePoint{
+					Time:            solve.SolvedAt,
+					CumulativeScore: cumulativeScore,
+					// floored, so that a solve counts towards the minute it happened in
+					MinutesSinceStart: int(math.Floor(solve.SolvedAt.Sub(eventStart).Minutes())),
+				})
+			}
+
+			responseBytes, err := json.Marshal(TeamTimelineResponse{EventStart: eventStart, Timeline: timeline})
+			if err != nil {
+				bundle.Log.Printf("Failed to marshal response: %s", err)
+				http.Error(responseWriter, "", http.StatusInternalServerError)
+				return
+			}
+
+			responseWriter.Header().Set("Content-Type", "application/json")
+			responseWriter.WriteHeader(http.StatusOK)
+			responseWriter.Write(responseBytes)
+		},
+	)
+}
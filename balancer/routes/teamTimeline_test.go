@@ -0,0 +1,105 @@
+package routes
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/juice-shop/multi-juicer/balancer/pkg/scoring"
+	"github.com/juice-shop/multi-juicer/balancer/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestTeamTimelineHandler(t *testing.T) {
+	createTeam := func(team string, challenges string) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      fmt.Sprintf("juiceshop-%s", team),
+				Namespace: "test-namespace",
+				Annotations: map[string]string{
+					"multi-juicer.owasp-juice.shop/challenges": challenges,
+				},
+				Labels: map[string]string{
+					"app.kubernetes.io/name":    "juice-shop",
+					"app.kubernetes.io/part-of": "multi-juicer",
+					"team":                      team,
+				},
+			},
+			Status: appsv1.DeploymentStatus{
+				ReadyReplicas: 1,
+			},
+		}
+	}
+
+	setupServer := func() *http.ServeMux {
+		server := http.NewServeMux()
+		clientset := fake.NewSimpleClientset(
+			createTeam("foobar", `[{"key":"nullByteChallenge","solvedAt":"2024-11-01T20:10:00Z"},{"key":"scoreBoardChallenge","solvedAt":"2024-11-01T19:55:48Z"}]`),
+			createTeam("other-team", `[]`),
+		)
+		bundle := testutil.NewTestBundleWithCustomFakeClient(clientset)
+		eventStart := time.Date(2024, 11, 1, 19, 0, 0, 0, time.UTC)
+		bundle.Config.EventStart = &eventStart
+		scoringService := scoring.NewScoringService(bundle)
+		scoringService.CalculateAndCacheScoreBoard(context.Background())
+		AddRoutes(server, bundle, scoringService)
+		return server
+	}
+
+	t.Run("returns the cumulative score of the team ordered by solve time", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/balancer/api/teams/foobar/timeline", nil)
+		req.Header.Set("Cookie", fmt.Sprintf("team=%s", testutil.SignTestTeamname("foobar")))
+		rr := httptest.NewRecorder()
+
+		setupServer().ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.JSONEq(t, `{"eventStart":"2024-11-01T19:00:00Z","timeline":[{"time":"2024-11-01T19:55:48Z","cumulativeScore":10,"minutesSinceStart":55},{"time":"2024-11-01T20:10:00Z","cumulativeScore":50,"minutesSinceStart":70}]}`, rr.Body.String())
+	})
+
+	t.Run("admins can see the timeline of every team", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/balancer/api/teams/other-team/timeline", nil)
+		req.Header.Set("Cookie", fmt.Sprintf("team=%s", testutil.SignTestTeamname("admin")))
+		rr := httptest.NewRecorder()
+
+		setupServer().ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.JSONEq(t, `{"eventStart":"2024-11-01T19:00:00Z","timeline":[]}`, rr.Body.String())
+	})
+
+	t.Run("teams can't see the timeline of other teams", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/balancer/api/teams/other-team/timeline", nil)
+		req.Header.Set("Cookie", fmt.Sprintf("team=%s", testutil.SignTestTeamname("foobar")))
+		rr := httptest.NewRecorder()
+
+		setupServer().ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+	})
+
+	t.Run("requires a team cookie", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/balancer/api/teams/foobar/timeline", nil)
+		rr := httptest.NewRecorder()
+
+		setupServer().ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+
+	t.Run("returns a 404 for unknown teams", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/balancer/api/teams/unknown/timeline", nil)
+		req.Header.Set("Cookie", fmt.Sprintf("team=%s", testutil.SignTestTeamname("admin")))
+		rr := httptest.NewRecorder()
+
+		setupServer().ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+}
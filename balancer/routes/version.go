@@ -0,0 +1,38 @@
+package routes
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/juice-shop/multi-juicer/balancer/pkg/buildinfo"
+	"github.com/juice-shop/multi-juicer/balancer/pkg/bundle"
+)
+
+type VersionResponse struct {
+	Version        string `json:"version"`
+	Commit         string `json:"commit"`
+	BuildDate      string `json:"buildDate"`
+	ChallengesHash string `json:"challengesHash"`
+}
+
+// handleVersion returns the build information of the balancer and the hash of the loaded challenges.json, to correlate the behavior of a running balancer with a deploy
+func handleVersion(bundle *bundle.Bundle) http.Handler {
+	return http.HandlerFunc(
+		func(responseWriter http.ResponseWriter, req *http.Request) {
+			responseBytes, err := json.Marshal(VersionResponse{
+				Version:        buildinfo.Version,
+				Commit:         buildinfo.Commit,
+				BuildDate:      buildinfo.BuildDate,
+				ChallengesHash: bundle.ChallengesHash,
+			})
+			if err != nil {
+				bundle.Log.Printf("Failed to marshal version response: %s", err)
+				http.Error(responseWriter, "", http.StatusInternalServerError)
+				return
+			}
+			responseWriter.Header().Set("Content-Type", "application/json")
+			responseWriter.WriteHeader(http.StatusOK)
+			responseWriter.Write(responseBytes)
+		},
+	)
+}
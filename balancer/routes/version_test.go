@@ -0,0 +1,33 @@
+package routes
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/juice-shop/multi-juicer/balancer/pkg/bundle"
+	"github.com/juice-shop/multi-juicer/balancer/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVersionHandler(t *testing.T) {
+	t.Run("returns the build information and the challenges hash", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/balancer/api/version", nil)
+		rr := httptest.NewRecorder()
+
+		server := http.NewServeMux()
+		testBundle := testutil.NewTestBundle()
+		testBundle.ChallengesHash = bundle.HashChallenges([]byte("[]"))
+		AddRoutes(server, testBundle, nil)
+
+		server.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+		assert.JSONEq(t, `{
+			"version": "dev",
+			"commit": "unknown",
+			"buildDate": "unknown",
+			"challengesHash": "4f53cda18c2baa0c0354bb5f9a3ecbe5ed12ab4d8e11ba873c2f11161202b945"
+		}`, rr.Body.String())
+	})
+}
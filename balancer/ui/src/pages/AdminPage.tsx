@@ -101,6 +101,8 @@ interface Team {
   ready: boolean;
   createdAt: Date;
   lastConnect: Date;
+  cpuMillicores?: number;
+  memoryBytes?: number;
 }
 
 interface TeamRaw {
@@ -108,6 +110,8 @@ interface TeamRaw {
   ready: boolean;
   createdAt: string;
   lastConnect: string;
+  cpuMillicores?: number;
+  memoryBytes?: number;
 }
 
 async function fetchAdminData(): Promise<Team[]> {
@@ -198,6 +202,13 @@ export default function AdminPage() {
                 />{" "}
                 <ReadableTimestamp date={lastConnect} />
               </p>
+              {team.cpuMillicores !== undefined &&
+                team.memoryBytes !== undefined && (
+                  <p className="text-sm text-gray-800 dark:text-gray-200">
+                    {team.cpuMillicores}m CPU,{" "}
+                    {Math.round(team.memoryBytes / 1024 / 1024)}Mi memory
+                  </p>
+                )}
             </div>
 
             <DeleteInstanceButton team={team.team} />
@@ -6,6 +6,7 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"k8s.io/apimachinery/pkg/api/errors"
@@ -17,15 +18,30 @@ import (
 var logger = log.New(os.Stdout, "", log.LstdFlags)
 var namespace = os.Getenv("NAMESPACE")
 
+// dryRun only logs the instances which would be deleted, so that operators can validate the configured grace period against real traffic first
+var dryRun = false
+
 func main() {
 	logger.Println("Starting cleaner")
 
+	if strings.TrimSpace(namespace) == "" {
+		logger.Fatal("Environment variable 'NAMESPACE' must be set. Without it the cleaner can't find the JuiceShop instances to clean up.")
+	}
+
 	maxInactiveTimeString := os.Getenv("MAX_INACTIVE_DURATION")
 	maxInactiveTime, err := time.ParseDuration(maxInactiveTimeString)
 	if err != nil {
 		logger.Fatalf("Could not parse configured MAX_INACTIVE_DURATION: '%s'. Duration has to formatted like the following examples: \"12h\" for 12 hours, \"30m\" for 30 minutes.", maxInactiveTimeString)
 	}
 
+	dryRun, err = parseDryRun(os.Getenv("CLEANUP_DRY_RUN"))
+	if err != nil {
+		logger.Fatal(err)
+	}
+	if dryRun {
+		logger.Println("Running in dry-run mode, no instances will be deleted")
+	}
+
 	config, err := rest.InClusterConfig()
 	if err != nil {
 		panic(err.Error())
@@ -39,6 +55,10 @@ func main() {
 
 	cleanupSummary := runCleanup(clientset, currentTime, maxInactiveTime)
 
+	if dryRun {
+		logger.Printf("Finished dry-run. Would have deleted %d inactive instance(s)", cleanupSummary.WouldDeleteInstances)
+		return
+	}
 	logger.Println("Finished cleaning up JuiceShop deployments.")
 	logger.Printf("Deleted %d deployment(s) and %d service(s) successfully", cleanupSummary.SuccessfulDeploymentDeletions, cleanupSummary.SuccessfulServiceDeletions)
 	if (cleanupSummary.FailedDeploymentDeletions + cleanupSummary.FailedServiceDeletions) > 0 {
@@ -46,16 +66,33 @@ func main() {
 	}
 }
 
+// juiceShopInstanceLabelSelector selects all JuiceShop instances managed by MultiJuicer. Needs to match the selector used by the balancer
+const juiceShopInstanceLabelSelector = "app.kubernetes.io/name=juice-shop,app.kubernetes.io/part-of=multi-juicer"
+
 type CleanupSummary struct {
 	SuccessfulDeploymentDeletions int
 	SuccessfulServiceDeletions    int
 	FailedDeploymentDeletions     int
 	FailedServiceDeletions        int
+	// WouldDeleteInstances counts the inactive instances which weren't deleted because of the dry-run mode
+	WouldDeleteInstances int
+}
+
+// parseDryRun parses the CLEANUP_DRY_RUN env var, dry-run is disabled if it isn't set
+func parseDryRun(value string) (bool, error) {
+	if strings.TrimSpace(value) == "" {
+		return false, nil
+	}
+	enabled, err := strconv.ParseBool(strings.TrimSpace(value))
+	if err != nil {
+		return false, fmt.Errorf("could not parse configured CLEANUP_DRY_RUN: '%s'. Has to be either \"true\" or \"false\"", value)
+	}
+	return enabled, nil
 }
 
 func runCleanup(clientset kubernetes.Interface, currentTime time.Time, maxInactive time.Duration) CleanupSummary {
 	deployments, err := clientset.AppsV1().Deployments(namespace).List(context.Background(), metav1.ListOptions{
-		LabelSelector: "app.kubernetes.io/name=juice-shop,app.kubernetes.io/part-of=multi-juicer",
+		LabelSelector: juiceShopInstanceLabelSelector,
 	})
 
 	if err != nil {
@@ -74,6 +111,11 @@ func runCleanup(clientset kubernetes.Interface, currentTime time.Time, maxInacti
 	}
 
 	for _, deployment := range deployments.Items {
+		// parked instances are already scaled down by an admin and are kept to preserve the progress of the team
+		if parkedAt := deployment.Annotations["multi-juicer.owasp-juice.shop/parkedAt"]; parkedAt != "" {
+			logger.Printf("Skipping deployment %s as it has been parked at %s", deployment.Name, parkedAt)
+			continue
+		}
 		lastConnectedTimestampString, hasAnnotation := deployment.Annotations["multi-juicer.owasp-juice.shop/lastRequest"]
 		if !hasAnnotation || lastConnectedTimestampString == "" {
 			logger.Printf("Skipping deployment %s as it has no lastRequest annotation", deployment.Name)
@@ -87,6 +129,11 @@ func runCleanup(clientset kubernetes.Interface, currentTime time.Time, maxInacti
 
 		name := deployment.Name
 		if currentTime.Sub(time.UnixMilli(lastConnectedTimestamp)) > maxInactive {
+			if dryRun {
+				logger.Printf("Would delete instance '%s' as it has been inactive for longer than %s (dry-run)", name, maxInactive.String())
+				summary.WouldDeleteInstances++
+				continue
+			}
 			logger.Printf("Deleting instance '%s' as it has been inactive for longer than %s", name, maxInactive.String())
 			err = clientset.AppsV1().Deployments(namespace).Delete(context.Background(), name, metav1.DeleteOptions{})
 			if err != nil && !errors.IsNotFound(err) {
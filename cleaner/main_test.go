@@ -125,6 +125,22 @@ func TestRunCleanup(t *testing.T) {
 		}
 	})
 
+	t.Run("Inactive Parked Deployment - Should Not Be Deleted", func(t *testing.T) {
+		lastRequestTime := strconv.FormatInt(time.Now().Add(-60*time.Minute).UnixMilli(), 10)
+		deployment := createDeployment("team1", lastRequestTime)
+		deployment.Annotations["multi-juicer.owasp-juice.shop/parkedAt"] = "2024-11-01T19:55:48Z"
+		clientset := fake.NewSimpleClientset(deployment, createService("team1"))
+
+		currentTime := time.Now()
+		maxInactive := time.Duration(30 * time.Minute)
+
+		summary := runCleanup(clientset, currentTime, maxInactive)
+
+		if summary.SuccessfulDeploymentDeletions != 0 || summary.SuccessfulServiceDeletions != 0 {
+			t.Errorf("Expected no deletions, got: %v", summary)
+		}
+	})
+
 	t.Run("Failure to Delete Deployment", func(t *testing.T) {
 		clientset := fake.NewSimpleClientset(createDeployment("team1", strconv.FormatInt(time.Now().Add(-60*time.Minute).UnixMilli(), 10)), createService("team1"))
 
@@ -162,4 +178,42 @@ func TestRunCleanup(t *testing.T) {
 			t.Errorf("Expected 1 failed service deletion, got: %v", summary)
 		}
 	})
+	t.Run("Inactive Deployment In Dry-Run - Should Not Be Deleted", func(t *testing.T) {
+		dryRun = true
+		defer func() { dryRun = false }()
+
+		lastRequestTime := strconv.FormatInt(time.Now().Add(-60*time.Minute).UnixMilli(), 10)
+		activeRequestTime := strconv.FormatInt(time.Now().Add(-10*time.Minute).UnixMilli(), 10)
+		clientset := fake.NewSimpleClientset(
+			createDeployment("team1", lastRequestTime),
+			createService("team1"),
+			createDeployment("team2", activeRequestTime),
+		)
+
+		currentTime := time.Now()
+		maxInactive := time.Duration(30 * time.Minute)
+
+		summary := runCleanup(clientset, currentTime, maxInactive)
+
+		if summary.WouldDeleteInstances != 1 || summary.SuccessfulDeploymentDeletions != 0 || summary.SuccessfulServiceDeletions != 0 {
+			t.Errorf("Expected 1 instance to be reported without deletions, got: %v", summary)
+		}
+		for _, action := range clientset.Actions() {
+			if action.GetVerb() == "delete" {
+				t.Errorf("Expected no delete calls in dry-run, got: %v", action)
+			}
+		}
+	})
+}
+
+func TestParseDryRun(t *testing.T) {
+	if enabled, err := parseDryRun(""); err != nil || enabled {
+		t.Errorf("Expected dry-run to be disabled by default, got: %v, %v", enabled, err)
+	}
+	if enabled, err := parseDryRun("true"); err != nil || !enabled {
+		t.Errorf("Expected dry-run to be enabled, got: %v, %v", enabled, err)
+	}
+	if _, err := parseDryRun("maybe"); err == nil {
+		t.Errorf("Expected an error for an invalid value")
+	}
 }
@@ -6,12 +6,18 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"os"
 	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/speps/go-hashids/v2"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	appsv1 "k8s.io/api/apps/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 )
@@ -38,137 +44,356 @@ type Challenge struct {
 
 var challengeIdLookup = map[string]int{}
 
+// juiceShopInstanceLabelSelector selects all JuiceShop instances managed by MultiJuicer. Needs to match the selector used by the balancer
+const juiceShopInstanceLabelSelector = "app.kubernetes.io/name=juice-shop,app.kubernetes.io/part-of=multi-juicer"
+
 // JuiceShopChallenge represents a challenge in the Juice Shop config file. reduced to just the key, everything else is not needed
 type JuiceShopChallenge struct {
 	Key string `json:"key"`
 }
 
-func StartBackgroundSync(clientset *kubernetes.Clientset, workerCount int) {
+// challengesFilePath is where the Dockerfile places the challenges.json of the JuiceShop version in use
+const challengesFilePath = "/challenges.json"
+
+// StartBackgroundSync starts syncing the progress of all JuiceShop instances in the background. The sync stops once the context is canceled.
+// Returns an error if the sync can't be started, e.g. because the challenges.json can't be read
+func StartBackgroundSync(ctx context.Context, clientset *kubernetes.Clientset, workerCount int) error {
 	logger.Printf("Starting background-sync looking for JuiceShop challenge progress changes with %d workers", workerCount)
 
-	createChallengeIdLookup()
+	if err := createChallengeIdLookup(challengesFilePath); err != nil {
+		return err
+	}
+
+	if err := validateContinueCodeConfig(); err != nil {
+		return fmt.Errorf("invalid continue code config. This is fatal as the progress watchdog wouldn't be able to restore the progress of teams: %w", err)
+	}
 
 	progressUpdateJobs := make(chan ProgressUpdateJobs)
 
-	// Start 10 workers which fetch and update ContinueCodes based on the `progressUpdateJobs` queue / channel
+	// Start the workers which fetch and update ContinueCodes based on the `progressUpdateJobs` queue / channel
 	for i := 0; i < workerCount; i++ {
-		go workOnProgressUpdates(progressUpdateJobs, clientset)
+		go workOnProgressUpdates(ctx, progressUpdateJobs, clientset)
 	}
 
-	go createProgressUpdateJobs(progressUpdateJobs, clientset)
+	go createProgressUpdateJobs(ctx, progressUpdateJobs, clientset)
+	return nil
 }
 
-func createChallengeIdLookup() {
-	challengesBytes, err := os.ReadFile("/challenges.json")
+// createChallengeIdLookup reads the challenges.json at the given path to map between challenge keys, challenge ids and difficulties
+func createChallengeIdLookup(path string) error {
+	challengesBytes, err := os.ReadFile(path)
 	if err != nil {
-		panic(fmt.Errorf("failed to read challenges.json. This is fatal as the progress watchdog needs it to map between challenge keys and challenge ids: %w", err))
+		return fmt.Errorf("failed to read challenges.json. This is fatal as the progress watchdog needs it to map between challenge keys and challenge ids: %w", err)
 	}
 
 	var challenges []JuiceShopChallenge
 	err = json.Unmarshal(challengesBytes, &challenges)
 	if err != nil {
-		panic(fmt.Errorf("failed to decode challenges.json. This is fatal as the progress watchdog needs it to map between challenge keys and challenge ids: %w", err))
+		return fmt.Errorf("failed to decode challenges.json. This is fatal as the progress watchdog needs it to map between challenge keys and challenge ids: %w", err)
 	}
 
+	challengesHash = hashChallenges(challengesBytes)
+
 	for i, challenge := range challenges {
 		challengeIdLookup[challenge.Key] = i + 1
 	}
+	return nil
 }
 
 // Constantly lists all JuiceShops in managed by MultiJuicer and queues progressUpdatesJobs for them
-func createProgressUpdateJobs(progressUpdateJobs chan<- ProgressUpdateJobs, clientset *kubernetes.Clientset) {
+func createProgressUpdateJobs(ctx context.Context, progressUpdateJobs chan<- ProgressUpdateJobs, clientset *kubernetes.Clientset) {
+	// stops the workers once no more jobs get created
+	defer close(progressUpdateJobs)
+
 	namespace := os.Getenv("NAMESPACE")
 	for {
 		// Get Instances
 		opts := metav1.ListOptions{
-			LabelSelector: "app.kubernetes.io/name=juice-shop",
+			LabelSelector: juiceShopInstanceLabelSelector,
+		}
+		juiceShops, err := clientset.AppsV1().Deployments(namespace).List(ctx, opts)
+		if ctx.Err() != nil {
+			logger.Println("Background-sync stopped")
+			return
 		}
-		juiceShops, err := clientset.AppsV1().Deployments(namespace).List(context.TODO(), opts)
 		if err != nil {
-			panic(err.Error())
+			// listing can fail temporarily, e.g. while the kubernetes api is unavailable. retried in the next sync round
+			logger.Println(fmt.Errorf("failed to list JuiceShop instances: %w", err))
+			if !waitForNextSyncRound(ctx) {
+				logger.Println("Background-sync stopped")
+				return
+			}
+			continue
 		}
 
 		logger.Printf("Background-sync started syncing %d instances", len(juiceShops.Items))
 
+		teamsWithTerminatingPods := map[string]bool{}
+		pods, err := clientset.CoreV1().Pods(namespace).List(ctx, opts)
+		if err != nil {
+			logger.Println(fmt.Errorf("failed to list JuiceShop pods to skip terminating instances: %w", err))
+		} else {
+			for _, pod := range pods.Items {
+				if pod.DeletionTimestamp != nil {
+					teamsWithTerminatingPods[pod.Labels["team"]] = true
+				}
+			}
+		}
+
+		jobs := []ProgressUpdateJobs{}
 		for _, instance := range juiceShops.Items {
 			Team := instance.Labels["team"]
 
-			if instance.Status.ReadyReplicas != 1 {
+			if !isSyncable(instance, teamsWithTerminatingPods) {
+				continue
+			}
+			if err := ValidateTeamName(Team); err != nil {
+				logger.Printf("Skipping instance '%s' with invalid team label '%s': %s", instance.Name, Team, err)
 				continue
 			}
 
 			var lastChallengeProgress []ChallengeStatus
 			json.Unmarshal([]byte(instance.Annotations["multi-juicer.owasp-juice.shop/challenges"]), &lastChallengeProgress)
 
-			progressUpdateJobs <- ProgressUpdateJobs{
+			jobs = append(jobs, ProgressUpdateJobs{
 				Team:                  Team,
 				LastChallengeProgress: lastChallengeProgress,
-			}
+			})
+		}
+
+		if !enqueueProgressUpdateJobs(ctx, progressUpdateJobs, jobs) {
+			logger.Println("Background-sync stopped")
+			return
 		}
-		time.Sleep(60 * time.Second)
+
+		if !waitForNextSyncRound(ctx) {
+			logger.Println("Background-sync stopped")
+			return
+		}
+	}
+}
+
+// waitForNextSyncRound waits until the next sync round is due. Returns false if the context got canceled in the meantime
+func waitForNextSyncRound(ctx context.Context) bool {
+	select {
+	case <-time.After(60 * time.Second):
+		return true
+	case <-ctx.Done():
+		return false
 	}
 }
 
-func workOnProgressUpdates(progressUpdateJobs <-chan ProgressUpdateJobs, clientset *kubernetes.Clientset) {
+// isSyncable checks if the progress of the instance can be synced. Instances without a ready and available replica or which are being deleted are skipped, as requests to them would fail anyway.
+// Instances scaled to multiple replicas are synced as soon as one of them is available
+func isSyncable(instance appsv1.Deployment, teamsWithTerminatingPods map[string]bool) bool {
+	if instance.DeletionTimestamp != nil {
+		return false
+	}
+	if instance.Status.ReadyReplicas < 1 || instance.Status.AvailableReplicas < 1 {
+		return false
+	}
+	return !teamsWithTerminatingPods[instance.Labels["team"]]
+}
+
+// syncJobDelayThreshold is how long a job can wait for a free worker before it counts as delayed
+const syncJobDelayThreshold = 5 * time.Second
+
+// enqueueProgressUpdateJobs hands the jobs of a sync round to the workers, tracking how many are still waiting and how many got delayed because all workers were busy.
+// Returns false if the context got canceled before all jobs were handed over
+func enqueueProgressUpdateJobs(ctx context.Context, progressUpdateJobs chan<- ProgressUpdateJobs, jobs []ProgressUpdateJobs) bool {
+	pendingSyncJobs.Store(int64(len(jobs)))
+	defer pendingSyncJobs.Store(0)
+
+	delayedJobs := 0
+	var longestWait time.Duration
+	for _, job := range jobs {
+		enqueuedAt := time.Now()
+		select {
+		case progressUpdateJobs <- job:
+		case <-ctx.Done():
+			return false
+		}
+		pendingSyncJobs.Add(-1)
+
+		if wait := time.Since(enqueuedAt); wait > syncJobDelayThreshold {
+			delayedJobs++
+			delayedSyncJobsCounter.Add(ctx, 1)
+			longestWait = max(longestWait, wait)
+		}
+	}
+
+	if delayedJobs > 0 {
+		logger.Printf("Warning: %d of %d sync jobs waited longer than %s for a free worker (longest %s). The sync workers can't keep up, consider increasing SYNC_WORKER_COUNT", delayedJobs, len(jobs), syncJobDelayThreshold, longestWait.Round(time.Second))
+	}
+	return true
+}
+
+func workOnProgressUpdates(ctx context.Context, progressUpdateJobs <-chan ProgressUpdateJobs, clientset *kubernetes.Clientset) {
 	for job := range progressUpdateJobs {
-		lastChallengeProgress := job.LastChallengeProgress
-		challengeProgress, err := getCurrentChallengeProgress(job.Team)
+		syncProgress(ctx, job, clientset)
+	}
+}
+
+func syncProgress(ctx context.Context, job ProgressUpdateJobs, clientset *kubernetes.Clientset) {
+	ctx, span := Tracer.Start(ctx, "sync.progress", trace.WithAttributes(attribute.String("team", job.Team)))
+	defer span.End()
+
+	lastChallengeProgress := job.LastChallengeProgress
+	if staleChallenges := findStaleChallenges(lastChallengeProgress); len(staleChallenges) > 0 {
+		logger.Printf("Warning: saved progress of team '%s' contains challenges unknown to the current challenges.json, which would be lost when re-applying it: %s. Check if the challenges.json matches the JuiceShop version", job.Team, strings.Join(staleChallenges, ", "))
+		staleProgressCounter.Add(ctx, int64(len(staleChallenges)))
+		span.AddEvent("stale challenges in saved progress", trace.WithAttributes(attribute.StringSlice("challenges", staleChallenges)))
+	}
+
+	challengeProgress, err := getCurrentChallengeProgress(ctx, job.Team)
+
+	if err != nil {
+		logger.Println(fmt.Errorf("failed to fetch current Challenge Progress for team '%s' from Juice Shop: %w", job.Team, err))
+		return
+	}
+	challengeProgress = PreserveSolveTimes(challengeProgress, lastChallengeProgress)
+
+	switch CompareChallengeStates(challengeProgress, lastChallengeProgress) {
+	case ApplyCode:
+		logger.Printf("Last ContinueCode for team '%s' contains unsolved challenges", job.Team)
+		applyChallengeProgress(ctx, job.Team, lastChallengeProgress)
+
+		challengeProgress, err = getCurrentChallengeProgress(ctx, job.Team)
 
 		if err != nil {
-			logger.Println(fmt.Errorf("failed to fetch current Challenge Progress for team '%s' from Juice Shop: %w", job.Team, err))
-			continue
+			logger.Println(fmt.Errorf("failed to re-fetch challenge progress from Juice Shop for team '%s' to reapply it: %w", job.Team, err))
+			return
+		}
+		if unrestoredChallenges := findUnrestoredChallenges(lastChallengeProgress, challengeProgress); len(unrestoredChallenges) > 0 {
+			logger.Printf("Warning: re-applying the progress of team '%s' didn't restore the challenges: %s. Check the ContinueCode salt, alphabet and the JuiceShop version", job.Team, strings.Join(unrestoredChallenges, ", "))
+			continueCodeRoundtripMismatchCounter.Inc()
+			span.AddEvent("continue code didn't restore all challenges", trace.WithAttributes(attribute.StringSlice("challenges", unrestoredChallenges)))
+		}
+		PersistRestoredProgress(ctx, clientset, job.Team, PreserveSolveTimes(challengeProgress, lastChallengeProgress), time.Now())
+	case UpdateCache:
+		PersistProgress(ctx, clientset, job.Team, challengeProgress)
+	case NoOp:
+	}
+}
+
+// findUnrestoredChallenges returns the keys of the intended challenges which aren't solved after applying the ContinueCode. Stale challenges are ignored, as they can't be part of a ContinueCode anyway
+func findUnrestoredChallenges(intended []ChallengeStatus, restored []ChallengeStatus) []string {
+	restoredKeys := make(map[string]bool, len(restored))
+	for _, challenge := range restored {
+		restoredKeys[challenge.Key] = true
+	}
+	unrestoredChallenges := []string{}
+	for _, challenge := range intended {
+		if _, known := challengeIdLookup[challenge.Key]; known && !restoredKeys[challenge.Key] {
+			unrestoredChallenges = append(unrestoredChallenges, challenge.Key)
+		}
+	}
+	return unrestoredChallenges
+}
+
+// findStaleChallenges returns the keys of saved challenges which aren't part of the current challenges.json. These can't be encoded into a ContinueCode anymore
+func findStaleChallenges(challengeProgress []ChallengeStatus) []string {
+	staleChallenges := []string{}
+	for _, challenge := range challengeProgress {
+		if _, ok := challengeIdLookup[challenge.Key]; !ok {
+			staleChallenges = append(staleChallenges, challenge.Key)
 		}
+	}
+	return staleChallenges
+}
 
-		switch CompareChallengeStates(challengeProgress, lastChallengeProgress) {
-		case ApplyCode:
-			logger.Printf("Last ContinueCode for team '%s' contains unsolved challenges", job.Team)
-			applyChallengeProgress(job.Team, lastChallengeProgress)
+// juiceShopRequestAttempts is how often fetching the progress of a team is tried before the team is skipped for the current sync round
+var juiceShopRequestAttempts = 3
 
-			challengeProgress, err = getCurrentChallengeProgress(job.Team)
+// juiceShopRetryDelay is the delay before the first retry, it doubles after every failed attempt
+var juiceShopRetryDelay = 500 * time.Millisecond
 
-			if err != nil {
-				logger.Println(fmt.Errorf("failed to re-fetch challenge progress from Juice Shop for team '%s' to reapply it: %w", job.Team, err))
-				continue
-			}
-			PersistProgress(clientset, job.Team, challengeProgress)
-		case UpdateCache:
-			PersistProgress(clientset, job.Team, challengeProgress)
-		case NoOp:
+// ConfigureJuiceShopRequestAttempts overrides how often fetching the progress of a team is tried. An empty value keeps the default
+func ConfigureJuiceShopRequestAttempts(attempts string) error {
+	if attempts == "" {
+		return nil
+	}
+	parsedAttempts, err := strconv.Atoi(attempts)
+	if err != nil || parsedAttempts < 1 {
+		return fmt.Errorf("JUICE_SHOP_REQUEST_ATTEMPTS must be a positive integer, got '%s'", attempts)
+	}
+	juiceShopRequestAttempts = parsedAttempts
+	return nil
+}
+
+// retryableJuiceShopError marks failed requests which might succeed when retried, e.g. because the JuiceShop was briefly overloaded
+type retryableJuiceShopError struct {
+	err error
+}
+
+func (e retryableJuiceShopError) Error() string { return e.err.Error() }
+func (e retryableJuiceShopError) Unwrap() error { return e.err }
+
+func getCurrentChallengeProgress(ctx context.Context, team string) (_ []ChallengeStatus, err error) {
+	ctx, span := Tracer.Start(ctx, "juiceshop.getChallenges", trace.WithAttributes(attribute.String("team", team)))
+	defer func() {
+		if err != nil {
+			RecordSpanError(span, err)
+		}
+		span.End()
+	}()
+
+	delay := juiceShopRetryDelay
+	for attempt := 1; ; attempt++ {
+		challengeProgress, err := fetchChallengeProgress(ctx, team)
+		if err == nil || attempt >= juiceShopRequestAttempts || !errors.As(err, &retryableJuiceShopError{}) {
+			return challengeProgress, err
 		}
+		logger.Printf("Failed to fetch the challenge progress of team '%s' (attempt %d of %d), retrying in %s: %s", team, attempt, juiceShopRequestAttempts, delay, err)
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		delay *= 2
 	}
 }
 
-func getCurrentChallengeProgress(team string) ([]ChallengeStatus, error) {
-	url := fmt.Sprintf("http://juiceshop-%s:3000/api/challenges", team)
+// fetchChallengeProgress does a single request for the solved challenges of the team. Errors worth retrying are wrapped as retryableJuiceShopError
+func fetchChallengeProgress(ctx context.Context, team string) ([]ChallengeStatus, error) {
+	ctx, cancel := context.WithTimeout(ctx, juiceShopRequestTimeout)
+	defer cancel()
+
+	url := juiceShopUrl(team, "/api/challenges")
 
-	req, err := http.NewRequest("GET", url, bytes.NewBuffer([]byte{}))
+	req, err := http.NewRequestWithContext(ctx, "GET", url, bytes.NewBuffer([]byte{}))
 	if err != nil {
 		panic("Failed to create http request")
 	}
-	res, err := http.DefaultClient.Do(req)
+	injectTraceContext(ctx, req)
+	res, err := doJuiceShopRequest(req)
 	if err != nil {
-		return nil, errors.New("failed to fetch Challenge Status")
+		err = fmt.Errorf("failed to fetch Challenge Status: %w", err)
+		// connection errors are worth retrying, unless the sync itself got canceled
+		if ctx.Err() == nil || errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, retryableJuiceShopError{err}
+		}
+		return nil, err
 	}
 	defer res.Body.Close()
 
-	switch res.StatusCode {
-	case 200:
-		defer res.Body.Close()
-
+	switch {
+	case res.StatusCode == 200:
 		challengeResponse := ChallengeResponse{}
 
-		err = json.NewDecoder(res.Body).Decode(&challengeResponse)
+		err = decodeJuiceShopJsonResponse(res, &challengeResponse)
 		if err != nil {
-			return nil, errors.New("failed to parse JSON from Juice Shop Challenge Status response")
+			return nil, fmt.Errorf("failed to parse Juice Shop Challenge Status response: %w", err)
 		}
 
 		challengeStatus := make(ChallengeStatuses, 0)
 
+		now := time.Now()
 		for _, challenge := range challengeResponse.Data {
 			if challenge.Solved {
 				challengeStatus = append(challengeStatus, ChallengeStatus{
 					Key:      challenge.Key,
-					SolvedAt: challenge.UpdatedAt,
+					SolvedAt: NormalizeSolveTime(challenge.UpdatedAt, now),
 				})
 			}
 		}
@@ -176,41 +401,188 @@ func getCurrentChallengeProgress(team string) ([]ChallengeStatus, error) {
 		sort.Stable(challengeStatus)
 
 		return challengeStatus, nil
+	case res.StatusCode >= 500:
+		return nil, retryableJuiceShopError{fmt.Errorf("unexpected response status code '%d' from Juice Shop", res.StatusCode)}
 	default:
 		return nil, fmt.Errorf("unexpected response status code '%d' from Juice Shop", res.StatusCode)
 	}
 }
 
-func applyChallengeProgress(team string, challengeProgress []ChallengeStatus) {
-	continueCode, err := GenerateContinueCode(challengeProgress)
+// juiceShopRequestTimeout limits how long a single request to a JuiceShop can take, so that an unresponsive instance doesn't block a sync worker
+const juiceShopRequestTimeout = 10 * time.Second
+
+// maxJuiceShopResponseSize caps how much of a Juice Shop response gets read. The challenge list of current Juice Shop versions is a few hundred KB
+const maxJuiceShopResponseSize = 10 * 1024 * 1024
+
+// maxResponseSnippetLength caps how much of an unexpected response body is included in errors
+const maxResponseSnippetLength = 200
+
+// decodeJuiceShopJsonResponse decodes a json response from the Juice Shop.
+// Misbehaving instances or ingresses can respond with html error pages using a 200 status code, so the errors include the start of the body to make this easy to spot in the logs
+func decodeJuiceShopJsonResponse(res *http.Response, target any) error {
+	body, err := io.ReadAll(io.LimitReader(res.Body, maxJuiceShopResponseSize+1))
+	if err != nil {
+		return fmt.Errorf("failed to read response body: %w", err)
+	}
+	if len(body) > maxJuiceShopResponseSize {
+		return fmt.Errorf("response body exceeds the maximum size of %d bytes", maxJuiceShopResponseSize)
+	}
+
+	contentType := res.Header.Get("Content-Type")
+	if contentType != "" && !strings.Contains(contentType, "json") {
+		return fmt.Errorf("expected a json response but got content type '%s': %s", contentType, responseSnippet(body))
+	}
+
+	if err := json.Unmarshal(body, target); err != nil {
+		return fmt.Errorf("invalid json (%w): %s", err, responseSnippet(body))
+	}
+	return nil
+}
+
+// responseSnippet returns the start of the body, quoted so that newlines in html pages don't break up log lines
+func responseSnippet(body []byte) string {
+	if len(body) > maxResponseSnippetLength {
+		return fmt.Sprintf("%q...", body[:maxResponseSnippetLength])
+	}
+	return fmt.Sprintf("%q", body)
+}
+
+func applyChallengeProgress(ctx context.Context, team string, challengeProgress []ChallengeStatus) {
+	ctx, span := Tracer.Start(ctx, "juiceshop.applyContinueCode", trace.WithAttributes(attribute.String("team", team)))
+	defer span.End()
+
+	for _, salt := range continueCodeSalts {
+		applied := applyChallengeProgressWithSalt(ctx, team, challengeProgress, salt)
+		if applied {
+			if len(continueCodeSalts) > 1 {
+				logger.Printf("Applied ContinueCode for team '%s' using salt '%s'", team, salt)
+			}
+			return
+		}
+	}
+	logger.Printf("Failed to apply ContinueCode for team '%s' with any of the %d configured salts", team, len(continueCodeSalts))
+	RecordSpanError(span, errors.New("none of the configured continue code salts got accepted"))
+}
+
+// applyChallengeProgressWithSalt returns true if the juice shop accepted the ContinueCode
+func applyChallengeProgressWithSalt(ctx context.Context, team string, challengeProgress []ChallengeStatus, salt string) bool {
+	ctx, cancel := context.WithTimeout(ctx, juiceShopRequestTimeout)
+	defer cancel()
+
+	continueCode, err := generateContinueCodeWithSalt(challengeProgress, salt)
 	if err != nil {
 		logger.Println(fmt.Errorf("failed to encode challenge progress into continue code: %w", err))
-		return
+		return false
+	}
+
+	matches, err := verifyContinueCodeWithSalt(continueCode, challengeProgress, salt)
+	if err != nil {
+		logger.Println(fmt.Errorf("failed to verify generated continue code for team '%s': %w", team, err))
+	} else if !matches {
+		logger.Printf("Warning: generated continue code for team '%s' doesn't decode to the expected challenges. Check the hashids salt and alphabet", team)
 	}
 
-	url := fmt.Sprintf("http://juiceshop-%s:3000/rest/continue-code/apply/%s", team, continueCode)
+	url := juiceShopUrl(team, fmt.Sprintf("/rest/continue-code/apply/%s", continueCode))
 
-	req, err := http.NewRequest("PUT", url, bytes.NewBuffer([]byte{}))
+	req, err := http.NewRequestWithContext(ctx, "PUT", url, bytes.NewBuffer([]byte{}))
 	if err != nil {
 		logger.Println(fmt.Errorf("failed to create http request to set the current ContinueCode: %w", err))
-		return
+		return false
 	}
-	res, err := http.DefaultClient.Do(req)
+	injectTraceContext(ctx, req)
+	res, err := doJuiceShopRequest(req)
 	if err != nil {
 		logger.Println(fmt.Errorf("failed to set the current ContinueCode to juice shop: %w", err))
-		return
+		return false
 	}
 	defer res.Body.Close()
+
+	return res.StatusCode == http.StatusOK
 }
 
-// ParseContinueCode returns the number of challenges solved by this ContinueCode
-func GenerateContinueCode(challenges []ChallengeStatus) (string, error) {
+const defaultContinueCodeSalt = "this is my salt"
+
+// continueCodeSalts are tried in order when applying a ContinueCode. The first one is used for everything else
+var continueCodeSalts = parseContinueCodeSalts(os.Getenv("CONTINUE_CODE_SALTS"))
+
+// parseContinueCodeSalts parses a comma separated list of salts, falling back to the default JuiceShop salt if none are configured
+func parseContinueCodeSalts(saltsEnv string) []string {
+	salts := []string{}
+	for _, salt := range strings.Split(saltsEnv, ",") {
+		if salt != "" {
+			salts = append(salts, salt)
+		}
+	}
+	if len(salts) == 0 {
+		return []string{defaultContinueCodeSalt}
+	}
+	return salts
+}
+
+const defaultContinueCodeMinLength = 60
+const defaultContinueCodeAlphabet = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ1234567890"
+
+// continueCodeMinLength and continueCodeAlphabet need to match the hashids config of the JuiceShop, otherwise ContinueCodes decode to the wrong challenges
+var continueCodeMinLength = defaultContinueCodeMinLength
+var continueCodeAlphabet = defaultContinueCodeAlphabet
+
+// ConfigureContinueCodes overrides the hashids min length and alphabet used for ContinueCodes. Empty values keep the defaults of current JuiceShop versions
+func ConfigureContinueCodes(minLength string, alphabet string) error {
+	if minLength != "" {
+		parsedMinLength, err := strconv.Atoi(minLength)
+		if err != nil || parsedMinLength < 0 {
+			return fmt.Errorf("CONTINUE_CODE_MIN_LENGTH must be a non negative integer, got '%s'", minLength)
+		}
+		continueCodeMinLength = parsedMinLength
+	}
+	if alphabet != "" {
+		continueCodeAlphabet = alphabet
+	}
+	return nil
+}
+
+// validateContinueCodeConfig checks that a ContinueCode containing all known challenges decodes back to the same challenges with every configured salt
+func validateContinueCodeConfig() error {
+	challenges := []ChallengeStatus{}
+	for key := range challengeIdLookup {
+		challenges = append(challenges, ChallengeStatus{Key: key})
+	}
+
+	for _, salt := range continueCodeSalts {
+		continueCode, err := generateContinueCodeWithSalt(challenges, salt)
+		if err != nil {
+			return fmt.Errorf("failed to generate continue code with salt '%s': %w", salt, err)
+		}
+		matches, err := verifyContinueCodeWithSalt(continueCode, challenges, salt)
+		if err != nil {
+			return fmt.Errorf("failed to decode continue code with salt '%s': %w", salt, err)
+		}
+		if !matches {
+			return fmt.Errorf("continue code generated with salt '%s' doesn't decode to the same challenges", salt)
+		}
+	}
+	return nil
+}
+
+func newContinueCodeHashID(salt string) (*hashids.HashID, error) {
 	hd := hashids.NewData()
-	hd.Salt = "this is my salt"
-	hd.MinLength = 60
-	hd.Alphabet = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ1234567890"
+	hd.Salt = salt
+	hd.MinLength = continueCodeMinLength
+	hd.Alphabet = continueCodeAlphabet
+
+	return hashids.NewWithData(hd)
+}
+
+// GenerateContinueCode encodes the solved challenges into a ContinueCode which can be applied to a JuiceShop
+func GenerateContinueCode(challenges []ChallengeStatus) (string, error) {
+	return generateContinueCodeWithSalt(challenges, continueCodeSalts[0])
+}
 
-	hashIDClient, _ := hashids.NewWithData(hd)
+func generateContinueCodeWithSalt(challenges []ChallengeStatus, salt string) (string, error) {
+	hashIDClient, err := newContinueCodeHashID(salt)
+	if err != nil {
+		return "", err
+	}
 
 	challengeIds := []int{}
 
@@ -226,3 +598,34 @@ func GenerateContinueCode(challenges []ChallengeStatus) (string, error) {
 
 	return continueCode, nil
 }
+
+// VerifyContinueCode decodes the ContinueCode and checks that it contains exactly the challenge ids of the expected challenges
+func VerifyContinueCode(code string, expected []ChallengeStatus) (bool, error) {
+	return verifyContinueCodeWithSalt(code, expected, continueCodeSalts[0])
+}
+
+func verifyContinueCodeWithSalt(code string, expected []ChallengeStatus, salt string) (bool, error) {
+	hashIDClient, err := newContinueCodeHashID(salt)
+	if err != nil {
+		return false, err
+	}
+
+	decodedIds, err := hashIDClient.DecodeWithError(code)
+	if err != nil {
+		return false, fmt.Errorf("failed to decode continue code: %w", err)
+	}
+
+	expectedIds := map[int]bool{}
+	for _, challenge := range expected {
+		expectedIds[challengeIdLookup[challenge.Key]] = true
+	}
+
+	decodedIdSet := map[int]bool{}
+	for _, id := range decodedIds {
+		if !expectedIds[id] {
+			return false, nil
+		}
+		decodedIdSet[id] = true
+	}
+	return len(decodedIdSet) == len(expectedIds), nil
+}
@@ -0,0 +1,345 @@
+package internal
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestVerifyContinueCode(t *testing.T) {
+	challengeIdLookup = map[string]int{
+		"scoreBoardChallenge":    1,
+		"nullByteChallenge":      2,
+		"ghostLoginChallenge":    3,
+		"httpHeaderXssChallenge": 4,
+	}
+
+	solved := []ChallengeStatus{{Key: "scoreBoardChallenge", SolvedAt: "foobar"}, {Key: "ghostLoginChallenge", SolvedAt: "foobar"}}
+	continueCode, err := GenerateContinueCode(solved)
+	assert.Nil(t, err)
+
+	matches, err := VerifyContinueCode(continueCode, solved)
+	assert.Nil(t, err)
+	assert.True(t, matches, "Should match the challenges it was generated from")
+
+	matches, err = VerifyContinueCode(continueCode, []ChallengeStatus{{Key: "ghostLoginChallenge", SolvedAt: "foobar"}, {Key: "scoreBoardChallenge", SolvedAt: "foobar"}})
+	assert.Nil(t, err)
+	assert.True(t, matches, "Should not depend on the order of the challenges")
+
+	matches, err = VerifyContinueCode(continueCode, []ChallengeStatus{{Key: "scoreBoardChallenge", SolvedAt: "foobar"}})
+	assert.Nil(t, err)
+	assert.False(t, matches, "Should not match if the code contains additional challenges")
+
+	matches, err = VerifyContinueCode(continueCode, append(solved, ChallengeStatus{Key: "nullByteChallenge", SolvedAt: "foobar"}))
+	assert.Nil(t, err)
+	assert.False(t, matches, "Should not match if the code is missing challenges")
+
+	_, err = VerifyContinueCode("not-a-continue-code", solved)
+	assert.NotNil(t, err, "Should fail for codes which can't be decoded")
+}
+
+func TestParseContinueCodeSalts(t *testing.T) {
+	assert.Equal(t, []string{"this is my salt"}, parseContinueCodeSalts(""), "Should default to the JuiceShop salt")
+	assert.Equal(t, []string{"new salt", "this is my salt"}, parseContinueCodeSalts("new salt,this is my salt"))
+	assert.Equal(t, []string{"new salt"}, parseContinueCodeSalts("new salt,,"), "Should ignore empty entries")
+}
+
+func TestGenerateContinueCodeWithSalt(t *testing.T) {
+	challengeIdLookup = map[string]int{
+		"scoreBoardChallenge": 1,
+		"nullByteChallenge":   2,
+	}
+	solved := []ChallengeStatus{{Key: "scoreBoardChallenge", SolvedAt: "foobar"}, {Key: "nullByteChallenge", SolvedAt: "foobar"}}
+
+	defaultCode, err := GenerateContinueCode(solved)
+	assert.Nil(t, err)
+	otherSaltCode, err := generateContinueCodeWithSalt(solved, "some other salt")
+	assert.Nil(t, err)
+	assert.NotEqual(t, defaultCode, otherSaltCode, "Should generate different codes for different salts")
+
+	matches, err := verifyContinueCodeWithSalt(otherSaltCode, solved, "some other salt")
+	assert.Nil(t, err)
+	assert.True(t, matches)
+}
+
+func TestIsSyncable(t *testing.T) {
+	createInstance := func(team string, readyReplicas int32, deletionTimestamp *metav1.Time) appsv1.Deployment {
+		return appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              "juiceshop-" + team,
+				Labels:            map[string]string{"team": team},
+				DeletionTimestamp: deletionTimestamp,
+			},
+			Status: appsv1.DeploymentStatus{ReadyReplicas: readyReplicas, AvailableReplicas: readyReplicas},
+		}
+	}
+
+	assert.True(t, isSyncable(createInstance("foobar", 1, nil), map[string]bool{}), "Should sync ready instances")
+	assert.False(t, isSyncable(createInstance("foobar", 0, nil), map[string]bool{}), "Should skip instances which aren't ready")
+	assert.True(t, isSyncable(createInstance("foobar", 2, nil), map[string]bool{}), "Should sync instances with multiple ready replicas")
+	notAvailable := createInstance("foobar", 1, nil)
+	notAvailable.Status.AvailableReplicas = 0
+	assert.False(t, isSyncable(notAvailable, map[string]bool{}), "Should skip instances whose ready replica isn't available yet")
+	assert.False(t, isSyncable(createInstance("foobar", 1, &metav1.Time{Time: time.Now()}), map[string]bool{}), "Should skip deployments which are being deleted")
+	assert.False(t, isSyncable(createInstance("foobar", 1, nil), map[string]bool{"foobar": true}), "Should skip instances with terminating pods")
+	assert.True(t, isSyncable(createInstance("foobar", 1, nil), map[string]bool{"other-team": true}), "Should only skip the team with terminating pods")
+}
+
+func TestDecodeJuiceShopJsonResponse(t *testing.T) {
+	createResponse := func(contentType string, body string) *http.Response {
+		header := http.Header{}
+		if contentType != "" {
+			header.Set("Content-Type", contentType)
+		}
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     header,
+			Body:       io.NopCloser(strings.NewReader(body)),
+		}
+	}
+
+	t.Run("decodes json responses", func(t *testing.T) {
+		challengeResponse := ChallengeResponse{}
+		err := decodeJuiceShopJsonResponse(createResponse("application/json; charset=utf-8", `{"status":"success","data":[{"key":"scoreBoardChallenge","solved":true}]}`), &challengeResponse)
+		assert.Nil(t, err)
+		assert.Equal(t, "scoreBoardChallenge", challengeResponse.Data[0].Key)
+	})
+
+	t.Run("includes the start of the body for html responses", func(t *testing.T) {
+		htmlPage := "<html><body><h1>Bad Gateway</h1>" + strings.Repeat("x", 500) + "</body></html>"
+		err := decodeJuiceShopJsonResponse(createResponse("text/html", htmlPage), &ChallengeResponse{})
+		assert.ErrorContains(t, err, "content type 'text/html'")
+		assert.ErrorContains(t, err, "<h1>Bad Gateway</h1>")
+		assert.NotContains(t, err.Error(), "</body>", "Should truncate the body")
+	})
+
+	t.Run("includes the start of the body for invalid json without content type", func(t *testing.T) {
+		err := decodeJuiceShopJsonResponse(createResponse("", "not json"), &ChallengeResponse{})
+		assert.ErrorContains(t, err, "invalid json")
+		assert.ErrorContains(t, err, `"not json"`)
+	})
+
+	t.Run("rejects responses exceeding the maximum size", func(t *testing.T) {
+		err := decodeJuiceShopJsonResponse(createResponse("application/json", strings.Repeat(" ", maxJuiceShopResponseSize+1)), &ChallengeResponse{})
+		assert.ErrorContains(t, err, "exceeds the maximum size")
+	})
+}
+
+func TestConfigureContinueCodes(t *testing.T) {
+	t.Cleanup(func() {
+		continueCodeMinLength = defaultContinueCodeMinLength
+		continueCodeAlphabet = defaultContinueCodeAlphabet
+	})
+	challengeIdLookup = map[string]int{
+		"scoreBoardChallenge": 1,
+		"nullByteChallenge":   2,
+	}
+
+	assert.Nil(t, ConfigureContinueCodes("", ""))
+	assert.Equal(t, 60, continueCodeMinLength, "Should keep the defaults")
+	assert.Nil(t, validateContinueCodeConfig())
+
+	assert.Nil(t, ConfigureContinueCodes("40", "abcdefghijklmnopqrstuvwxyz"))
+	assert.Equal(t, 40, continueCodeMinLength)
+	assert.Nil(t, validateContinueCodeConfig())
+	continueCode, err := GenerateContinueCode([]ChallengeStatus{{Key: "scoreBoardChallenge", SolvedAt: "foobar"}})
+	assert.Nil(t, err)
+	assert.Regexp(t, "^[a-z]{40,}$", continueCode)
+
+	assert.NotNil(t, ConfigureContinueCodes("-1", ""), "Should reject negative min lengths")
+	assert.NotNil(t, ConfigureContinueCodes("sixty", ""), "Should reject non numeric min lengths")
+
+	assert.Nil(t, ConfigureContinueCodes("", "abc"))
+	assert.NotNil(t, validateContinueCodeConfig(), "Should fail for alphabets hashids can't use")
+}
+
+func TestJuiceShopRequestsAreCanceledWithTheContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := getCurrentChallengeProgress(ctx, "foobar")
+	assert.ErrorIs(t, err, context.Canceled)
+
+	applied := applyChallengeProgressWithSalt(ctx, "foobar", []ChallengeStatus{}, defaultContinueCodeSalt)
+	assert.False(t, applied)
+}
+
+func TestFindStaleChallenges(t *testing.T) {
+	challengeIdLookup = map[string]int{
+		"scoreBoardChallenge": 1,
+		"nullByteChallenge":   2,
+	}
+
+	assert.Equal(t, []string{}, findStaleChallenges([]ChallengeStatus{{Key: "scoreBoardChallenge", SolvedAt: "foobar"}}))
+	assert.Equal(t, []string{"removedChallenge"}, findStaleChallenges([]ChallengeStatus{
+		{Key: "scoreBoardChallenge", SolvedAt: "foobar"},
+		{Key: "removedChallenge", SolvedAt: "foobar"},
+	}))
+}
+
+func TestFindUnrestoredChallenges(t *testing.T) {
+	challengeIdLookup = map[string]int{
+		"scoreBoardChallenge": 1,
+		"nullByteChallenge":   2,
+	}
+
+	intended := []ChallengeStatus{
+		{Key: "scoreBoardChallenge", SolvedAt: "2024-11-01T19:55:48.211Z"},
+		{Key: "nullByteChallenge", SolvedAt: "2024-11-01T20:10:00Z"},
+		{Key: "removedChallenge", SolvedAt: "2024-11-01T20:15:00Z"},
+	}
+	assert.Equal(t, []string{}, findUnrestoredChallenges(intended, []ChallengeStatus{
+		{Key: "nullByteChallenge", SolvedAt: "2024-11-02T08:00:00Z"},
+		{Key: "scoreBoardChallenge", SolvedAt: "2024-11-02T08:00:00Z"},
+	}), "Stale challenges can't be restored and shouldn't count as mismatch")
+	assert.Equal(t, []string{"nullByteChallenge"}, findUnrestoredChallenges(intended, []ChallengeStatus{
+		{Key: "scoreBoardChallenge", SolvedAt: "2024-11-02T08:00:00Z"},
+	}))
+}
+
+func TestEnqueueProgressUpdateJobs(t *testing.T) {
+	t.Run("tracks the jobs still waiting for a worker", func(t *testing.T) {
+		progressUpdateJobs := make(chan ProgressUpdateJobs)
+		jobs := []ProgressUpdateJobs{{Team: "foo"}, {Team: "bar"}}
+
+		done := make(chan bool)
+		go func() {
+			done <- enqueueProgressUpdateJobs(context.Background(), progressUpdateJobs, jobs)
+		}()
+
+		assert.Equal(t, "foo", (<-progressUpdateJobs).Team)
+		assert.Eventually(t, func() bool { return pendingSyncJobs.Load() == 1 }, time.Second, time.Millisecond)
+		assert.Equal(t, "bar", (<-progressUpdateJobs).Team)
+		assert.True(t, <-done)
+		assert.Equal(t, int64(0), pendingSyncJobs.Load())
+	})
+
+	t.Run("stops once the context is canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		assert.False(t, enqueueProgressUpdateJobs(ctx, make(chan ProgressUpdateJobs), []ProgressUpdateJobs{{Team: "foo"}}))
+		assert.Equal(t, int64(0), pendingSyncJobs.Load())
+	})
+}
+
+func TestCreateChallengeIdLookup(t *testing.T) {
+	t.Cleanup(func() {
+		challengeIdLookup = map[string]int{}
+		challengesHash = ""
+	})
+
+	t.Run("creates the lookup from the challenges.json", func(t *testing.T) {
+		challengeIdLookup = map[string]int{}
+		path := filepath.Join(t.TempDir(), "challenges.json")
+		os.WriteFile(path, []byte(`[{"key":"scoreBoardChallenge","difficulty":1},{"key":"nullByteChallenge","difficulty":4}]`), 0o644)
+
+		err := createChallengeIdLookup(path)
+
+		assert.Nil(t, err)
+		assert.Equal(t, map[string]int{"scoreBoardChallenge": 1, "nullByteChallenge": 2}, challengeIdLookup)
+	})
+
+	t.Run("returns an error if the challenges.json doesn't exist", func(t *testing.T) {
+		err := createChallengeIdLookup(filepath.Join(t.TempDir(), "challenges.json"))
+
+		assert.ErrorContains(t, err, "failed to read challenges.json")
+	})
+
+	t.Run("returns an error if the challenges.json is invalid", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "challenges.json")
+		os.WriteFile(path, []byte(`not json`), 0o644)
+
+		err := createChallengeIdLookup(path)
+
+		assert.ErrorContains(t, err, "failed to decode challenges.json")
+	})
+}
+
+type roundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+func TestGetCurrentChallengeProgressRetries(t *testing.T) {
+	respondWith := func(t *testing.T, responses ...func() (*http.Response, error)) *int {
+		resetJuiceShopClient(t)
+		originalDelay := juiceShopRetryDelay
+		juiceShopRetryDelay = time.Millisecond
+		t.Cleanup(func() { juiceShopRetryDelay = originalDelay })
+
+		requests := 0
+		juiceShopHttpClient = &http.Client{Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			response := responses[min(requests, len(responses)-1)]
+			requests++
+			return response()
+		})}
+		return &requests
+	}
+	withStatus := func(status int, body string) func() (*http.Response, error) {
+		return func() (*http.Response, error) {
+			return &http.Response{StatusCode: status, Body: io.NopCloser(strings.NewReader(body)), Header: http.Header{}}, nil
+		}
+	}
+	connectionReset := func() (*http.Response, error) {
+		return nil, syscall.ECONNRESET
+	}
+	solvedChallenges := `{"status":"success","data":[{"key":"scoreBoardChallenge","solved":true,"updatedAt":"2024-11-01T19:55:48.211Z"}]}`
+
+	t.Run("retries server errors", func(t *testing.T) {
+		requests := respondWith(t, withStatus(503, "overloaded"), withStatus(200, solvedChallenges))
+
+		progress, err := getCurrentChallengeProgress(context.Background(), "foobar")
+		assert.Nil(t, err)
+		assert.Equal(t, []ChallengeStatus{{Key: "scoreBoardChallenge", SolvedAt: "2024-11-01T19:55:48.211Z"}}, progress)
+		assert.Equal(t, 2, *requests)
+	})
+
+	t.Run("retries connection errors", func(t *testing.T) {
+		requests := respondWith(t, connectionReset, withStatus(200, solvedChallenges))
+
+		_, err := getCurrentChallengeProgress(context.Background(), "foobar")
+		assert.Nil(t, err)
+		assert.Equal(t, 2, *requests)
+	})
+
+	t.Run("doesn't retry client errors", func(t *testing.T) {
+		requests := respondWith(t, withStatus(404, "not found"), withStatus(200, solvedChallenges))
+
+		_, err := getCurrentChallengeProgress(context.Background(), "foobar")
+		assert.NotNil(t, err)
+		assert.Equal(t, 1, *requests)
+	})
+
+	t.Run("gives up after the configured attempts", func(t *testing.T) {
+		requests := respondWith(t, withStatus(500, "broken"))
+		originalAttempts := juiceShopRequestAttempts
+		t.Cleanup(func() { juiceShopRequestAttempts = originalAttempts })
+		assert.Nil(t, ConfigureJuiceShopRequestAttempts("4"))
+
+		_, err := getCurrentChallengeProgress(context.Background(), "foobar")
+		assert.ErrorContains(t, err, "unexpected response status code '500'")
+		assert.Equal(t, 4, *requests)
+	})
+}
+
+func TestConfigureJuiceShopRequestAttempts(t *testing.T) {
+	originalAttempts := juiceShopRequestAttempts
+	t.Cleanup(func() { juiceShopRequestAttempts = originalAttempts })
+
+	assert.Nil(t, ConfigureJuiceShopRequestAttempts(""))
+	assert.Equal(t, 3, juiceShopRequestAttempts, "Should keep the default")
+	assert.Nil(t, ConfigureJuiceShopRequestAttempts("1"))
+	assert.Equal(t, 1, juiceShopRequestAttempts)
+	assert.NotNil(t, ConfigureJuiceShopRequestAttempts("0"))
+	assert.NotNil(t, ConfigureJuiceShopRequestAttempts("many"))
+}
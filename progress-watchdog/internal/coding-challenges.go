@@ -0,0 +1,130 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/speps/go-hashids/v2"
+)
+
+// CodingChallengeKind is one of the two coding challenge modes of the JuiceShop. Each mode tracks its progress in a separate ContinueCode
+type CodingChallengeKind string
+
+const (
+	FindIt CodingChallengeKind = "findIt"
+	FixIt  CodingChallengeKind = "fixIt"
+)
+
+// codingChallengesEnabled can be turned off for events which don't use the coding challenges, skipping all FindIt / FixIt handling
+var codingChallengesEnabled = true
+
+// ConfigureCodingChallenges parses the ENABLE_CODING_CHALLENGES env var. An empty value keeps the coding challenges enabled
+func ConfigureCodingChallenges(enabled string) error {
+	if enabled == "" {
+		return nil
+	}
+	parsedEnabled, err := strconv.ParseBool(enabled)
+	if err != nil {
+		return fmt.Errorf("ENABLE_CODING_CHALLENGES must be either 'true' or 'false', got '%s'", enabled)
+	}
+	codingChallengesEnabled = parsedEnabled
+	if !codingChallengesEnabled {
+		logger.Println("Coding challenges are disabled, FindIt / FixIt continue codes sent with webhooks are ignored")
+	}
+	return nil
+}
+
+// CodingChallengesEnabled reports if FindIt / FixIt continue codes should be handled
+func CodingChallengesEnabled() bool {
+	return codingChallengesEnabled
+}
+
+// codingChallengeSalts are the hashids salts the JuiceShop uses for the FindIt / FixIt ContinueCodes
+var codingChallengeSalts = map[CodingChallengeKind]string{
+	FindIt: "this is the salt for findIt challenges",
+	FixIt:  "yet another salt for the fixIt challenges",
+}
+
+// CodingChallengeAnnotation returns the deployment annotation the ContinueCode of the coding challenge kind is saved in
+func CodingChallengeAnnotation(kind CodingChallengeKind) string {
+	if kind == FindIt {
+		return "multi-juicer.owasp-juice.shop/continueCodeFindIt"
+	}
+	return "multi-juicer.owasp-juice.shop/continueCodeFixIt"
+}
+
+// ValidateCodingChallengeContinueCode checks that the ContinueCode decodes to known challenges using the salt of the coding challenge kind
+func ValidateCodingChallengeContinueCode(kind CodingChallengeKind, code string) error {
+	hd := hashids.NewData()
+	hd.Salt = codingChallengeSalts[kind]
+	hd.MinLength = continueCodeMinLength
+	hd.Alphabet = continueCodeAlphabet
+	hashIDClient, err := hashids.NewWithData(hd)
+	if err != nil {
+		return err
+	}
+
+	decodedIds, err := hashIDClient.DecodeWithError(code)
+	if err != nil {
+		return fmt.Errorf("failed to decode %s continue code: %w", kind, err)
+	}
+
+	knownIds := map[int]bool{}
+	for _, id := range challengeIdLookup {
+		knownIds[id] = true
+	}
+	for _, id := range decodedIds {
+		if !knownIds[id] {
+			return fmt.Errorf("%s continue code contains the unknown challenge id %d", kind, id)
+		}
+	}
+	return nil
+}
+
+// ResolveCodingChallengeContinueCode returns the ContinueCode of the coding challenge kind which should be saved for the team.
+// Prefers the code sent with the webhook, as the instance might already be gone when re-fetching it. Falls back to fetching the code from the instance if the webhook code doesn't decode
+func ResolveCodingChallengeContinueCode(ctx context.Context, team string, kind CodingChallengeKind, webhookCode string) (string, error) {
+	err := ValidateCodingChallengeContinueCode(kind, webhookCode)
+	if err == nil {
+		return webhookCode, nil
+	}
+	logger.Printf("Received invalid %s continue code for team '%s' via webhook, fetching it from the instance instead: %s", kind, team, err)
+
+	fetchedCode, err := fetchCodingChallengeContinueCode(ctx, team, kind)
+	if err != nil {
+		return "", err
+	}
+	if err := ValidateCodingChallengeContinueCode(kind, fetchedCode); err != nil {
+		return "", err
+	}
+	return fetchedCode, nil
+}
+
+func fetchCodingChallengeContinueCode(ctx context.Context, team string, kind CodingChallengeKind) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, juiceShopRequestTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", juiceShopUrl(team, fmt.Sprintf("/rest/continue-code-%s", kind)), nil)
+	if err != nil {
+		return "", err
+	}
+	injectTraceContext(ctx, req)
+	res, err := doJuiceShopRequest(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch %s continue code: %w", kind, err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected response status code '%d' from Juice Shop", res.StatusCode)
+	}
+	payload := struct {
+		ContinueCode string `json:"continueCode"`
+	}{}
+	if err := decodeJuiceShopJsonResponse(res, &payload); err != nil {
+		return "", err
+	}
+	return payload.ContinueCode, nil
+}
@@ -0,0 +1,91 @@
+package internal
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/speps/go-hashids/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCodingChallengeContinueCodes(t *testing.T) {
+	challengeIdLookup = map[string]int{
+		"scoreBoardChallenge": 1,
+		"nullByteChallenge":   2,
+	}
+	encode := func(t *testing.T, salt string, ids ...int) string {
+		hd := hashids.NewData()
+		hd.Salt = salt
+		hd.MinLength = continueCodeMinLength
+		hd.Alphabet = continueCodeAlphabet
+		hashIDClient, err := hashids.NewWithData(hd)
+		assert.Nil(t, err)
+		code, err := hashIDClient.Encode(ids)
+		assert.Nil(t, err)
+		return code
+	}
+	respondWithContinueCode := func(t *testing.T, code string) *int {
+		resetJuiceShopClient(t)
+		requests := 0
+		juiceShopHttpClient = &http.Client{Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			requests++
+			assert.Equal(t, "/rest/continue-code-findIt", req.URL.Path)
+			return &http.Response{StatusCode: 200, Body: io.NopCloser(strings.NewReader(`{"continueCode":"` + code + `"}`)), Header: http.Header{}}, nil
+		})}
+		return &requests
+	}
+
+	t.Run("accepts codes decoding to known challenges", func(t *testing.T) {
+		assert.Nil(t, ValidateCodingChallengeContinueCode(FindIt, encode(t, codingChallengeSalts[FindIt], 1, 2)))
+		assert.Nil(t, ValidateCodingChallengeContinueCode(FixIt, encode(t, codingChallengeSalts[FixIt], 2)))
+	})
+
+	t.Run("rejects codes using another salt or containing unknown challenges", func(t *testing.T) {
+		assert.NotNil(t, ValidateCodingChallengeContinueCode(FindIt, encode(t, codingChallengeSalts[FixIt], 1)))
+		assert.NotNil(t, ValidateCodingChallengeContinueCode(FindIt, encode(t, codingChallengeSalts[FindIt], 1, 42)))
+		assert.NotNil(t, ValidateCodingChallengeContinueCode(FindIt, "not-a-continue-code"))
+	})
+
+	t.Run("prefers the code sent with the webhook", func(t *testing.T) {
+		requests := respondWithContinueCode(t, "unused")
+		webhookCode := encode(t, codingChallengeSalts[FindIt], 1)
+
+		code, err := ResolveCodingChallengeContinueCode(context.Background(), "foobar", FindIt, webhookCode)
+		assert.Nil(t, err)
+		assert.Equal(t, webhookCode, code)
+		assert.Equal(t, 0, *requests)
+	})
+
+	t.Run("falls back to fetching the code from the instance if the webhook code is invalid", func(t *testing.T) {
+		fetchedCode := encode(t, codingChallengeSalts[FindIt], 1, 2)
+		requests := respondWithContinueCode(t, fetchedCode)
+
+		code, err := ResolveCodingChallengeContinueCode(context.Background(), "foobar", FindIt, "broken")
+		assert.Nil(t, err)
+		assert.Equal(t, fetchedCode, code)
+		assert.Equal(t, 1, *requests)
+	})
+
+	t.Run("fails if the fetched code is invalid as well", func(t *testing.T) {
+		respondWithContinueCode(t, "also-broken")
+
+		_, err := ResolveCodingChallengeContinueCode(context.Background(), "foobar", FindIt, "broken")
+		assert.NotNil(t, err)
+	})
+}
+
+func TestConfigureCodingChallenges(t *testing.T) {
+	t.Cleanup(func() { codingChallengesEnabled = true })
+
+	assert.Nil(t, ConfigureCodingChallenges(""))
+	assert.True(t, CodingChallengesEnabled(), "Should be enabled by default")
+
+	assert.NotNil(t, ConfigureCodingChallenges("maybe"))
+	assert.True(t, CodingChallengesEnabled())
+
+	assert.Nil(t, ConfigureCodingChallenges("false"))
+	assert.False(t, CodingChallengesEnabled())
+}
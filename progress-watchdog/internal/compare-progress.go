@@ -41,3 +41,23 @@ func CompareChallengeStates(currentSolvedChallenges, lastSolvedChallenges []Chal
 
 	return UpdateCache
 }
+
+// PreserveSolveTimes copies the solve times of the last known progress onto the current one.
+// Re-applying a continue code marks all restored challenges as solved at the time of the restore, so without this the original solve times would be lost on every instance restart.
+func PreserveSolveTimes(currentSolvedChallenges, lastSolvedChallenges []ChallengeStatus) []ChallengeStatus {
+	lastSolvedAt := make(map[string]string, len(lastSolvedChallenges))
+	for _, challenge := range lastSolvedChallenges {
+		if challenge.SolvedAt != "" {
+			lastSolvedAt[challenge.Key] = challenge.SolvedAt
+		}
+	}
+
+	merged := make([]ChallengeStatus, len(currentSolvedChallenges))
+	for i, challenge := range currentSolvedChallenges {
+		if solvedAt, ok := lastSolvedAt[challenge.Key]; ok {
+			challenge.SolvedAt = solvedAt
+		}
+		merged[i] = challenge
+	}
+	return merged
+}
@@ -49,3 +49,39 @@ func TestCompareChallengeStates(t *testing.T) {
 		"Should apply when a challenge is not contained",
 	)
 }
+
+func TestPreserveSolveTimes(t *testing.T) {
+	t.Run("keeps the original solve times of challenges restored after a restart", func(t *testing.T) {
+		lastProgress := []ChallengeStatus{
+			{Key: "scoreBoardChallenge", SolvedAt: "2024-01-01T10:00:00.000Z"},
+			{Key: "loginAdminChallenge", SolvedAt: "2024-01-01T09:00:00.000Z"},
+		}
+		// after the restart juice shop reports the time the continue code got applied
+		restoredProgress := []ChallengeStatus{
+			{Key: "scoreBoardChallenge", SolvedAt: "2024-01-02T12:00:00.000Z"},
+			{Key: "loginAdminChallenge", SolvedAt: "2024-01-02T12:00:00.000Z"},
+		}
+
+		merged := PreserveSolveTimes(restoredProgress, lastProgress)
+
+		assert.Equal(t, lastProgress, merged)
+		assert.Equal(t, NoOp, CompareChallengeStates(merged, lastProgress))
+	})
+
+	t.Run("keeps the solve time of newly solved challenges", func(t *testing.T) {
+		merged := PreserveSolveTimes(
+			[]ChallengeStatus{
+				{Key: "scoreBoardChallenge", SolvedAt: "2024-01-02T12:00:00.000Z"},
+				{Key: "loginAdminChallenge", SolvedAt: "2024-01-02T13:00:00.000Z"},
+			},
+			[]ChallengeStatus{
+				{Key: "scoreBoardChallenge", SolvedAt: "2024-01-01T10:00:00.000Z"},
+			},
+		)
+
+		assert.Equal(t, []ChallengeStatus{
+			{Key: "scoreBoardChallenge", SolvedAt: "2024-01-01T10:00:00.000Z"},
+			{Key: "loginAdminChallenge", SolvedAt: "2024-01-02T13:00:00.000Z"},
+		}, merged)
+	})
+}
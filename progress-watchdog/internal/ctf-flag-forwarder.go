@@ -0,0 +1,95 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// CtfFlagForward is the payload POSTed to the configured CTF platform for every solve carrying a CTF flag
+type CtfFlagForward struct {
+	Team      string `json:"team"`
+	Challenge string `json:"challenge"`
+	CtfFlag   string `json:"ctfFlag"`
+}
+
+var ctfFlagForwardUrl = ""
+var ctfFlagForwardHttpClient = &http.Client{Timeout: 10 * time.Second}
+
+// ctfFlagForwardAttempts is how often forwarding a flag is tried before giving up. The delay between attempts doubles after every failed attempt
+const ctfFlagForwardAttempts = 5
+
+var ctfFlagForwardRetryDelay = 1 * time.Second
+
+// ConfigureCtfFlagForwarding sets the url CTF flags get forwarded to. Forwarding is disabled if the url is empty
+func ConfigureCtfFlagForwarding(forwardUrl string) error {
+	if forwardUrl != "" {
+		parsed, err := url.Parse(forwardUrl)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return fmt.Errorf("CTF_FLAG_FORWARD_URL must be an absolute http or https url, got '%s'", forwardUrl)
+		}
+		logger.Printf("Forwarding CTF flags of solved challenges to '%s'", forwardUrl)
+	}
+	ctfFlagForwardUrl = forwardUrl
+	return nil
+}
+
+// ForwardCtfFlag forwards the flag of a solved challenge to the configured CTF platform in the background. Does nothing if forwarding isn't configured or the solve has no flag
+func ForwardCtfFlag(ctx context.Context, team, challenge, ctfFlag string) {
+	if ctfFlagForwardUrl == "" || ctfFlag == "" {
+		return
+	}
+	// the forward outlives the webhook request it originates from
+	go forwardCtfFlag(context.WithoutCancel(ctx), ctfFlagForwardUrl, CtfFlagForward{Team: team, Challenge: challenge, CtfFlag: ctfFlag})
+}
+
+func forwardCtfFlag(ctx context.Context, forwardUrl string, forward CtfFlagForward) error {
+	body, err := json.Marshal(forward)
+	if err != nil {
+		return err
+	}
+
+	delay := ctfFlagForwardRetryDelay
+	for attempt := 1; ; attempt++ {
+		err = postCtfFlag(ctx, forwardUrl, body)
+		if err == nil {
+			return nil
+		}
+		if attempt == ctfFlagForwardAttempts {
+			logger.Printf("Failed to forward CTF flag of challenge '%s' solved by team '%s', giving up after %d attempts: %s", forward.Challenge, forward.Team, attempt, err)
+			failedCtfFlagForwardsCounter.Add(ctx, 1)
+			return err
+		}
+		logger.Printf("Failed to forward CTF flag of challenge '%s' solved by team '%s', retrying in %s: %s", forward.Challenge, forward.Team, delay, err)
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		delay *= 2
+	}
+}
+
+func postCtfFlag(ctx context.Context, forwardUrl string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, "POST", forwardUrl, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	injectTraceContext(ctx, req)
+
+	res, err := ctfFlagForwardHttpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return fmt.Errorf("unexpected response status code '%d'", res.StatusCode)
+	}
+	return nil
+}
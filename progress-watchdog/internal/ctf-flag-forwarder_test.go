@@ -0,0 +1,79 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigureCtfFlagForwarding(t *testing.T) {
+	t.Cleanup(func() { ctfFlagForwardUrl = "" })
+
+	assert.Nil(t, ConfigureCtfFlagForwarding(""))
+	assert.Equal(t, "", ctfFlagForwardUrl)
+
+	assert.Nil(t, ConfigureCtfFlagForwarding("https://ctf.example.com/api/solves"))
+	assert.Equal(t, "https://ctf.example.com/api/solves", ctfFlagForwardUrl)
+
+	assert.NotNil(t, ConfigureCtfFlagForwarding("ctf.example.com/api/solves"), "Should reject urls without scheme")
+	assert.NotNil(t, ConfigureCtfFlagForwarding("ftp://ctf.example.com"), "Should reject non http urls")
+}
+
+func TestForwardCtfFlag(t *testing.T) {
+	originalRetryDelay := ctfFlagForwardRetryDelay
+	ctfFlagForwardRetryDelay = time.Millisecond
+	t.Cleanup(func() { ctfFlagForwardRetryDelay = originalRetryDelay })
+
+	t.Run("posts the flag to the configured url", func(t *testing.T) {
+		var received CtfFlagForward
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "POST", r.Method)
+			assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+			json.NewDecoder(r.Body).Decode(&received)
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		err := forwardCtfFlag(context.Background(), server.URL, CtfFlagForward{Team: "foobar", Challenge: "scoreBoardChallenge", CtfFlag: "flag-123"})
+
+		assert.Nil(t, err)
+		assert.Equal(t, CtfFlagForward{Team: "foobar", Challenge: "scoreBoardChallenge", CtfFlag: "flag-123"}, received)
+	})
+
+	t.Run("retries failed forwards", func(t *testing.T) {
+		var requests atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if requests.Add(1) < 3 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.WriteHeader(http.StatusCreated)
+		}))
+		defer server.Close()
+
+		err := forwardCtfFlag(context.Background(), server.URL, CtfFlagForward{Team: "foobar", Challenge: "scoreBoardChallenge", CtfFlag: "flag-123"})
+
+		assert.Nil(t, err)
+		assert.Equal(t, int32(3), requests.Load())
+	})
+
+	t.Run("gives up after the max number of attempts", func(t *testing.T) {
+		var requests atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests.Add(1)
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer server.Close()
+
+		err := forwardCtfFlag(context.Background(), server.URL, CtfFlagForward{Team: "foobar", Challenge: "scoreBoardChallenge", CtfFlag: "flag-123"})
+
+		assert.ErrorContains(t, err, "unexpected response status code '500'")
+		assert.Equal(t, int32(ctfFlagForwardAttempts), requests.Load())
+	})
+}
@@ -0,0 +1,165 @@
+package internal
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+)
+
+// JuiceShopClientConfig configures how the ProgressWatchdog connects to the JuiceShop instances.
+// Defaults to plain http on port 3000, which is what the JuiceShop services created by the balancer expose
+type JuiceShopClientConfig struct {
+	Scheme string
+	Port   int
+	// CAFile is an optional pem file with the CAs used to verify the JuiceShop certificates. Uses the system CAs if it's empty
+	CAFile string
+	// CertFile and KeyFile are an optional client certificate, for setups requiring mTLS
+	CertFile string
+	KeyFile  string
+}
+
+var juiceShopClientConfig = JuiceShopClientConfig{Scheme: "http", Port: 3000}
+var juiceShopHttpClient = http.DefaultClient
+
+// juiceShopRequestSlots bounds the number of concurrent requests to all JuiceShop instances. Sending blocks while all slots are taken
+var juiceShopRequestSlots = make(chan struct{}, 20)
+
+// juiceShopRequestsInFlight is the number of requests to JuiceShop instances currently holding a slot
+var juiceShopRequestsInFlight atomic.Int64
+
+// JuiceShopClientConfigFromEnv reads the JUICE_SHOP_* env vars, falling back to the defaults for unset ones
+func JuiceShopClientConfigFromEnv() (JuiceShopClientConfig, error) {
+	config := JuiceShopClientConfig{
+		Scheme:   "http",
+		Port:     3000,
+		CAFile:   os.Getenv("JUICE_SHOP_TLS_CA_FILE"),
+		CertFile: os.Getenv("JUICE_SHOP_TLS_CERT_FILE"),
+		KeyFile:  os.Getenv("JUICE_SHOP_TLS_KEY_FILE"),
+	}
+	if scheme := os.Getenv("JUICE_SHOP_SCHEME"); scheme != "" {
+		config.Scheme = scheme
+	}
+	if portString := os.Getenv("JUICE_SHOP_PORT"); portString != "" {
+		port, err := strconv.Atoi(portString)
+		if err != nil || port <= 0 || port > 65535 {
+			return config, fmt.Errorf("JUICE_SHOP_PORT must be a valid port, got '%s'", portString)
+		}
+		config.Port = port
+	}
+	return config, nil
+}
+
+// InitJuiceShopClient sets up the http client used for all requests to the JuiceShop instances
+func InitJuiceShopClient(config JuiceShopClientConfig) error {
+	if config.Scheme != "http" && config.Scheme != "https" {
+		return fmt.Errorf("JuiceShop scheme must be either 'http' or 'https', got '%s'", config.Scheme)
+	}
+
+	client := http.DefaultClient
+	if config.Scheme == "https" {
+		tlsConfig, err := newJuiceShopTLSConfig(config)
+		if err != nil {
+			return err
+		}
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = tlsConfig
+		client = &http.Client{Transport: transport}
+	} else if config.CAFile != "" || config.CertFile != "" {
+		logger.Printf("Warning: TLS files are configured for the JuiceShop client, but they are ignored as the scheme is '%s'", config.Scheme)
+	}
+
+	juiceShopClientConfig = config
+	juiceShopHttpClient = client
+	logger.Printf("Connecting to JuiceShop instances via %s on port %d", config.Scheme, config.Port)
+	return nil
+}
+
+func newJuiceShopTLSConfig(config JuiceShopClientConfig) (*tls.Config, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if config.CAFile != "" {
+		caBytes, err := os.ReadFile(config.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read JuiceShop CA file: %w", err)
+		}
+		certPool := x509.NewCertPool()
+		if !certPool.AppendCertsFromPEM(caBytes) {
+			return nil, errors.New("JuiceShop CA file doesn't contain any valid pem certificates")
+		}
+		tlsConfig.RootCAs = certPool
+	}
+
+	if (config.CertFile == "") != (config.KeyFile == "") {
+		return nil, errors.New("JuiceShop client certificate and key have to be configured together")
+	}
+	if config.CertFile != "" {
+		certificate, err := tls.LoadX509KeyPair(config.CertFile, config.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load JuiceShop client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{certificate}
+	}
+
+	return tlsConfig, nil
+}
+
+// ConfigureJuiceShopMaxConcurrentRequests overrides how many requests to JuiceShop instances can be in flight at once, across all sync workers and webhooks. An empty value keeps the default
+func ConfigureJuiceShopMaxConcurrentRequests(maxRequests string) error {
+	if maxRequests == "" {
+		return nil
+	}
+	parsedMaxRequests, err := strconv.Atoi(maxRequests)
+	if err != nil || parsedMaxRequests < 1 {
+		return fmt.Errorf("JUICE_SHOP_MAX_CONCURRENT_REQUESTS must be a positive integer, got '%s'", maxRequests)
+	}
+	juiceShopRequestSlots = make(chan struct{}, parsedMaxRequests)
+	return nil
+}
+
+// doJuiceShopRequest sends the request once a request slot is free. The slot is held until the response body is closed, as the connection stays in use until then
+func doJuiceShopRequest(req *http.Request) (*http.Response, error) {
+	slots := juiceShopRequestSlots
+	select {
+	case slots <- struct{}{}:
+	case <-req.Context().Done():
+		return nil, req.Context().Err()
+	}
+	juiceShopRequestsInFlight.Add(1)
+	var releaseOnce sync.Once
+	release := func() {
+		releaseOnce.Do(func() {
+			juiceShopRequestsInFlight.Add(-1)
+			<-slots
+		})
+	}
+
+	res, err := juiceShopHttpClient.Do(req)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	res.Body = &releasingBody{ReadCloser: res.Body, release: release}
+	return res, nil
+}
+
+type releasingBody struct {
+	io.ReadCloser
+	release func()
+}
+
+func (b *releasingBody) Close() error {
+	defer b.release()
+	return b.ReadCloser.Close()
+}
+
+// juiceShopUrl returns the url of the path on the JuiceShop instance of the team
+func juiceShopUrl(team string, path string) string {
+	return fmt.Sprintf("%s://juiceshop-%s:%d%s", juiceShopClientConfig.Scheme, team, juiceShopClientConfig.Port, path)
+}
@@ -0,0 +1,123 @@
+package internal
+
+import (
+	"context"
+	"encoding/pem"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func resetJuiceShopClient(t *testing.T) {
+	t.Cleanup(func() {
+		juiceShopClientConfig = JuiceShopClientConfig{Scheme: "http", Port: 3000}
+		juiceShopHttpClient = http.DefaultClient
+		juiceShopRequestSlots = make(chan struct{}, 20)
+	})
+}
+
+func TestJuiceShopClientConfigFromEnv(t *testing.T) {
+	config, err := JuiceShopClientConfigFromEnv()
+	assert.Nil(t, err)
+	assert.Equal(t, JuiceShopClientConfig{Scheme: "http", Port: 3000}, config, "Should default to plain http on port 3000")
+
+	t.Setenv("JUICE_SHOP_SCHEME", "https")
+	t.Setenv("JUICE_SHOP_PORT", "3443")
+	t.Setenv("JUICE_SHOP_TLS_CA_FILE", "/certs/ca.pem")
+	config, err = JuiceShopClientConfigFromEnv()
+	assert.Nil(t, err)
+	assert.Equal(t, JuiceShopClientConfig{Scheme: "https", Port: 3443, CAFile: "/certs/ca.pem"}, config)
+
+	t.Setenv("JUICE_SHOP_PORT", "not-a-port")
+	_, err = JuiceShopClientConfigFromEnv()
+	assert.NotNil(t, err)
+}
+
+func TestInitJuiceShopClient(t *testing.T) {
+	t.Run("uses plain http by default", func(t *testing.T) {
+		resetJuiceShopClient(t)
+
+		err := InitJuiceShopClient(JuiceShopClientConfig{Scheme: "http", Port: 3000})
+		assert.Nil(t, err)
+		assert.Equal(t, "http://juiceshop-foobar:3000/api/challenges", juiceShopUrl("foobar", "/api/challenges"))
+	})
+
+	t.Run("rejects unknown schemes", func(t *testing.T) {
+		resetJuiceShopClient(t)
+
+		err := InitJuiceShopClient(JuiceShopClientConfig{Scheme: "ftp", Port: 3000})
+		assert.NotNil(t, err)
+	})
+
+	t.Run("requires client certificate and key to be configured together", func(t *testing.T) {
+		resetJuiceShopClient(t)
+
+		err := InitJuiceShopClient(JuiceShopClientConfig{Scheme: "https", Port: 3000, CertFile: "/certs/tls.crt"})
+		assert.NotNil(t, err)
+	})
+
+	t.Run("trusts the configured CA", func(t *testing.T) {
+		resetJuiceShopClient(t)
+
+		server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		caFile := filepath.Join(t.TempDir(), "ca.pem")
+		caPem := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+		assert.Nil(t, os.WriteFile(caFile, caPem, 0600))
+
+		err := InitJuiceShopClient(JuiceShopClientConfig{Scheme: "https", Port: 3000, CAFile: caFile})
+		assert.Nil(t, err)
+		assert.Equal(t, "https://juiceshop-foobar:3000/api/challenges", juiceShopUrl("foobar", "/api/challenges"))
+
+		res, err := juiceShopHttpClient.Get(server.URL)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		res.Body.Close()
+	})
+}
+
+func TestJuiceShopRequestConcurrencyLimit(t *testing.T) {
+	t.Run("rejects invalid limits", func(t *testing.T) {
+		resetJuiceShopClient(t)
+		assert.NotNil(t, ConfigureJuiceShopMaxConcurrentRequests("0"))
+		assert.NotNil(t, ConfigureJuiceShopMaxConcurrentRequests("many"))
+		assert.Nil(t, ConfigureJuiceShopMaxConcurrentRequests(""))
+		assert.Equal(t, 20, cap(juiceShopRequestSlots))
+	})
+
+	t.Run("blocks requests until a response body got closed", func(t *testing.T) {
+		resetJuiceShopClient(t)
+		assert.Nil(t, ConfigureJuiceShopMaxConcurrentRequests("1"))
+		juiceShopHttpClient = &http.Client{Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			return &http.Response{StatusCode: 200, Body: io.NopCloser(strings.NewReader("{}")), Header: http.Header{}}, nil
+		})}
+
+		req, _ := http.NewRequest("GET", juiceShopUrl("foobar", "/api/challenges"), nil)
+		res, err := doJuiceShopRequest(req)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(1), juiceShopRequestsInFlight.Load())
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		_, err = doJuiceShopRequest(req.WithContext(ctx))
+		assert.ErrorIs(t, err, context.DeadlineExceeded, "Should wait for the free slot until the context expires")
+
+		res.Body.Close()
+		res.Body.Close()
+		assert.Equal(t, int64(0), juiceShopRequestsInFlight.Load(), "Closing the body twice should only release the slot once")
+
+		res, err = doJuiceShopRequest(req)
+		assert.Nil(t, err)
+		res.Body.Close()
+	})
+}
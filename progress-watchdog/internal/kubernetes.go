@@ -6,7 +6,10 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes"
@@ -36,11 +39,40 @@ type UpdateProgressDeploymentMetadata struct {
 type UpdateProgressDeploymentDiffAnnotations struct {
 	Challenges       string `json:"multi-juicer.owasp-juice.shop/challenges"`
 	ChallengesSolved string `json:"multi-juicer.owasp-juice.shop/challengesSolved"`
+	// LastRestoredAt is only patched when the saved progress got re-applied to the JuiceShop, so that the balancer can tell the team that its progress survived an instance restart
+	LastRestoredAt string `json:"multi-juicer.owasp-juice.shop/lastRestoredAt,omitempty"`
 }
 
-func PersistProgress(clientset *kubernetes.Clientset, team string, solvedChallenges []ChallengeStatus) {
+func PersistProgress(ctx context.Context, clientset *kubernetes.Clientset, team string, solvedChallenges []ChallengeStatus) {
+	persistProgress(ctx, clientset, team, solvedChallenges, "")
+}
+
+// PersistRestoredProgress persists the progress after it got re-applied to the JuiceShop, together with the time it got restored at
+func PersistRestoredProgress(ctx context.Context, clientset *kubernetes.Clientset, team string, solvedChallenges []ChallengeStatus, restoredAt time.Time) {
+	persistProgress(ctx, clientset, team, solvedChallenges, restoredAt.UTC().Format(time.RFC3339))
+}
+
+func persistProgress(ctx context.Context, clientset *kubernetes.Clientset, team string, solvedChallenges []ChallengeStatus, lastRestoredAt string) {
+	ctx, span := Tracer.Start(ctx, "persist.progress", trace.WithAttributes(
+		attribute.String("team", team),
+		attribute.Int("challenges.solved", len(solvedChallenges)),
+	))
+	defer span.End()
+
 	logger.Printf("Updating saved ContinueCode of team '%s'", team)
 
+	jsonBytes := createProgressPatch(solvedChallenges, lastRestoredAt)
+
+	namespace := os.Getenv("NAMESPACE")
+	_, err := clientset.AppsV1().Deployments(namespace).Patch(ctx, fmt.Sprintf("juiceshop-%s", team), types.MergePatchType, jsonBytes, v1.PatchOptions{})
+	if err != nil {
+		RecordSpanError(span, err)
+		logger.Println(fmt.Errorf("failed to patch new ContinueCode into deployment for team %s: %w", team, err))
+	}
+}
+
+// createProgressPatch creates the merge patch for the progress annotations. The lastRestoredAt annotation is left untouched if lastRestoredAt is empty
+func createProgressPatch(solvedChallenges []ChallengeStatus, lastRestoredAt string) []byte {
 	encodedSolvedChallenges, err := json.Marshal(solvedChallenges)
 	if err != nil {
 		panic("Could not encode json, to update ContinueCode and challengeSolved count on deployment")
@@ -51,6 +83,7 @@ func PersistProgress(clientset *kubernetes.Clientset, team string, solvedChallen
 			Annotations: UpdateProgressDeploymentDiffAnnotations{
 				Challenges:       string(encodedSolvedChallenges),
 				ChallengesSolved: fmt.Sprintf("%d", len(solvedChallenges)),
+				LastRestoredAt:   lastRestoredAt,
 			},
 		},
 	}
@@ -59,10 +92,91 @@ func PersistProgress(clientset *kubernetes.Clientset, team string, solvedChallen
 	if err != nil {
 		panic("Could not encode json, to update ContinueCode and challengeSolved count on deployment")
 	}
+	return jsonBytes
+}
+
+// CheckKubernetesApi lists a single JuiceShop deployment, which fails if the kubernetes api is unreachable or the RBAC role of the watchdog doesn't allow listing deployments
+func CheckKubernetesApi(ctx context.Context, clientset *kubernetes.Clientset) error {
+	_, err := clientset.AppsV1().Deployments(os.Getenv("NAMESPACE")).List(ctx, v1.ListOptions{
+		LabelSelector: juiceShopInstanceLabelSelector,
+		Limit:         1,
+	})
+	return err
+}
+
+// WebhookLogEntry records a single solution webhook received for a team, to be able to investigate disputed solves later on
+type WebhookLogEntry struct {
+	Challenge  string `json:"challenge"`
+	IssuedOn   string `json:"issuedOn"`
+	ReceivedAt string `json:"receivedAt"`
+	Source     string `json:"source"`
+}
+
+// maxWebhookLogEntries bounds the webhook log, as annotations are limited in size. The oldest entries get dropped first
+const maxWebhookLogEntries = 100
+
+// AppendWebhookLogEntries adds the entries to the webhook log, dropping the oldest entries once the log exceeds maxWebhookLogEntries
+func AppendWebhookLogEntries(existingLogJson string, entries []WebhookLogEntry) []WebhookLogEntry {
+	webhookLog := []WebhookLogEntry{}
+	if existingLogJson != "" {
+		if err := json.Unmarshal([]byte(existingLogJson), &webhookLog); err != nil {
+			logger.Println(fmt.Errorf("failed to decode existing webhook log, starting a new one: %w", err))
+			webhookLog = []WebhookLogEntry{}
+		}
+	}
+	webhookLog = append(webhookLog, entries...)
+	if len(webhookLog) > maxWebhookLogEntries {
+		webhookLog = webhookLog[len(webhookLog)-maxWebhookLogEntries:]
+	}
+	return webhookLog
+}
+
+// EncodeWebhookLog encodes the webhook log for the webhookLog annotation, where the balancer can read it
+func EncodeWebhookLog(webhookLog []WebhookLogEntry) (string, error) {
+	webhookLogJson, err := json.Marshal(webhookLog)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode webhook log: %w", err)
+	}
+	return string(webhookLogJson), nil
+}
+
+// ProgressAnnotations are the annotations saving the solved challenges of a team
+func ProgressAnnotations(solvedChallenges []ChallengeStatus) map[string]string {
+	encodedSolvedChallenges, err := json.Marshal(solvedChallenges)
+	if err != nil {
+		panic("Could not encode json, to update ContinueCode and challengeSolved count on deployment")
+	}
+	return map[string]string{
+		"multi-juicer.owasp-juice.shop/challenges":       string(encodedSolvedChallenges),
+		"multi-juicer.owasp-juice.shop/challengesSolved": fmt.Sprintf("%d", len(solvedChallenges)),
+	}
+}
+
+// PersistAnnotations writes the annotations to the deployment of the team with a single merge patch, so that a webhook causes at most one write. Does nothing if there are no annotations to write
+func PersistAnnotations(ctx context.Context, clientset *kubernetes.Clientset, team string, annotations map[string]string) {
+	if len(annotations) == 0 {
+		return
+	}
+	ctx, span := Tracer.Start(ctx, "persist.progress", trace.WithAttributes(
+		attribute.String("team", team),
+		attribute.Int("annotations", len(annotations)),
+	))
+	defer span.End()
+
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": annotations,
+		},
+	})
+	if err != nil {
+		logger.Println(fmt.Errorf("failed to encode annotation patch of team '%s': %w", team, err))
+		return
+	}
 
 	namespace := os.Getenv("NAMESPACE")
-	_, err = clientset.AppsV1().Deployments(namespace).Patch(context.TODO(), fmt.Sprintf("juiceshop-%s", team), types.MergePatchType, jsonBytes, v1.PatchOptions{})
+	_, err = clientset.AppsV1().Deployments(namespace).Patch(ctx, fmt.Sprintf("juiceshop-%s", team), types.MergePatchType, patch, v1.PatchOptions{})
 	if err != nil {
-		logger.Println(fmt.Errorf("failed to patch new ContinueCode into deployment for team %s: %w", team, err))
+		RecordSpanError(span, err)
+		logger.Println(fmt.Errorf("failed to patch annotations into deployment for team %s: %w", team, err))
 	}
 }
@@ -0,0 +1,65 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAppendWebhookLogEntries(t *testing.T) {
+	entry := WebhookLogEntry{Challenge: "scoreBoardChallenge", IssuedOn: "2024-11-01T19:55:48.211Z", ReceivedAt: "2024-11-01T19:55:49Z", Source: "10.0.0.1:41234"}
+
+	assert.Equal(t, []WebhookLogEntry{entry}, AppendWebhookLogEntries("", []WebhookLogEntry{entry}), "Should start a new log")
+	assert.Equal(t, []WebhookLogEntry{entry}, AppendWebhookLogEntries("not json", []WebhookLogEntry{entry}), "Should start a new log if the existing one is invalid")
+
+	existingLog := `[{"challenge":"nullByteChallenge","issuedOn":"2024-11-01T19:50:00.000Z","receivedAt":"2024-11-01T19:50:01Z","source":"10.0.0.1:41234"}]`
+	webhookLog := AppendWebhookLogEntries(existingLog, []WebhookLogEntry{entry})
+	assert.Len(t, webhookLog, 2)
+	assert.Equal(t, "nullByteChallenge", webhookLog[0].Challenge)
+	assert.Equal(t, entry, webhookLog[1])
+
+	entries := []WebhookLogEntry{}
+	for i := 0; i < maxWebhookLogEntries+5; i++ {
+		entries = append(entries, WebhookLogEntry{Challenge: fmt.Sprintf("challenge-%d", i)})
+	}
+	webhookLog = AppendWebhookLogEntries("", entries)
+	assert.Len(t, webhookLog, maxWebhookLogEntries)
+	assert.Equal(t, "challenge-5", webhookLog[0].Challenge, "Should drop the oldest entries")
+}
+
+func TestCreateProgressPatch(t *testing.T) {
+	challenges := []ChallengeStatus{{Key: "scoreBoardChallenge", SolvedAt: "2024-11-01T19:55:48.211Z"}}
+
+	assert.JSONEq(t,
+		`{"metadata":{"annotations":{"multi-juicer.owasp-juice.shop/challenges":"[{\"key\":\"scoreBoardChallenge\",\"solvedAt\":\"2024-11-01T19:55:48.211Z\"}]","multi-juicer.owasp-juice.shop/challengesSolved":"1"}}}`,
+		string(createProgressPatch(challenges, "")),
+		"Should leave the lastRestoredAt annotation untouched for regular updates",
+	)
+	assert.JSONEq(t,
+		`{"metadata":{"annotations":{"multi-juicer.owasp-juice.shop/challenges":"[{\"key\":\"scoreBoardChallenge\",\"solvedAt\":\"2024-11-01T19:55:48.211Z\"}]","multi-juicer.owasp-juice.shop/challengesSolved":"1","multi-juicer.owasp-juice.shop/lastRestoredAt":"2024-11-01T20:00:00Z"}}}`,
+		string(createProgressPatch(challenges, "2024-11-01T20:00:00Z")),
+	)
+}
+
+func TestProgressAnnotations(t *testing.T) {
+	annotations := ProgressAnnotations([]ChallengeStatus{{Key: "scoreBoardChallenge", SolvedAt: "2024-11-01T19:55:48.211Z"}})
+	assert.Equal(t, map[string]string{
+		"multi-juicer.owasp-juice.shop/challenges":       `[{"key":"scoreBoardChallenge","solvedAt":"2024-11-01T19:55:48.211Z"}]`,
+		"multi-juicer.owasp-juice.shop/challengesSolved": "1",
+	}, annotations)
+}
+
+func TestEncodeWebhookLog(t *testing.T) {
+	webhookLogJson, err := EncodeWebhookLog([]WebhookLogEntry{{Challenge: "scoreBoardChallenge", IssuedOn: "2024-11-01T19:55:48.211Z", ReceivedAt: "2024-11-01T19:55:49Z", Source: "10.0.0.1:41234"}})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `[{"challenge":"scoreBoardChallenge","issuedOn":"2024-11-01T19:55:48.211Z","receivedAt":"2024-11-01T19:55:49Z","source":"10.0.0.1:41234"}]`, webhookLogJson)
+}
+
+func TestPersistAnnotationsSkipsEmptyUpdates(t *testing.T) {
+	// would panic on the nil clientset if it tried to write anything
+	assert.NotPanics(t, func() {
+		PersistAnnotations(context.Background(), nil, "foobar", map[string]string{})
+	})
+}
@@ -0,0 +1,132 @@
+package internal
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/metric"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+var meter = otel.Meter("github.com/juice-shop/multi-juicer/progress-watchdog")
+
+// staleProgressCounter counts saved challenges which can't be restored anymore, as they aren't part of the current challenges.json
+var staleProgressCounter, _ = meter.Int64Counter(
+	"progress_watchdog.stale_challenges",
+	metric.WithDescription("Number of saved challenge solves which are unknown to the current challenges.json and would get lost when re-applying the progress"),
+)
+
+// continueCodeRoundtripMismatchCounter counts re-applied ContinueCodes after which the JuiceShop didn't report all intended challenges as solved
+var continueCodeRoundtripMismatchCounter = prometheus.NewCounter(
+	prometheus.CounterOpts{
+		Name: "continue_code_roundtrip_mismatch_total",
+		Help: "Number of times re-applying a ContinueCode didn't restore all saved challenges. Indicates a salt, alphabet or version mismatch with the JuiceShop",
+	},
+)
+
+// pendingSyncJobs is the number of sync jobs of the current background-sync round which haven't been picked up by a worker yet
+var pendingSyncJobs atomic.Int64
+
+var _, _ = meter.Int64ObservableGauge(
+	"progress_watchdog.sync_queue_depth",
+	metric.WithDescription("Number of instances of the current background-sync round still waiting for a free sync worker"),
+	metric.WithInt64Callback(func(_ context.Context, observer metric.Int64Observer) error {
+		observer.Observe(pendingSyncJobs.Load())
+		return nil
+	}),
+)
+
+// delayedSyncJobsCounter counts sync jobs which had to wait longer than syncJobDelayThreshold for a free worker
+var delayedSyncJobsCounter, _ = meter.Int64Counter(
+	"progress_watchdog.sync_jobs_delayed",
+	metric.WithDescription("Number of sync jobs which had to wait for a free sync worker for longer than the delay threshold. Increase SYNC_WORKER_COUNT if this keeps growing"),
+)
+
+var _, _ = meter.Int64ObservableGauge(
+	"progress_watchdog.juice_shop_requests_in_flight",
+	metric.WithDescription("Number of requests to JuiceShop instances currently in flight. Bounded by JUICE_SHOP_MAX_CONCURRENT_REQUESTS"),
+	metric.WithInt64Callback(func(_ context.Context, observer metric.Int64Observer) error {
+		observer.Observe(juiceShopRequestsInFlight.Load())
+		return nil
+	}),
+)
+
+// failedCtfFlagForwardsCounter counts CTF flags which couldn't be forwarded to the CTF platform, even after retrying
+var failedCtfFlagForwardsCounter, _ = meter.Int64Counter(
+	"progress_watchdog.ctf_flag_forwards_failed",
+	metric.WithDescription("Number of CTF flags which couldn't be forwarded to the configured CTF platform after all retries"),
+)
+
+// webhookDuration measures the full processing of a webhook, including reading and patching the deployment and fetching coding challenge continue codes.
+// Served via Prometheus on the metrics port like the balancer metrics, so that it can be scraped without setting up an OTLP collector
+var webhookDuration = prometheus.NewHistogramVec(
+	prometheus.HistogramOpts{
+		Name:    "progress_watchdog_webhook_duration_seconds",
+		Help:    "Time it took to process a solution webhook, by webhook kind (regular, findIt, fixIt)",
+		Buckets: prometheus.DefBuckets,
+	},
+	[]string{"kind"},
+)
+
+// webhookErrorResponsesCounter counts webhooks answered with a 4xx or 5xx status code
+var webhookErrorResponsesCounter = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "progress_watchdog_webhook_error_responses_total",
+		Help: "Number of solution webhooks answered with a 4xx or 5xx status code, by webhook kind and status code",
+	},
+	[]string{"kind", "status_code"},
+)
+
+func init() {
+	prometheus.MustRegister(webhookDuration)
+	prometheus.MustRegister(webhookErrorResponsesCounter)
+	prometheus.MustRegister(continueCodeRoundtripMismatchCounter)
+}
+
+// RecordWebhookProcessed records the duration and, for failed webhooks, the error status of a processed webhook
+func RecordWebhookProcessed(kind string, statusCode int, duration time.Duration) {
+	webhookDuration.WithLabelValues(kind).Observe(duration.Seconds())
+	if statusCode >= 400 {
+		webhookErrorResponsesCounter.WithLabelValues(kind, strconv.Itoa(statusCode)).Inc()
+	}
+}
+
+// InitMetrics sets up exporting metrics via OTLP if an OTLP endpoint is configured via the standard OTEL_EXPORTER_OTLP_* env vars.
+// The returned shutdown func flushes the remaining metrics and should be called before the process exits
+func InitMetrics(ctx context.Context) (func(context.Context) error, error) {
+	if os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") == "" && os.Getenv("OTEL_EXPORTER_OTLP_METRICS_ENDPOINT") == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporter, err := otlpmetrichttp.New(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := resource.New(ctx,
+		resource.WithAttributes(semconv.ServiceName("progress-watchdog")),
+		resource.WithFromEnv(),
+		resource.WithTelemetrySDK(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	meterProvider := sdkmetric.NewMeterProvider(
+		sdkmetric.WithReader(sdkmetric.NewPeriodicReader(exporter)),
+		sdkmetric.WithResource(res),
+	)
+	otel.SetMeterProvider(meterProvider)
+
+	logger.Println("Exporting metrics via OTLP")
+	return meterProvider.Shutdown, nil
+}
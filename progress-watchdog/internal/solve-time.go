@@ -0,0 +1,38 @@
+package internal
+
+import (
+	"time"
+)
+
+// solveTimeLayouts are the timestamp formats JuiceShop instances are known to send, depending on their version and config. Layouts without an offset are assumed to be in UTC
+var solveTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999 -07:00",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999",
+}
+
+// minPlausibleSolveTime rejects zero values and timestamps of instances with a broken clock
+var minPlausibleSolveTime = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// maxSolveTimeClockSkew is how far a solve time can be in the future, to tolerate slightly diverging clocks between the JuiceShop and the watchdog
+const maxSolveTimeClockSkew = 5 * time.Minute
+
+// NormalizeSolveTime converts a solve time sent by a JuiceShop into UTC, so that solve times of instances with different timezone configs stay comparable.
+// Falls back to now for timestamps which can't be parsed or are implausible, i.e. before the year 2000 or in the future
+func NormalizeSolveTime(solvedAt string, now time.Time) string {
+	for _, layout := range solveTimeLayouts {
+		parsed, err := time.Parse(layout, solvedAt)
+		if err != nil {
+			continue
+		}
+		if parsed.Before(minPlausibleSolveTime) || parsed.After(now.Add(maxSolveTimeClockSkew)) {
+			logger.Printf("Received implausible solve time '%s', using the current time instead", solvedAt)
+			return now.UTC().Format(time.RFC3339Nano)
+		}
+		return parsed.UTC().Format(time.RFC3339Nano)
+	}
+	logger.Printf("Received solve time '%s' in an unknown format, using the current time instead", solvedAt)
+	return now.UTC().Format(time.RFC3339Nano)
+}
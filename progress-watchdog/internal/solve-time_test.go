@@ -0,0 +1,46 @@
+package internal
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeSolveTime(t *testing.T) {
+	now := time.Date(2024, 10, 18, 14, 0, 0, 0, time.UTC)
+
+	t.Run("keeps utc timestamps", func(t *testing.T) {
+		assert.Equal(t, "2024-10-18T13:55:18.081Z", NormalizeSolveTime("2024-10-18T13:55:18.081Z", now))
+	})
+
+	t.Run("converts timestamps with an offset to utc", func(t *testing.T) {
+		assert.Equal(t, "2024-10-18T13:55:18.081Z", NormalizeSolveTime("2024-10-18T15:55:18.081+02:00", now))
+		assert.Equal(t, "2024-10-18T13:55:18Z", NormalizeSolveTime("2024-10-18T08:55:18-05:00", now))
+		assert.Equal(t, "2024-10-18T13:55:18.081Z", NormalizeSolveTime("2024-10-18 15:55:18.081 +02:00", now))
+	})
+
+	t.Run("makes mixed timezone inputs comparable", func(t *testing.T) {
+		earlier := NormalizeSolveTime("2024-10-18T15:50:00+02:00", now)
+		later := NormalizeSolveTime("2024-10-18T13:55:00Z", now)
+		earlierTime, _ := time.Parse(time.RFC3339Nano, earlier)
+		laterTime, _ := time.Parse(time.RFC3339Nano, later)
+		assert.True(t, earlierTime.Before(laterTime))
+	})
+
+	t.Run("assumes utc for timestamps without an offset", func(t *testing.T) {
+		assert.Equal(t, "2024-10-18T13:55:18.081Z", NormalizeSolveTime("2024-10-18T13:55:18.081", now))
+		assert.Equal(t, "2024-10-18T13:55:18Z", NormalizeSolveTime("2024-10-18 13:55:18", now))
+	})
+
+	t.Run("falls back to now for implausible timestamps", func(t *testing.T) {
+		assert.Equal(t, "2024-10-18T14:00:00Z", NormalizeSolveTime("1970-01-01T00:00:00Z", now))
+		assert.Equal(t, "2024-10-18T14:00:00Z", NormalizeSolveTime("2024-10-18T15:00:00Z", now))
+		assert.Equal(t, "2024-10-18T14:03:00Z", NormalizeSolveTime("2024-10-18T14:03:00Z", now), "Should tolerate small clock skew")
+	})
+
+	t.Run("falls back to now for unparseable timestamps", func(t *testing.T) {
+		assert.Equal(t, "2024-10-18T14:00:00Z", NormalizeSolveTime("", now))
+		assert.Equal(t, "2024-10-18T14:00:00Z", NormalizeSolveTime("yesterday", now))
+	})
+}
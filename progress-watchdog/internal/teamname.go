@@ -0,0 +1,23 @@
+package internal
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// validTeamNamePattern matches the team names the balancer accepts when creating teams. Keep in sync with the balancers join route
+var validTeamNamePattern = regexp.MustCompile("^[a-z0-9]([-a-z0-9])+[a-z0-9]$")
+
+const maxTeamNameLength = 16
+
+// ValidateTeamName checks that the team name could have been created by the balancer.
+// Team names end up in deployment names and urls, so anything else gets rejected before it is used for lookups
+func ValidateTeamName(team string) error {
+	if len(team) > maxTeamNameLength {
+		return fmt.Errorf("team name must not be longer than %d characters", maxTeamNameLength)
+	}
+	if !validTeamNamePattern.MatchString(team) {
+		return fmt.Errorf("team name must consist of lowercase letters, numbers and dashes, starting and ending with a letter or number")
+	}
+	return nil
+}
@@ -0,0 +1,18 @@
+package internal
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateTeamName(t *testing.T) {
+	for _, team := range []string{"foobar", "team-1", "abc", strings.Repeat("a", 16)} {
+		assert.Nil(t, ValidateTeamName(team), team)
+	}
+
+	for _, team := range []string{"", "ab", "FooBar", "-foobar", "foobar-", "foo.bar", "foo/../bar", "foo:3000", "foo%2Fbar", strings.Repeat("a", 17)} {
+		assert.NotNil(t, ValidateTeamName(team), team)
+	}
+}
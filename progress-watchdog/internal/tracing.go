@@ -0,0 +1,65 @@
+package internal
+
+import (
+	"context"
+	"net/http"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Tracer is used for all spans created by the progress-watchdog. Spans are only exported if tracing got enabled via InitTracing
+var Tracer = otel.Tracer("github.com/juice-shop/multi-juicer/progress-watchdog")
+
+// InitTracing sets up exporting traces via OTLP if an OTLP endpoint is configured via the standard OTEL_EXPORTER_OTLP_* env vars.
+// The returned shutdown func flushes the remaining spans and should be called before the process exits
+func InitTracing(ctx context.Context) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	if os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") == "" && os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") == "" {
+		logger.Println("No OTLP endpoint configured, tracing is disabled")
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporter, err := otlptracehttp.New(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := resource.New(ctx,
+		resource.WithAttributes(semconv.ServiceName("progress-watchdog")),
+		// allows overriding the service name and adding attributes via OTEL_SERVICE_NAME and OTEL_RESOURCE_ATTRIBUTES
+		resource.WithFromEnv(),
+		resource.WithTelemetrySDK(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	tracerProvider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(tracerProvider)
+
+	logger.Println("Exporting traces via OTLP")
+	return tracerProvider.Shutdown, nil
+}
+
+// injectTraceContext adds the trace context headers to outgoing requests, so that the traces continue in the JuiceShop (if it's instrumented)
+func injectTraceContext(ctx context.Context, req *http.Request) {
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+}
+
+// RecordSpanError marks the span as failed
+func RecordSpanError(span trace.Span, err error) {
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
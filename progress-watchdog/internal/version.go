@@ -0,0 +1,40 @@
+package internal
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// Build information of the progress-watchdog. Injected at build time, e.g. via:
+//
+//	go build -ldflags "-X github.com/juice-shop/multi-juicer/progress-watchdog/internal.Version=v8.0.0"
+var (
+	Version   = "dev"
+	Commit    = "unknown"
+	BuildDate = "unknown"
+)
+
+// challengesHash is the sha256 hash of the loaded challenges.json, set once the challenges got loaded
+var challengesHash = ""
+
+type VersionInfo struct {
+	Version        string `json:"version"`
+	Commit         string `json:"commit"`
+	BuildDate      string `json:"buildDate"`
+	ChallengesHash string `json:"challengesHash"`
+}
+
+// GetVersionInfo returns the build information and the hash of the loaded challenges.json
+func GetVersionInfo() VersionInfo {
+	return VersionInfo{
+		Version:        Version,
+		Commit:         Commit,
+		BuildDate:      BuildDate,
+		ChallengesHash: challengesHash,
+	}
+}
+
+func hashChallenges(challengesBytes []byte) string {
+	hash := sha256.Sum256(challengesBytes)
+	return hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,19 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetVersionInfo(t *testing.T) {
+	t.Cleanup(func() { challengesHash = "" })
+	challengesHash = hashChallenges([]byte("[]"))
+
+	assert.Equal(t, VersionInfo{
+		Version:        "dev",
+		Commit:         "unknown",
+		BuildDate:      "unknown",
+		ChallengesHash: "4f53cda18c2baa0c0354bb5f9a3ecbe5ed12ab4d8e11ba873c2f11161202b945",
+	}, GetVersionInfo())
+}
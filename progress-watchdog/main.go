@@ -1,16 +1,33 @@
 package main
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
+	"maps"
+	"net"
 	"net/http"
 	"os"
+	"os/signal"
 	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
 
 	"github.com/juice-shop/multi-juicer/progress-watchdog/internal"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
@@ -31,6 +48,8 @@ type JuiceShopWebhookSolution struct {
 	Challenge string  `json:"challenge"`
 	Evidence  *string `json:"evidence"`
 	IssuedOn  string  `json:"issuedOn"`
+	// CtfFlag is only set if the JuiceShop runs in CTF mode. Single webhook payloads carry it next to the solution
+	CtfFlag string `json:"ctfFlag,omitempty"`
 }
 
 type JuiceShopWebhookIssuer struct {
@@ -44,6 +63,229 @@ type JuiceShopWebhook struct {
 	Solution JuiceShopWebhookSolution `json:"solution"`
 	CtfFlag  string                   `json:"ctfFlag"`
 	Issuer   JuiceShopWebhookIssuer   `json:"issuer"`
+	// ContinueCodeFindIt and ContinueCodeFixIt optionally carry the updated coding challenge progress, so that it doesn't have to be fetched from the instance
+	ContinueCodeFindIt string `json:"continueCodeFindIt,omitempty"`
+	ContinueCodeFixIt  string `json:"continueCodeFixIt,omitempty"`
+}
+
+// decodeWebhookSolutions decodes either a single webhook payload or a json array of solutions, for juice shops sending multiple solutions at once.
+// The issuer and coding challenge continue codes are only known for single webhook payloads and empty otherwise
+func decodeWebhookSolutions(body []byte) ([]JuiceShopWebhookSolution, JuiceShopWebhook, error) {
+	trimmed := bytes.TrimLeft(body, " \t\r\n")
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		var solutions []JuiceShopWebhookSolution
+		if err := json.Unmarshal(trimmed, &solutions); err != nil {
+			return nil, JuiceShopWebhook{}, err
+		}
+		return solutions, JuiceShopWebhook{}, nil
+	}
+
+	var webhook JuiceShopWebhook
+	if err := json.Unmarshal(trimmed, &webhook); err != nil {
+		return nil, JuiceShopWebhook{}, err
+	}
+	if webhook.Solution.CtfFlag == "" {
+		webhook.Solution.CtfFlag = webhook.CtfFlag
+	}
+	return []JuiceShopWebhookSolution{webhook.Solution}, webhook, nil
+}
+
+// codingChallengeContinueCodes returns the coding challenge continue codes sent with the webhook, by coding challenge kind. Always empty if coding challenges are disabled
+func codingChallengeContinueCodes(webhook JuiceShopWebhook) map[internal.CodingChallengeKind]string {
+	continueCodes := map[internal.CodingChallengeKind]string{}
+	if !internal.CodingChallengesEnabled() {
+		return continueCodes
+	}
+	if webhook.ContinueCodeFindIt != "" {
+		continueCodes[internal.FindIt] = webhook.ContinueCodeFindIt
+	}
+	if webhook.ContinueCodeFixIt != "" {
+		continueCodes[internal.FixIt] = webhook.ContinueCodeFixIt
+	}
+	return continueCodes
+}
+
+// webhookKind labels the webhook metrics. Webhooks carrying coding challenge continue codes are labeled by the furthest coding challenge stage
+func webhookKind(webhook JuiceShopWebhook) string {
+	if webhook.ContinueCodeFixIt != "" {
+		return string(internal.FixIt)
+	}
+	if webhook.ContinueCodeFindIt != "" {
+		return string(internal.FindIt)
+	}
+	return "regular"
+}
+
+// statusRecordingResponseWriter remembers the status code of the response for the webhook metrics
+type statusRecordingResponseWriter struct {
+	http.ResponseWriter
+	statusCode int
+}
+
+func (w *statusRecordingResponseWriter) WriteHeader(statusCode int) {
+	w.statusCode = statusCode
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+// webhookSource describes where a webhook came from for the webhook log. Prefers the hostname reported by the JuiceShop over the remote address
+func webhookSource(req *http.Request, issuer JuiceShopWebhookIssuer) string {
+	if issuer.HostName != "" {
+		return fmt.Sprintf("%s (%s)", issuer.HostName, req.RemoteAddr)
+	}
+	return req.RemoteAddr
+}
+
+// WebhookResponse is returned to clients requesting json, so that they can show the solved challenges without fetching the scoreboard.
+// It deliberately doesn't contain the score: the balancer owns the scoring rules (e.g. point overrides), the admin score adjustments and the score cap,
+// so a score calculated by the watchdog would disagree with the scoreboard as soon as any of them is used
+type WebhookResponse struct {
+	Team                 string   `json:"team"`
+	SolvedChallenges     []string `json:"solvedChallenges"`
+	SolvedChallengeCount int      `json:"solvedChallengeCount"`
+}
+
+// writeWebhookResponse responds with the solved challenge keys if the client accepts json. Everyone else gets a plain "ok" as before
+func writeWebhookResponse(responseWriter http.ResponseWriter, req *http.Request, team string, challengeStatus []internal.ChallengeStatus) {
+	if !strings.Contains(req.Header.Get("Accept"), "application/json") {
+		responseWriter.WriteHeader(http.StatusOK)
+		responseWriter.Write([]byte("ok"))
+		return
+	}
+
+	solvedChallenges := make([]string, 0, len(challengeStatus))
+	for _, challenge := range challengeStatus {
+		solvedChallenges = append(solvedChallenges, challenge.Key)
+	}
+	responseBytes, err := json.Marshal(WebhookResponse{
+		Team:                 team,
+		SolvedChallenges:     solvedChallenges,
+		SolvedChallengeCount: len(solvedChallenges),
+	})
+	if err != nil {
+		logger.Print(fmt.Errorf("failed to encode webhook response: %w", err))
+		writeJSONError(responseWriter, http.StatusInternalServerError, "internal_error", "failed to encode webhook response")
+		return
+	}
+	responseWriter.Header().Set("Content-Type", "application/json")
+	responseWriter.WriteHeader(http.StatusOK)
+	responseWriter.Write(responseBytes)
+}
+
+// kubernetesApiCheckInterval limits how often the readiness probe actually queries the kubernetes api, probes in between reuse the last result
+const kubernetesApiCheckInterval = 10 * time.Second
+
+// newReadinessHandler reports the watchdog as ready once checkKubernetesApi succeeds, so that it doesn't take webhooks before connectivity and RBAC permissions are confirmed
+func newReadinessHandler(checkKubernetesApi func(ctx context.Context) error) http.HandlerFunc {
+	var mutex sync.Mutex
+	var checkedAt time.Time
+	var lastErr error
+	return func(responseWriter http.ResponseWriter, req *http.Request) {
+		mutex.Lock()
+		if checkedAt.IsZero() || time.Since(checkedAt) >= kubernetesApiCheckInterval {
+			lastErr = checkKubernetesApi(req.Context())
+			checkedAt = time.Now()
+		}
+		err := lastErr
+		mutex.Unlock()
+
+		if err != nil {
+			logger.Print(fmt.Errorf("readiness check failed to list deployments: %w", err))
+			http.Error(responseWriter, "kubernetes api not usable", http.StatusServiceUnavailable)
+			return
+		}
+		responseWriter.WriteHeader(http.StatusOK)
+		responseWriter.Write([]byte("ok"))
+	}
+}
+
+const defaultSyncWorkerCount = 10
+
+// parseSyncWorkerCount parses the SYNC_WORKER_COUNT env var, falling back to the default if it isn't set
+func parseSyncWorkerCount(value string) (int, error) {
+	if value == "" {
+		return defaultSyncWorkerCount, nil
+	}
+	workerCount, err := strconv.Atoi(value)
+	if err != nil || workerCount <= 0 {
+		return 0, fmt.Errorf("SYNC_WORKER_COUNT must be a positive integer, got '%s'", value)
+	}
+	return workerCount, nil
+}
+
+const defaultWebhookMaxBodySize = 1024 * 1024
+
+// parseWebhookMaxBodySize parses the WEBHOOK_MAX_BODY_SIZE env var (in bytes), falling back to 1MB if it isn't set
+func parseWebhookMaxBodySize(value string) (int64, error) {
+	if value == "" {
+		return defaultWebhookMaxBodySize, nil
+	}
+	maxBodySize, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || maxBodySize <= 0 {
+		return 0, fmt.Errorf("WEBHOOK_MAX_BODY_SIZE must be a positive number of bytes, got '%s'", value)
+	}
+	return maxBodySize, nil
+}
+
+const defaultListenAddr = ":8080"
+
+// parseListenAddr parses the LISTEN_ADDR env var, falling back to listening on port 8080 on all interfaces if it isn't set
+func parseListenAddr(value string) (string, error) {
+	if value == "" {
+		return defaultListenAddr, nil
+	}
+	if _, _, err := net.SplitHostPort(value); err != nil {
+		return "", fmt.Errorf("LISTEN_ADDR must be a host:port address like ':8080' or '127.0.0.1:8080', got '%s'", value)
+	}
+	return value, nil
+}
+
+// ErrorResponse is the body of failed webhook requests, matching the error bodies of the balancer admin api
+type ErrorResponse struct {
+	Error string `json:"error"`
+	Code  string `json:"code"`
+}
+
+// writeJSONError is the json counterpart of http.Error, used by the webhook handler
+func writeJSONError(responseWriter http.ResponseWriter, status int, code string, message string) {
+	responseBody, _ := json.Marshal(ErrorResponse{Error: message, Code: code})
+	responseWriter.Header().Set("Content-Type", "application/json")
+	responseWriter.Header().Set("X-Content-Type-Options", "nosniff")
+	responseWriter.WriteHeader(status)
+	responseWriter.Write(responseBody)
+}
+
+// readWebhookBody reads the request body up to maxBodySize bytes. Writes the error response and returns false if the body couldn't be read
+func readWebhookBody(responseWriter http.ResponseWriter, req *http.Request, maxBodySize int64) ([]byte, bool) {
+	body, err := io.ReadAll(http.MaxBytesReader(responseWriter, req.Body, maxBodySize))
+	if err != nil {
+		var maxBytesError *http.MaxBytesError
+		if errors.As(err, &maxBytesError) {
+			writeJSONError(responseWriter, http.StatusRequestEntityTooLarge, "body_too_large", "body too large")
+			return nil, false
+		}
+		writeJSONError(responseWriter, http.StatusBadRequest, "invalid_body", "failed to read body")
+		return nil, false
+	}
+	return body, true
+}
+
+// handleWebhookMethodNotAllowed answers requests to the webhook path which don't use POST, so that misconfigured webhook clients get a clear error instead of a 404
+func handleWebhookMethodNotAllowed(responseWriter http.ResponseWriter, req *http.Request) {
+	responseWriter.Header().Set("Allow", http.MethodPost)
+	writeJSONError(responseWriter, http.StatusMethodNotAllowed, "method_not_allowed", "method not allowed, webhooks must be sent via POST")
+}
+
+// handleVersion returns the build information and the hash of the loaded challenges.json, to correlate the behavior of a running watchdog with a deploy
+func handleVersion(responseWriter http.ResponseWriter, req *http.Request) {
+	responseBytes, err := json.Marshal(internal.GetVersionInfo())
+	if err != nil {
+		logger.Print(fmt.Errorf("failed to encode version response: %w", err))
+		http.Error(responseWriter, "", http.StatusInternalServerError)
+		return
+	}
+	responseWriter.Header().Set("Content-Type", "application/json")
+	responseWriter.WriteHeader(http.StatusOK)
+	responseWriter.Write(responseBytes)
 }
 
 var logger = log.New(os.Stdout, "", log.LstdFlags)
@@ -52,6 +294,10 @@ var namespace = os.Getenv("NAMESPACE")
 func main() {
 	logger.Println("Starting ProgressWatchdog")
 
+	if strings.TrimSpace(namespace) == "" {
+		logger.Fatal("Environment variable 'NAMESPACE' must be set. Without it the ProgressWatchdog can't find the JuiceShop instances it should watch.")
+	}
+
 	config, err := rest.InClusterConfig()
 	if err != nil {
 		panic(err.Error())
@@ -63,23 +309,112 @@ func main() {
 		panic(err.Error())
 	}
 
-	const numberWorkers = 10
-	internal.StartBackgroundSync(clientset, numberWorkers)
+	shutdownTracing, err := internal.InitTracing(context.Background())
+	if err != nil {
+		logger.Fatal(fmt.Errorf("failed to set up tracing: %w", err))
+	}
+	defer shutdownTracing(context.Background())
+
+	shutdownMetrics, err := internal.InitMetrics(context.Background())
+	if err != nil {
+		logger.Fatal(fmt.Errorf("failed to set up metrics: %w", err))
+	}
+	defer shutdownMetrics(context.Background())
+	go startMetricsServer()
+
+	if err := internal.ConfigureContinueCodes(os.Getenv("CONTINUE_CODE_MIN_LENGTH"), os.Getenv("CONTINUE_CODE_ALPHABET")); err != nil {
+		logger.Fatal(err)
+	}
+
+	juiceShopClientConfig, err := internal.JuiceShopClientConfigFromEnv()
+	if err != nil {
+		logger.Fatal(err)
+	}
+	if err := internal.InitJuiceShopClient(juiceShopClientConfig); err != nil {
+		logger.Fatal(fmt.Errorf("failed to set up the JuiceShop client: %w", err))
+	}
+	if err := internal.ConfigureJuiceShopRequestAttempts(os.Getenv("JUICE_SHOP_REQUEST_ATTEMPTS")); err != nil {
+		logger.Fatal(err)
+	}
+	if err := internal.ConfigureJuiceShopMaxConcurrentRequests(os.Getenv("JUICE_SHOP_MAX_CONCURRENT_REQUESTS")); err != nil {
+		logger.Fatal(err)
+	}
+
+	numberWorkers, err := parseSyncWorkerCount(os.Getenv("SYNC_WORKER_COUNT"))
+	if err != nil {
+		logger.Fatal(err)
+	}
+	// canceled on SIGTERM / SIGINT, stopping the background sync and the web server
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
+	defer stop()
+
+	if err := internal.StartBackgroundSync(ctx, clientset, numberWorkers); err != nil {
+		logger.Fatal(fmt.Errorf("failed to start the background sync: %w", err))
+	}
+
+	webhookMaxBodySize, err := parseWebhookMaxBodySize(os.Getenv("WEBHOOK_MAX_BODY_SIZE"))
+	if err != nil {
+		logger.Fatal(err)
+	}
+	listenAddr, err := parseListenAddr(os.Getenv("LISTEN_ADDR"))
+	if err != nil {
+		logger.Fatal(err)
+	}
+	if err := internal.ConfigureCtfFlagForwarding(os.Getenv("CTF_FLAG_FORWARD_URL")); err != nil {
+		logger.Fatal(err)
+	}
+	if err := internal.ConfigureCodingChallenges(os.Getenv("ENABLE_CODING_CHALLENGES")); err != nil {
+		logger.Fatal(err)
+	}
 
 	router := http.NewServeMux()
 	router.HandleFunc("POST /team/{team}/webhook", func(responseWriter http.ResponseWriter, req *http.Request) {
+		start := time.Now()
+		statusRecorder := &statusRecordingResponseWriter{ResponseWriter: responseWriter, statusCode: http.StatusOK}
+		responseWriter = statusRecorder
+		// unknown until the body got decoded
+		kind := "unknown"
+		defer func() {
+			internal.RecordWebhookProcessed(kind, statusRecorder.statusCode, time.Since(start))
+		}()
+
 		team := req.PathValue("team")
-		var webhook JuiceShopWebhook
+		if err := internal.ValidateTeamName(team); err != nil {
+			writeJSONError(responseWriter, http.StatusBadRequest, "invalid_team_name", fmt.Sprintf("invalid team name: %s", err))
+			return
+		}
+
+		// continues traces started by the JuiceShop, if it sends trace context headers
+		ctx := otel.GetTextMapPropagator().Extract(req.Context(), propagation.HeaderCarrier(req.Header))
+		ctx, span := internal.Tracer.Start(ctx, "webhook.handle", trace.WithAttributes(attribute.String("team", team)))
+		defer span.End()
 
-		err := json.NewDecoder(req.Body).Decode(&webhook)
+		body, ok := readWebhookBody(responseWriter, req, webhookMaxBodySize)
+		if !ok {
+			return
+		}
+		solutions, webhook, err := decodeWebhookSolutions(body)
 		if err != nil {
-			http.Error(responseWriter, "invalid json", http.StatusBadRequest)
+			writeJSONError(responseWriter, http.StatusBadRequest, "invalid_json", "invalid json")
 			return
 		}
+		kind = webhookKind(webhook)
 
-		deployment, err := clientset.AppsV1().Deployments(namespace).Get(context.Background(), fmt.Sprintf("juiceshop-%s", team), metav1.GetOptions{})
+		getDeploymentCtx, getDeploymentSpan := internal.Tracer.Start(ctx, "kubernetes.getDeployment")
+		deployment, err := clientset.AppsV1().Deployments(namespace).Get(getDeploymentCtx, fmt.Sprintf("juiceshop-%s", team), metav1.GetOptions{})
 		if err != nil {
+			internal.RecordSpanError(getDeploymentSpan, err)
+		}
+		getDeploymentSpan.End()
+		if apierrors.IsNotFound(err) {
+			logger.Printf("Received webhook for team '%s' which doesn't have a deployment (anymore), ignoring webhook", team)
+			writeJSONError(responseWriter, http.StatusNotFound, "team_not_found", "team not found")
+			return
+		} else if err != nil {
+			internal.RecordSpanError(span, err)
 			logger.Print(fmt.Errorf("failed to get deployment for team: '%s' received via in webhook: %w", team, err))
+			writeJSONError(responseWriter, http.StatusInternalServerError, "kubernetes_error", "failed to get deployment for team")
+			return
 		}
 
 		challengeStatusJson := "[]"
@@ -93,36 +428,107 @@ func main() {
 			logger.Print(fmt.Errorf("failed to decode json from juice shop deployment annotation: %w", err))
 		}
 
-		// check if the challenge is already solved
+		solvedChallenges := map[string]bool{}
 		for _, status := range challengeStatus {
-			if status.Key == webhook.Solution.Challenge {
-				logger.Printf("Challenge '%s' already solved by team '%s', ignoring webhook", webhook.Solution.Challenge, team)
-				responseWriter.WriteHeader(http.StatusOK)
-				responseWriter.Write([]byte("ok"))
-				return
-			}
+			solvedChallenges[status.Key] = true
 		}
 
-		challengeStatus = append(challengeStatus, internal.ChallengeStatus{Key: webhook.Solution.Challenge, SolvedAt: webhook.Solution.IssuedOn})
-		sort.Stable(challengeStatus)
+		now := time.Now()
+		receivedAt := now.UTC().Format(time.RFC3339)
+		webhookLogEntries := make([]internal.WebhookLogEntry, 0, len(solutions))
+		newlySolved := 0
+		for _, solution := range solutions {
+			// all webhooks are logged, including ones for already solved challenges
+			webhookLogEntries = append(webhookLogEntries, internal.WebhookLogEntry{
+				Challenge:  solution.Challenge,
+				IssuedOn:   solution.IssuedOn,
+				ReceivedAt: receivedAt,
+				Source:     webhookSource(req, webhook.Issuer),
+			})
 
-		internal.PersistProgress(clientset, team, challengeStatus)
+			// check if the challenge is already solved
+			if solvedChallenges[solution.Challenge] {
+				logger.Printf("Challenge '%s' already solved by team '%s', ignoring solution", solution.Challenge, team)
+				continue
+			}
+			solvedChallenges[solution.Challenge] = true
+			challengeStatus = append(challengeStatus, internal.ChallengeStatus{Key: solution.Challenge, SolvedAt: internal.NormalizeSolveTime(solution.IssuedOn, now)})
+			newlySolved++
+			logger.Printf("Received webhook for team '%s' for challenge '%s'", team, solution.Challenge)
+			internal.ForwardCtfFlag(ctx, team, solution.Challenge, solution.CtfFlag)
+		}
 
-		logger.Printf("Received webhook for team '%s' for challenge '%s'", team, webhook.Solution.Challenge)
+		// all changes of the webhook are collected and written with a single patch
+		annotations := map[string]string{}
+		if newlySolved > 0 {
+			sort.Stable(challengeStatus)
+			maps.Copy(annotations, internal.ProgressAnnotations(challengeStatus))
+		}
+		if webhookContinueCodes := codingChallengeContinueCodes(webhook); len(webhookContinueCodes) > 0 {
+			for kind, webhookCode := range webhookContinueCodes {
+				continueCode, err := internal.ResolveCodingChallengeContinueCode(ctx, team, kind, webhookCode)
+				if err != nil {
+					logger.Print(fmt.Errorf("failed to get a valid %s continue code for team '%s': %w", kind, team, err))
+					continue
+				}
+				if deployment.Annotations[internal.CodingChallengeAnnotation(kind)] != continueCode {
+					annotations[internal.CodingChallengeAnnotation(kind)] = continueCode
+				}
+			}
+		}
+		if len(webhookLogEntries) > 0 {
+			webhookLog := internal.AppendWebhookLogEntries(deployment.Annotations["multi-juicer.owasp-juice.shop/webhookLog"], webhookLogEntries)
+			if webhookLogJson, err := internal.EncodeWebhookLog(webhookLog); err != nil {
+				logger.Print(fmt.Errorf("failed to persist webhook log of team '%s': %w", team, err))
+			} else {
+				annotations["multi-juicer.owasp-juice.shop/webhookLog"] = webhookLogJson
+			}
+		}
+		internal.PersistAnnotations(ctx, clientset, team, annotations)
 
-		responseWriter.WriteHeader(http.StatusOK)
-		responseWriter.Write([]byte("ok"))
+		writeWebhookResponse(responseWriter, req, team, challengeStatus)
 	})
 
-	router.HandleFunc("GET /ready", func(responseWriter http.ResponseWriter, req *http.Request) {
-		responseWriter.WriteHeader(http.StatusOK)
-		responseWriter.Write([]byte("ok"))
-	})
+	router.HandleFunc("/team/{team}/webhook", handleWebhookMethodNotAllowed)
+
+	router.HandleFunc("GET /ready", newReadinessHandler(func(ctx context.Context) error {
+		return internal.CheckKubernetesApi(ctx, clientset)
+	}))
+
+	router.HandleFunc("GET /version", handleVersion)
 
 	server := &http.Server{
-		Addr:    ":8080",
+		Addr:    listenAddr,
 		Handler: router,
 	}
-	logger.Println("Starting web server listening for Solution Webhooks on :8080")
-	server.ListenAndServe()
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+
+	listener, err := net.Listen("tcp", listenAddr)
+	if err != nil {
+		logger.Fatal(fmt.Errorf("failed to listen on '%s': %w", listenAddr, err))
+	}
+	logger.Printf("Starting web server listening for Solution Webhooks on %s", listener.Addr())
+	// Serve returns ErrServerClosed once the server got shut down gracefully, every other error means the server failed
+	if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		logger.Fatal(fmt.Errorf("web server failed: %w", err))
+	}
+}
+
+// startMetricsServer serves the Prometheus metrics on a separate port, like the balancer does
+func startMetricsServer() {
+	metricsRouter := http.NewServeMux()
+	metricsRouter.Handle("GET /metrics", promhttp.Handler())
+	metricsServer := &http.Server{
+		Addr:    ":8081",
+		Handler: metricsRouter,
+	}
+
+	if err := metricsServer.ListenAndServe(); err != nil {
+		logger.Fatal(fmt.Errorf("failed to start metrics server: %w", err))
+	}
 }
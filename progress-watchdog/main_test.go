@@ -0,0 +1,200 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/juice-shop/multi-juicer/progress-watchdog/internal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeWebhookSolutions(t *testing.T) {
+	solutions, webhook, err := decodeWebhookSolutions([]byte(`{"solution":{"challenge":"scoreBoardChallenge","evidence":null,"issuedOn":"2024-11-01T19:55:48.211Z"},"ctfFlag":"foobar","issuer":{"hostName":"juiceshop-foobar-7d9f8b"}}`))
+	assert.Nil(t, err)
+	assert.Equal(t, "juiceshop-foobar-7d9f8b", webhook.Issuer.HostName)
+	assert.Equal(t, []JuiceShopWebhookSolution{
+		{Challenge: "scoreBoardChallenge", IssuedOn: "2024-11-01T19:55:48.211Z", CtfFlag: "foobar"},
+	}, solutions, "Should decode a single webhook payload including its ctf flag")
+
+	solutions, _, err = decodeWebhookSolutions([]byte(` 
+	[{"challenge":"scoreBoardChallenge","issuedOn":"2024-11-01T19:55:48.211Z"},{"challenge":"nullByteChallenge","issuedOn":"2024-11-01T20:01:12.000Z"}]`))
+	assert.Nil(t, err)
+	assert.Equal(t, []JuiceShopWebhookSolution{
+		{Challenge: "scoreBoardChallenge", IssuedOn: "2024-11-01T19:55:48.211Z"},
+		{Challenge: "nullByteChallenge", IssuedOn: "2024-11-01T20:01:12.000Z"},
+	}, solutions, "Should decode an array of solutions with leading whitespace")
+
+	_, _, err = decodeWebhookSolutions([]byte(`[{"challenge":`))
+	assert.NotNil(t, err, "Should fail on invalid json")
+}
+
+func TestCodingChallengeContinueCodes(t *testing.T) {
+	_, webhook, err := decodeWebhookSolutions([]byte(`{"solution":{"challenge":"scoreBoardChallenge","issuedOn":"2024-11-01T19:55:48.211Z"},"continueCodeFindIt":"findItCode"}`))
+	assert.Nil(t, err)
+	assert.Equal(t, map[internal.CodingChallengeKind]string{internal.FindIt: "findItCode"}, codingChallengeContinueCodes(webhook))
+
+	_, webhook, err = decodeWebhookSolutions([]byte(`[{"challenge":"scoreBoardChallenge","issuedOn":"2024-11-01T19:55:48.211Z"}]`))
+	assert.Nil(t, err)
+	assert.Empty(t, codingChallengeContinueCodes(webhook), "Should be empty for webhooks without continue codes")
+
+	t.Cleanup(func() { internal.ConfigureCodingChallenges("true") })
+	assert.Nil(t, internal.ConfigureCodingChallenges("false"))
+	_, webhook, err = decodeWebhookSolutions([]byte(`{"solution":{"challenge":"scoreBoardChallenge","issuedOn":"2024-11-01T19:55:48.211Z"},"continueCodeFindIt":"findItCode"}`))
+	assert.Nil(t, err)
+	assert.Empty(t, codingChallengeContinueCodes(webhook), "Should ignore continue codes if coding challenges are disabled")
+}
+
+func TestWebhookKind(t *testing.T) {
+	assert.Equal(t, "regular", webhookKind(JuiceShopWebhook{}))
+	assert.Equal(t, "findIt", webhookKind(JuiceShopWebhook{ContinueCodeFindIt: "findItCode"}))
+	assert.Equal(t, "fixIt", webhookKind(JuiceShopWebhook{ContinueCodeFindIt: "findItCode", ContinueCodeFixIt: "fixItCode"}), "Should use the furthest coding challenge stage")
+}
+
+func TestStatusRecordingResponseWriter(t *testing.T) {
+	rr := httptest.NewRecorder()
+	recorder := &statusRecordingResponseWriter{ResponseWriter: rr, statusCode: http.StatusOK}
+	recorder.Write([]byte("ok"))
+	assert.Equal(t, http.StatusOK, recorder.statusCode, "Should default to 200 if the status isn't set explicitly")
+
+	rr = httptest.NewRecorder()
+	recorder = &statusRecordingResponseWriter{ResponseWriter: rr, statusCode: http.StatusOK}
+	http.Error(recorder, "team not found", http.StatusNotFound)
+	assert.Equal(t, http.StatusNotFound, recorder.statusCode)
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
+
+func TestParseSyncWorkerCount(t *testing.T) {
+	workerCount, err := parseSyncWorkerCount("")
+	assert.Nil(t, err)
+	assert.Equal(t, 10, workerCount, "Should default to 10 workers")
+
+	workerCount, err = parseSyncWorkerCount("25")
+	assert.Nil(t, err)
+	assert.Equal(t, 25, workerCount)
+
+	_, err = parseSyncWorkerCount("0")
+	assert.NotNil(t, err, "Should reject zero workers")
+	_, err = parseSyncWorkerCount("-3")
+	assert.NotNil(t, err, "Should reject negative worker counts")
+	_, err = parseSyncWorkerCount("ten")
+	assert.NotNil(t, err, "Should reject non numeric values")
+}
+
+func TestParseWebhookMaxBodySize(t *testing.T) {
+	maxBodySize, err := parseWebhookMaxBodySize("")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1024*1024), maxBodySize, "Should default to 1MB")
+
+	maxBodySize, err = parseWebhookMaxBodySize("2048")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2048), maxBodySize)
+
+	_, err = parseWebhookMaxBodySize("0")
+	assert.NotNil(t, err, "Should reject zero")
+	_, err = parseWebhookMaxBodySize("1MB")
+	assert.NotNil(t, err, "Should reject non numeric values")
+}
+
+func TestParseListenAddr(t *testing.T) {
+	listenAddr, err := parseListenAddr("")
+	assert.Nil(t, err)
+	assert.Equal(t, ":8080", listenAddr, "Should default to port 8080 on all interfaces")
+
+	listenAddr, err = parseListenAddr("127.0.0.1:9090")
+	assert.Nil(t, err)
+	assert.Equal(t, "127.0.0.1:9090", listenAddr)
+
+	_, err = parseListenAddr("8080")
+	assert.NotNil(t, err, "Should reject addresses without a port separator")
+}
+
+func TestReadWebhookBody(t *testing.T) {
+	req := httptest.NewRequest("POST", "/team/foobar/webhook", strings.NewReader(`{"solution":{}}`))
+	rr := httptest.NewRecorder()
+	body, ok := readWebhookBody(rr, req, 1024)
+	assert.True(t, ok)
+	assert.Equal(t, `{"solution":{}}`, string(body))
+
+	req = httptest.NewRequest("POST", "/team/foobar/webhook", strings.NewReader(strings.Repeat("a", 1025)))
+	rr = httptest.NewRecorder()
+	_, ok = readWebhookBody(rr, req, 1024)
+	assert.False(t, ok)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rr.Code)
+	assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{"error":"body too large","code":"body_too_large"}`, rr.Body.String())
+}
+
+func TestWriteWebhookResponse(t *testing.T) {
+	challengeStatus := []internal.ChallengeStatus{{Key: "scoreBoardChallenge", SolvedAt: "2024-11-01T19:55:48.211Z"}}
+
+	req := httptest.NewRequest("POST", "/team/foobar/webhook", nil)
+	rr := httptest.NewRecorder()
+	writeWebhookResponse(rr, req, "foobar", challengeStatus)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "ok", rr.Body.String(), "Should respond with plain text by default")
+
+	req = httptest.NewRequest("POST", "/team/foobar/webhook", nil)
+	req.Header.Set("Accept", "application/json")
+	rr = httptest.NewRecorder()
+	writeWebhookResponse(rr, req, "foobar", challengeStatus)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{"team":"foobar","solvedChallenges":["scoreBoardChallenge"],"solvedChallengeCount":1}`, rr.Body.String())
+}
+
+func TestWebhookMethodNotAllowed(t *testing.T) {
+	router := http.NewServeMux()
+	router.HandleFunc("POST /team/{team}/webhook", func(responseWriter http.ResponseWriter, req *http.Request) {
+		responseWriter.WriteHeader(http.StatusOK)
+	})
+	router.HandleFunc("/team/{team}/webhook", handleWebhookMethodNotAllowed)
+
+	for _, method := range []string{"GET", "PUT", "DELETE"} {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(method, "/team/foobar/webhook", nil))
+		assert.Equal(t, http.StatusMethodNotAllowed, rr.Code, method)
+		assert.Equal(t, "POST", rr.Header().Get("Allow"), method)
+		assert.JSONEq(t, `{"error":"method not allowed, webhooks must be sent via POST","code":"method_not_allowed"}`, rr.Body.String(), method)
+	}
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest("POST", "/team/foobar/webhook", nil))
+	assert.Equal(t, http.StatusOK, rr.Code, "Should still route POST requests to the webhook handler")
+}
+
+func TestHandleVersion(t *testing.T) {
+	rr := httptest.NewRecorder()
+	handleVersion(rr, httptest.NewRequest("GET", "/version", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{"version":"dev","commit":"unknown","buildDate":"unknown","challengesHash":""}`, rr.Body.String())
+}
+
+func TestReadinessHandler(t *testing.T) {
+	checks := 0
+	checkErr := errors.New("forbidden")
+	handler := newReadinessHandler(func(ctx context.Context) error {
+		checks++
+		return checkErr
+	})
+
+	for i := 0; i < 3; i++ {
+		rr := httptest.NewRecorder()
+		handler(rr, httptest.NewRequest("GET", "/ready", nil))
+		assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+	}
+	assert.Equal(t, 1, checks, "Should reuse the result of the last check instead of querying the api on every probe")
+
+	checkErr = nil
+	handler = newReadinessHandler(func(ctx context.Context) error {
+		return nil
+	})
+	rr := httptest.NewRecorder()
+	handler(rr, httptest.NewRequest("GET", "/ready", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "ok", rr.Body.String())
+}